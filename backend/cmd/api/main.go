@@ -11,6 +11,7 @@ import (
 	"chalkstone.council/internal/config"
 	"chalkstone.council/internal/database"
 	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -43,6 +44,13 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Refuse to start without keys that tokens can safely be signed with
+	keys, err := middleware.LoadKeySet(cfg.JWTKeysFile, cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	middleware.SetKeySet(keys)
+
 	db, err := database.InitDB()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -85,11 +93,18 @@ func main() {
 	docs.SwaggerInfo.BasePath = "/api"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Create an instance of RealAuth
+	// Create an instance of RealAuth, rejecting revoked access tokens
+	middleware.SetRevocationList(db)
 	auth := &middleware.RealAuth{}
 
-	// Setup routes with injected authentication middleware
-	api.SetupRoutes(r, db, auth)
+	// Object store for uploaded images, chosen by STORAGE_BACKEND
+	images, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up image storage: %v", err)
+	}
+
+	// Setup routes with injected storage and authentication middleware
+	api.SetupRoutes(r, db, images, auth)
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"chalkstone.council/internal/oidc/oidctest"
+)
+
+func main() {
+	// Parse command line arguments
+	addr := flag.String("addr", "localhost:9400", "Address to listen on")
+	clientID := flag.String("client-id", "council-api", "Client ID the API is registered with")
+	subject := flag.String("sub", "mock-user-1", "Subject of the logged in user")
+	username := flag.String("username", "mock.dispatcher", "Preferred username of the logged in user")
+	email := flag.String("email", "mock.dispatcher@chalkstone.gov.uk", "Email of the logged in user")
+	groups := flag.String("groups", "highways-dispatch", "Comma separated groups of the logged in user")
+	flag.Parse()
+
+	issuer := "http://" + *addr
+	provider, err := oidctest.New(issuer, *clientID)
+	if err != nil {
+		log.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.SetUser(oidctest.User{
+		Subject:           *subject,
+		Email:             *email,
+		PreferredUsername: *username,
+		Groups:            strings.Split(*groups, ","),
+	})
+
+	// Every login is approved straight away as the user above
+	fmt.Printf("Mock OIDC provider running, set OIDC_ISSUER=%s and OIDC_CLIENT_ID=%s\n", issuer, *clientID)
+	log.Fatal(http.ListenAndServe(*addr, provider.Handler()))
+}
@@ -5,24 +5,35 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"chalkstone.council/internal/config"
 	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
 )
 
 func main() {
 	// Parse command line arguments
-	userType := flag.String("type", "staff", "User type (public or staff)")
+	userType := flag.String("type", models.RoleAdmin, "Role ("+strings.Join(models.Roles, ", ")+")")
 	userID := flag.String("id", "test-user", "User ID")
 	flag.Parse()
 
-	// Set the exact same JWT_SECRET as used by the server
+	if !models.ValidateRole(*userType) {
+		fmt.Printf("Invalid role %q, must be one of: %s\n", *userType, strings.Join(models.Roles, ", "))
+		os.Exit(2)
+	}
+
+	// Sign with the same keys as the server
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	os.Setenv("JWT_SECRET", cfg.JWTSecret)
+	keys, err := middleware.LoadKeySet(cfg.JWTKeysFile, cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	middleware.SetKeySet(keys)
 
 	// Generate the token
 	token, err := middleware.GenerateToken(*userID, *userType)
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/notify"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const passwordResetTTL = time.Hour
+
+// checkCurrentPassword returns the authenticated user after verifying their password
+// before a sensitive account change. It writes the error response and returns nil on
+// mismatch.
+func (h *Handler) checkCurrentPassword(c *gin.Context, password string) *models.User {
+	user, err := h.db.GetUserByUsername(c.GetString("userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return nil
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get user", err)
+		return nil
+	}
+	if !utils.CheckPasswordHash(password, user.PasswordHash) {
+		utils.RespondWithError(c, http.StatusForbidden, "Current password is incorrect", nil)
+		return nil
+	}
+	return user
+}
+
+// checkNewPassword validates a new password against the password policy. It writes
+// the error response, listing every rule the password fails, and returns false if it
+// is rejected.
+func (h *Handler) checkNewPassword(c *gin.Context, password, username string) bool {
+	violations, err := h.passwordPolicy.ValidatePassword(password, username)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process password", err)
+		return false
+	}
+	if len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password does not meet the policy", "violations": violations})
+		return false
+	}
+	return true
+}
+
+// @Summary Change password
+// @Description Change the password of the authenticated user. All sessions are logged out and a new one is started.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body object{current_password=string,new_password=string} true "Current and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401,403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user := h.checkCurrentPassword(c, req.CurrentPassword)
+	if user == nil || !h.checkNewPassword(c, req.NewPassword, user.Username) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process password", err)
+		return
+	}
+
+	if err := h.db.UpdatePassword(user.Username, hashedPassword); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to change password", err)
+		return
+	}
+
+	tokens, err := h.issueTokens(user.Username, user.UserType)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Request password reset
+// @Description Send a single-use password reset token to the account with the given email. The response is the same whether or not such an account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{email=string} true "Email of the account"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password-reset [post]
+func (h *Handler) RequestPasswordReset(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	token, tokenHash, err := newOneTimeCode()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create reset token", err)
+		return
+	}
+
+	expiresAt := time.Now().Add(passwordResetTTL)
+	user, err := h.db.CreatePasswordReset(req.Email, tokenHash, expiresAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create reset token", err)
+		return
+	}
+
+	if user != nil {
+		// Delivery failures are only logged so the response doesn't reveal the account exists
+		err = h.notifier.Send(notify.Message{
+			To:      *user.Email,
+			Subject: "Reset your Chalkstone Council password",
+			Body: fmt.Sprintf("A password reset was requested for %s.\n\nReset token: %s\n\nThe token expires at %s. If you didn't ask to reset your password, you can ignore this message.",
+				user.Username, token, expiresAt.UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			log.Printf("Send password reset error: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account with that email exists, a reset token has been sent"})
+}
+
+// @Summary Reset password
+// @Description Set a new password with a password reset token. All of the user's sessions are logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{token=string,new_password=string} true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /auth/password-reset/confirm [post]
+func (h *Handler) ConfirmPasswordReset(c *gin.Context) {
+	var req struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tokenHash := middleware.HashToken(req.Token)
+	username, err := h.db.GetPasswordResetUsername(tokenHash)
+	if errors.Is(err, database.ErrResetTokenInvalid) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid or expired reset token", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to reset password", err)
+		return
+	}
+	if !h.checkNewPassword(c, req.NewPassword, username) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process password", err)
+		return
+	}
+
+	// The token may have been used in the meantime
+	_, err = h.db.ResetPassword(tokenHash, hashedPassword)
+	if errors.Is(err, database.ErrResetTokenInvalid) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid or expired reset token", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to reset password", err)
+		return
+	}
+
+	log.Printf("Password of %s reset", username)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// @Summary Delete account
+// @Description Delete the authenticated user's account and log out all of its sessions. Issues and comments the user created are kept but no longer attributed to them.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body object{password=string} true "Current password"
+// @Success 200 {object} map[string]string
+// @Failure 400,401,403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me [delete]
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user := h.checkCurrentPassword(c, req.Password)
+	if user == nil {
+		return
+	}
+
+	// Revoke the token of this request too, in case it wasn't issued with a refresh token
+	tokenID := c.GetString("tokenID")
+	expiresAt := c.GetTime("tokenExpiresAt")
+	if tokenID != "" && !expiresAt.IsZero() {
+		if err := h.db.RevokeToken(tokenID, expiresAt); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete account", err)
+			return
+		}
+	}
+
+	err := h.db.DeleteUser(user.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete account", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+}
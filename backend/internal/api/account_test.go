@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/credentials"
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/notify"
+	"chalkstone.council/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// recordingNotifier keeps the messages it was asked to send
+type recordingNotifier struct {
+	messages []notify.Message
+}
+
+func (n *recordingNotifier) Send(msg notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// setupAccountTestRouter registers the account routes for the user "jane"
+func setupAccountTestRouter(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOperations, *recordingNotifier) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	notifier := &recordingNotifier{}
+	handler := &Handler{
+		db:             mockDB,
+		notifier:       notifier,
+		passwordPolicy: credentials.Policy{MinPasswordLength: 10, MinCharClasses: 2},
+	}
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/auth/password-reset", handler.RequestPasswordReset)
+	api.POST("/auth/password-reset/confirm", handler.ConfirmPasswordReset)
+
+	me := api.Group("/me")
+	me.Use(func(c *gin.Context) {
+		c.Set("userID", "jane")
+		c.Set("userType", models.RoleResident)
+		c.Set("tokenID", "current-jti")
+		c.Set("tokenExpiresAt", time.Now().Add(time.Minute))
+		c.Next()
+	})
+	me.PUT("/password", handler.ChangePassword)
+	me.DELETE("", handler.DeleteAccount)
+
+	return router, mockDB, notifier
+}
+
+func sendAccountRequest(router *gin.Engine, method, url, payload string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestChangePassword(t *testing.T) {
+	router, mockDB, _ := setupAccountTestRouter(t)
+	hash, err := utils.HashPassword("old-password")
+	assert.NoError(t, err)
+	user := &models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleResident}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().UpdatePassword("jane", gomock.Any()).DoAndReturn(func(_, newHash string) error {
+			assert.True(t, utils.CheckPasswordHash("new-password", newHash))
+			return nil
+		})
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		w := sendAccountRequest(router, "PUT", "/api/me/password", `{"current_password": "old-password", "new_password": "new-password"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response["token"])
+		assert.NotEmpty(t, response["refresh_token"])
+	})
+
+	t.Run("Wrong current password", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+
+		w := sendAccountRequest(router, "PUT", "/api/me/password", `{"current_password": "guess", "new_password": "new-password"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Weak new password", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+
+		w := sendAccountRequest(router, "PUT", "/api/me/password", `{"current_password": "old-password", "new_password": "jane"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response struct {
+			Violations []credentials.Violation `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Violations, 3)
+	})
+
+	t.Run("Missing fields", func(t *testing.T) {
+		w := sendAccountRequest(router, "PUT", "/api/me/password", `{"new_password": "new-password"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	router, mockDB, notifier := setupAccountTestRouter(t)
+
+	t.Run("Known email", func(t *testing.T) {
+		email := "jane@example.com"
+		var storedHash string
+		mockDB.EXPECT().CreatePasswordReset(email, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_, tokenHash string, expiresAt time.Time) (*models.User, error) {
+				storedHash = tokenHash
+				assert.WithinDuration(t, time.Now().Add(passwordResetTTL), expiresAt, time.Minute)
+				return &models.User{Username: "jane", Email: &email}, nil
+			})
+
+		w := sendAccountRequest(router, "POST", "/api/auth/password-reset", `{"email": "jane@example.com"}`)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		// Only the recipient gets the token, the database only its hash
+		assert.Len(t, notifier.messages, 1)
+		assert.Equal(t, email, notifier.messages[0].To)
+		assert.NotContains(t, w.Body.String(), storedHash)
+		assert.NotContains(t, notifier.messages[0].Body, storedHash)
+	})
+
+	t.Run("Unknown email", func(t *testing.T) {
+		mockDB.EXPECT().CreatePasswordReset("nobody@example.com", gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
+
+		w := sendAccountRequest(router, "POST", "/api/auth/password-reset", `{"email": "nobody@example.com"}`)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Len(t, notifier.messages, 1)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		mockDB.EXPECT().GetPasswordResetUsername(middleware.HashToken("reset-token")).Return("jane", nil)
+		mockDB.EXPECT().ResetPassword(middleware.HashToken("reset-token"), gomock.Any()).Return("jane", nil)
+
+		w := sendAccountRequest(router, "POST", "/api/auth/password-reset/confirm", `{"token": "reset-token", "new_password": "new-password"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Confirm with invalid token", func(t *testing.T) {
+		mockDB.EXPECT().GetPasswordResetUsername(middleware.HashToken("used-token")).Return("", database.ErrResetTokenInvalid)
+
+		w := sendAccountRequest(router, "POST", "/api/auth/password-reset/confirm", `{"token": "used-token", "new_password": "new-password"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Confirm with password containing the username", func(t *testing.T) {
+		mockDB.EXPECT().GetPasswordResetUsername(middleware.HashToken("reset-token")).Return("jane", nil)
+
+		w := sendAccountRequest(router, "POST", "/api/auth/password-reset/confirm", `{"token": "reset-token", "new_password": "jane-password"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	router, mockDB, _ := setupAccountTestRouter(t)
+	hash, err := utils.HashPassword("password")
+	assert.NoError(t, err)
+	user := &models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleResident}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().RevokeToken("current-jti", gomock.Any()).Return(nil)
+		mockDB.EXPECT().DeleteUser("jane").Return(nil)
+
+		w := sendAccountRequest(router, "DELETE", "/api/me", `{"password": "password"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+
+		w := sendAccountRequest(router, "DELETE", "/api/me", `{"password": "guess"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Password required", func(t *testing.T) {
+		w := sendAccountRequest(router, "DELETE", "/api/me", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
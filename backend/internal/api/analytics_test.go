@@ -67,7 +67,7 @@ func TestEngineerPerformanceAnalytics(t *testing.T) {
 		}
 
 		// Set mock expectation - this should be called when the handler runs
-		mockDB.EXPECT().GetEngineerPerformance().Return(performanceData, nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(performanceData, nil)
 
 		// Create request
 		req, _ := http.NewRequest("GET", "/api/analytics/engineers", nil)
@@ -89,7 +89,7 @@ func TestEngineerPerformanceAnalytics(t *testing.T) {
 
 	// Test case 2: Database error
 	t.Run("Database Error", func(t *testing.T) {
-		mockDB.EXPECT().GetEngineerPerformance().Return(nil, errors.New("database error"))
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(nil, errors.New("database error"))
 
 		req, _ := http.NewRequest("GET", "/api/analytics/engineers", nil)
 		w := httptest.NewRecorder()
@@ -106,7 +106,7 @@ func TestEngineerPerformanceAnalytics(t *testing.T) {
 
 	// Test case 3: No data found
 	t.Run("No Data", func(t *testing.T) {
-		mockDB.EXPECT().GetEngineerPerformance().Return([]*models.EngineerPerformance{}, nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return([]*models.EngineerPerformance{}, nil)
 
 		req, _ := http.NewRequest("GET", "/api/analytics/engineers", nil)
 		w := httptest.NewRecorder()
@@ -135,6 +135,6 @@ func TestEngineerPerformanceAnalytics(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 		assert.Contains(t, response, "error")
-		assert.Equal(t, "Staff access required", response["error"])
+		assert.Equal(t, "Insufficient permissions", response["error"])
 	})
 }
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoadAutoAssignOnCreate reads AUTO_ASSIGN_ON_CREATE, which makes new reports go
+// straight to an engineer. It is off unless set to a true value.
+func LoadAutoAssignOnCreate() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("AUTO_ASSIGN_ON_CREATE"))
+	return enabled
+}
+
+// autoAssign picks the best available engineer for an issue and assigns them.
+// Returns nil without assigning when there is no active engineer.
+func (h *Handler) autoAssign(issueID int64, issueType models.IssueType, actor string) (*models.AssignmentDecision, error) {
+	workloads, err := h.db.GetEngineerPerformance(false)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := models.ChooseEngineer(issueType, workloads)
+	if decision == nil {
+		return nil, nil
+	}
+
+	if err := h.db.AssignIssue(issueID, decision.EngineerID, decision.Reason, actor); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// @Summary Auto-assign issue
+// @Description Assign the issue to the active engineer specialising in its type with the fewest open issues, recording why they were chosen
+// @Tags issues
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} models.AssignmentDecision
+// @Failure 400,403,404,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/auto-assign [post]
+func (h *Handler) AutoAssignIssue(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if !models.IsOpenStatus(issue.Status) {
+		utils.RespondWithError(c, http.StatusConflict, "Issue is no longer open", nil)
+		return
+	}
+
+	actor, _ := c.Get("userID")
+	actorName, _ := actor.(string)
+
+	decision, err := h.autoAssign(id, issue.Type, actorName)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to assign issue", err)
+		return
+	}
+	if decision == nil {
+		utils.RespondWithError(c, http.StatusConflict, "No active engineer available", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
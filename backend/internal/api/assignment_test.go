@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func assignmentWorkloads() []*models.EngineerPerformance {
+	return []*models.EngineerPerformance{
+		{Engineer: &models.Engineer{ID: 1, Name: "John Smith", Specialization: "Roads and Infrastructure", Active: true}, IssuesAssigned: 5},
+		{Engineer: &models.Engineer{ID: 2, Name: "Emma Johnson", Specialization: "Environmental Services", Active: true}, IssuesAssigned: 0},
+		{Engineer: &models.Engineer{ID: 5, Name: "David Garcia", Specialization: "Roads and Infrastructure", Active: true}, IssuesAssigned: 1},
+	}
+}
+
+func TestAutoAssignIssue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	requireAssign := middleware.RequirePermission(models.PermIssuesAssign)
+	router := gin.New()
+	router.POST("/api/issues/:id/auto-assign", func(c *gin.Context) {
+		c.Set("userID", "dispatcher")
+		c.Set("userType", models.RoleDispatcher)
+		c.Next()
+	}, requireAssign, handler.AutoAssignIssue)
+	router.POST("/api/public/issues/:id/auto-assign", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", models.RoleResident)
+		c.Next()
+	}, requireAssign, handler.AutoAssignIssue)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Picks the least loaded specialist", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Type: models.TypePothole, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(assignmentWorkloads(), nil)
+		mockDB.EXPECT().AssignIssue(int64(1), int64(5), gomock.Any(), "dispatcher").Return(nil)
+
+		w := send("/api/issues/1/auto-assign")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var decision models.AssignmentDecision
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &decision))
+		assert.Equal(t, int64(5), decision.EngineerID)
+		assert.Equal(t, "David Garcia", decision.EngineerName)
+		assert.Equal(t, 1, decision.OpenIssues)
+		assert.Contains(t, decision.Reason, "Roads and Infrastructure")
+	})
+
+	t.Run("No active engineer", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Type: models.TypePothole, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return([]*models.EngineerPerformance{}, nil)
+
+		w := send("/api/issues/1/auto-assign")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Closed issue", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(2)).Return(&models.Issue{ID: 2, Type: models.TypePothole, Status: models.StatusClosed}, nil)
+
+		w := send("/api/issues/2/auto-assign")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		w := send("/api/issues/99/auto-assign")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Assignment fails", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Type: models.TypePothole, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(assignmentWorkloads(), nil)
+		mockDB.EXPECT().AssignIssue(int64(1), int64(5), gomock.Any(), "dispatcher").Return(errors.New("db error"))
+
+		w := send("/api/issues/1/auto-assign")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Staff only", func(t *testing.T) {
+		w := send("/api/public/issues/1/auto-assign")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateIssueAutoAssign(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB, autoAssignOnCreate: true}
+
+	router := gin.New()
+	router.POST("/api/issues", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Next()
+	}, handler.CreateIssue)
+
+	send := func() *httptest.ResponseRecorder {
+		body, contentType := createDuplicateTestForm(false)
+		req, _ := http.NewRequest("POST", "/api/issues", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Assigns the new issue", func(t *testing.T) {
+		mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(8), nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(assignmentWorkloads(), nil)
+		mockDB.EXPECT().AssignIssue(int64(8), int64(5), gomock.Any(), "system").Return(nil)
+
+		w := send()
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			ID         int64                      `json:"id"`
+			Assignment *models.AssignmentDecision `json:"assignment"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(8), response.ID)
+		assert.Equal(t, int64(5), response.Assignment.EngineerID)
+	})
+
+	t.Run("Failed assignment still creates the issue", func(t *testing.T) {
+		mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(9), nil)
+		mockDB.EXPECT().GetEngineerPerformance(false).Return(nil, errors.New("db error"))
+
+		w := send()
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "assignment")
+	})
+}
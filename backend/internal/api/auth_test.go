@@ -72,7 +72,7 @@ func setupAuthTestRouter(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOperati
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getCommentForIssue loads the comment named in the URL and checks it belongs to the issue in the URL.
+// It writes the error response and returns nil if the comment can't be used.
+func (h *Handler) getCommentForIssue(c *gin.Context) *models.Comment {
+	issueID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return nil
+	}
+
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid comment ID", err)
+		return nil
+	}
+
+	comment, err := h.db.GetComment(commentID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get comment", err)
+		return nil
+	}
+
+	// Internal notes are hidden from residents entirely
+	if comment == nil || comment.IssueID != issueID || (comment.Internal && !middleware.HasPermission(c, models.PermCommentsInternal)) {
+		utils.RespondWithError(c, http.StatusNotFound, "Comment not found", nil)
+		return nil
+	}
+
+	return comment
+}
+
+// @Summary List issue comments
+// @Description Get the comments on an issue. Residents can read the comments on their own reports; internal notes are only returned to staff.
+// @Tags comments
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {array} models.Comment
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/comments [get]
+func (h *Handler) ListComments(c *gin.Context) {
+	// Authenticate user
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+
+	// Residents can only read the conversation on their own reports
+	staff := middleware.HasPermission(c, models.PermCommentsInternal)
+	if !staff {
+		reporter, err := h.isReporter(issue, userID.(string))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+			return
+		}
+		if !reporter {
+			utils.RespondWithError(c, http.StatusForbidden, "Only the reporter can read the comments on this issue", nil)
+			return
+		}
+	}
+
+	comments, err := h.db.ListComments(id, staff)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list comments", err)
+		return
+	}
+	for _, comment := range comments {
+		if comment.Images, err = h.presignKeys(c.Request.Context(), comment.Images); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list comments", err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// @Summary Add a comment to an issue
+// @Description Add a comment with optional images. Residents can comment on their own reports; staff can also add internal notes.
+// @Tags comments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param body formData string true "Comment text"
+// @Param internal formData boolean false "Staff-only internal note"
+// @Param images formData file false "Images to attach (multiple allowed)"
+// @Success 201 {object} map[string]int64
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/comments [post]
+func (h *Handler) CreateComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	// Parse multipart form (handle file uploads)
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB limit
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid form data", err)
+		return
+	}
+
+	body := c.PostForm("body")
+	if body == "" {
+		utils.RespondWithError(c, http.StatusBadRequest, "Comment body is required", nil)
+		return
+	}
+	internal, _ := strconv.ParseBool(c.PostForm("internal"))
+
+	staff := middleware.HasPermission(c, models.PermCommentsInternal)
+	if internal && !staff {
+		utils.RespondWithError(c, http.StatusForbidden, "Staff access required for internal notes", nil)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+
+	// Residents can only follow up on their own reports
+	if !staff {
+		reporter, err := h.isReporter(issue, userID.(string))
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+			return
+		}
+		if !reporter {
+			utils.RespondWithError(c, http.StatusForbidden, "Only the reporter can comment on this issue", nil)
+			return
+		}
+	}
+
+	// Process images (if provided)
+	uploaded := models.IssueImages{}
+	imageKeys := []string{}
+	if c.Request.MultipartForm != nil {
+		for _, fileHeader := range c.Request.MultipartForm.File["images"] {
+			image, err := h.uploadImageFile(c, fileHeader)
+			if err != nil {
+				h.deleteImageObjects(c.Request.Context(), uploaded)
+				utils.RespondWithError(c, http.StatusInternalServerError, "Failed to upload image", err)
+				return
+			}
+
+			uploaded = append(uploaded, image)
+			imageKeys = append(imageKeys, image.Original)
+		}
+	}
+
+	comment := models.Comment{
+		IssueID:  id,
+		Author:   userID.(string),
+		Body:     body,
+		Internal: internal,
+		Images:   imageKeys,
+	}
+
+	commentID, err := h.db.CreateComment(&comment)
+	if err != nil {
+		// Nothing refers to the uploads unless the comment was saved
+		h.deleteImageObjects(c.Request.Context(), uploaded)
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create comment", err)
+		return
+	}
+
+	// Comment images are only kept at full size, so the smaller renditions go
+	for _, image := range uploaded {
+		h.deleteObjects(c.Request.Context(), image.Keys()[1:])
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": commentID})
+}
+
+// @Summary Edit a comment
+// @Description Edit the text of a comment. Only the author may edit their comment.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param commentId path int true "Comment ID"
+// @Param comment body models.CommentUpdate true "New comment text"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/comments/{commentId} [put]
+func (h *Handler) UpdateComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	comment := h.getCommentForIssue(c)
+	if comment == nil {
+		return
+	}
+
+	if comment.Author != userID {
+		utils.RespondWithError(c, http.StatusForbidden, "Only the author can edit this comment", nil)
+		return
+	}
+
+	var update models.CommentUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if err := h.db.UpdateComment(comment.ID, update.Body); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update comment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment updated successfully"})
+}
+
+// @Summary Delete a comment
+// @Description Delete a comment and its images. The author, a dispatcher, a supervisor or an admin may delete a comment.
+// @Tags comments
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param commentId path int true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/comments/{commentId} [delete]
+func (h *Handler) DeleteComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	comment := h.getCommentForIssue(c)
+	if comment == nil {
+		return
+	}
+
+	if comment.Author != userID && !middleware.HasPermission(c, models.PermCommentsModerate) {
+		utils.RespondWithError(c, http.StatusForbidden, "Only the author can delete this comment", nil)
+		return
+	}
+
+	images, err := h.db.DeleteComment(comment.ID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete comment", err)
+		return
+	}
+	h.deleteObjects(c.Request.Context(), images)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
@@ -0,0 +1,386 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// setupCommentTestRouter registers the comment routes with a fixed user identity
+func setupCommentTestRouter(t *testing.T, userID, userType string) (*gin.Engine, *dbMock.MockDatabaseOperations, *storage.MemoryStore) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	store := storage.NewMemoryStore("", nil)
+	handler := NewHandler(mockDB, store)
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("userType", userType)
+		c.Next()
+	})
+	api.GET("/issues/:id/comments", handler.ListComments)
+	api.POST("/issues/:id/comments", handler.CreateComment)
+	api.PUT("/issues/:id/comments/:commentId", handler.UpdateComment)
+	api.DELETE("/issues/:id/comments/:commentId", handler.DeleteComment)
+
+	return router, mockDB, store
+}
+
+// createCommentForm builds a multipart comment form
+func createCommentForm(body string, internal bool) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if body != "" {
+		_ = writer.WriteField("body", body)
+	}
+	if internal {
+		_ = writer.WriteField("internal", "true")
+	}
+	writer.Close()
+	return &buf, writer.FormDataContentType()
+}
+
+// createCommentPhotoForm builds a multipart comment form with one photo
+func createCommentPhotoForm(t *testing.T, body string) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	_ = writer.WriteField("body", body)
+	fileWriter, _ := writer.CreateFormFile("images", "pothole.jpg")
+	_, _ = fileWriter.Write(testJPEG(t, 64, 48))
+	writer.Close()
+	return &buf, writer.FormDataContentType()
+}
+
+func TestListComments(t *testing.T) {
+	t.Run("Staff See Internal Notes", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "staff_user", "staff")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1}, nil)
+		mockDB.EXPECT().ListComments(int64(1), true).Return([]*models.Comment{
+			{ID: 1, IssueID: 1, Author: "resident", Body: "Still there"},
+			{ID: 2, IssueID: 1, Author: "staff_user", Body: "Crew booked", Internal: true},
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []models.Comment
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response, 2)
+	})
+
+	t.Run("Residents Only See Public Comments", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "resident"}, nil)
+		mockDB.EXPECT().ListComments(int64(1), false).Return([]*models.Comment{}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Resident Cannot Read Others Reports", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "someone_else"}, nil)
+		mockDB.EXPECT().IsIssueReporter(int64(1), "resident").Return(false, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Merged Reporter Can Read", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		// The resident's own report was merged into this issue
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "someone_else"}, nil)
+		mockDB.EXPECT().IsIssueReporter(int64(1), "resident").Return(true, nil)
+		mockDB.EXPECT().ListComments(int64(1), false).Return([]*models.Comment{}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Issue Not Found", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/99/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "resident"}, nil)
+		mockDB.EXPECT().ListComments(int64(1), false).Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/comments", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestCreateComment(t *testing.T) {
+	t.Run("Reporter Adds Follow Up", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "resident"}, nil)
+		mockDB.EXPECT().CreateComment(gomock.Any()).DoAndReturn(func(comment *models.Comment) (int64, error) {
+			assert.Equal(t, int64(1), comment.IssueID)
+			assert.Equal(t, "resident", comment.Author)
+			assert.Equal(t, "It is getting bigger", comment.Body)
+			assert.False(t, comment.Internal)
+			return 5, nil
+		})
+
+		body, contentType := createCommentForm("It is getting bigger", false)
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"id":5}`, w.Body.String())
+	})
+
+	t.Run("Reporter Adds Photo", func(t *testing.T) {
+		router, mockDB, store := setupCommentTestRouter(t, "resident", "public")
+
+		var images []string
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "resident"}, nil)
+		mockDB.EXPECT().CreateComment(gomock.Any()).DoAndReturn(func(comment *models.Comment) (int64, error) {
+			images = comment.Images
+			return 5, nil
+		})
+
+		body, contentType := createCommentPhotoForm(t, "It is getting bigger")
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Only the original is kept
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Len(t, images, 1)
+		assert.Equal(t, images, store.Keys())
+	})
+
+	t.Run("Staff Adds Internal Note", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "staff_user", "staff")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "resident"}, nil)
+		mockDB.EXPECT().CreateComment(gomock.Any()).DoAndReturn(func(comment *models.Comment) (int64, error) {
+			assert.True(t, comment.Internal)
+			return 6, nil
+		})
+
+		body, contentType := createCommentForm("Needs a road closure", true)
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Resident Cannot Add Internal Note", func(t *testing.T) {
+		router, _, _ := setupCommentTestRouter(t, "resident", "public")
+
+		body, contentType := createCommentForm("Sneaky", true)
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Resident Cannot Comment On Others Reports", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, ReportedBy: "someone_else"}, nil)
+		mockDB.EXPECT().IsIssueReporter(int64(1), "resident").Return(false, nil)
+
+		body, contentType := createCommentForm("Me too", false)
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Missing Body", func(t *testing.T) {
+		router, _, _ := setupCommentTestRouter(t, "resident", "public")
+
+		body, contentType := createCommentForm("", false)
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		router, mockDB, store := setupCommentTestRouter(t, "staff_user", "staff")
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1}, nil)
+		mockDB.EXPECT().CreateComment(gomock.Any()).Return(int64(0), errors.New("database error"))
+
+		body, contentType := createCommentPhotoForm(t, "Note")
+		req, _ := http.NewRequest("POST", "/api/issues/1/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		// The upload is removed again
+		assert.Empty(t, store.Keys())
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	t.Run("Author Edits Comment", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "resident"}, nil)
+		mockDB.EXPECT().UpdateComment(int64(3), "Corrected text").Return(nil)
+
+		req, _ := http.NewRequest("PUT", "/api/issues/1/comments/3", bytes.NewBufferString(`{"body":"Corrected text"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Staff Cannot Edit Others Comments", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "staff_user", "staff")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "resident"}, nil)
+
+		req, _ := http.NewRequest("PUT", "/api/issues/1/comments/3", bytes.NewBufferString(`{"body":"Edited"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Comment On Different Issue", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 2, Author: "resident"}, nil)
+
+		req, _ := http.NewRequest("PUT", "/api/issues/1/comments/3", bytes.NewBufferString(`{"body":"Edited"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Comment ID", func(t *testing.T) {
+		router, _, _ := setupCommentTestRouter(t, "resident", "public")
+
+		req, _ := http.NewRequest("PUT", "/api/issues/1/comments/abc", bytes.NewBufferString(`{"body":"Edited"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("Staff Deletes Any Comment", func(t *testing.T) {
+		router, mockDB, store := setupCommentTestRouter(t, "staff_user", "staff")
+		assert.NoError(t, store.Put(context.Background(), "a.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"))
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "resident", Images: []string{"a.jpg"}}, nil)
+		mockDB.EXPECT().DeleteComment(int64(3)).Return([]string{"a.jpg"}, nil)
+
+		req, _ := http.NewRequest("DELETE", "/api/issues/1/comments/3", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// Its images are deleted from storage too
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("Resident Cannot Delete Others Comments", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "someone_else"}, nil)
+
+		req, _ := http.NewRequest("DELETE", "/api/issues/1/comments/3", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Internal Note Hidden From Residents", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "staff_user", Internal: true}, nil)
+
+		req, _ := http.NewRequest("DELETE", "/api/issues/1/comments/3", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		router, mockDB, _ := setupCommentTestRouter(t, "resident", "public")
+
+		mockDB.EXPECT().GetComment(int64(3)).Return(&models.Comment{ID: 3, IssueID: 1, Author: "resident"}, nil)
+		mockDB.EXPECT().DeleteComment(int64(3)).Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("DELETE", "/api/issues/1/comments/3", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -312,7 +312,7 @@ func TestCreateIssueSuccess(t *testing.T) {
 			assert.Equal(t, 51.5074, issue.Location.Latitude)
 			assert.Equal(t, -0.1278, issue.Location.Longitude)
 			assert.Equal(t, "test_user", issue.ReportedBy)
-			// Note: we're not checking images here since we can't mock storage.UploadImage
+			// Images are covered by TestCreateIssueStoresImages
 		}).
 		Return(int64(1), nil)
 	
@@ -327,7 +327,7 @@ func TestCreateIssueSuccess(t *testing.T) {
 	})
 	api.POST("/issues", handler.CreateIssue)
 	
-	// Create a basic form without images since we can't easily mock the storage
+	// Create a basic form without images
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 	
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDuplicateRadiusMeters = 50
+	defaultDuplicateWindow       = 30 * 24 * time.Hour
+)
+
+// DuplicateSettings controls how close and how recent an open issue must be to be
+// offered as a duplicate of a new report. A zero radius disables the check.
+type DuplicateSettings struct {
+	RadiusMeters float64
+	Window       time.Duration
+}
+
+// LoadDuplicateSettings reads DUPLICATE_RADIUS_METERS and DUPLICATE_WINDOW_HOURS,
+// falling back to the defaults when they are unset or invalid.
+func LoadDuplicateSettings() DuplicateSettings {
+	settings := DuplicateSettings{
+		RadiusMeters: defaultDuplicateRadiusMeters,
+		Window:       defaultDuplicateWindow,
+	}
+	if radius, err := strconv.ParseFloat(os.Getenv("DUPLICATE_RADIUS_METERS"), 64); err == nil && radius >= 0 {
+		settings.RadiusMeters = radius
+	}
+	if hours, err := strconv.Atoi(os.Getenv("DUPLICATE_WINDOW_HOURS")); err == nil && hours > 0 {
+		settings.Window = time.Duration(hours) * time.Hour
+	}
+	return settings
+}
+
+// findDuplicates returns open issues that may describe the same problem as the new report
+func (h *Handler) findDuplicates(issue *models.IssueCreate) ([]*models.DuplicateCandidate, error) {
+	if h.duplicates.RadiusMeters <= 0 {
+		return nil, nil
+	}
+	return h.db.FindDuplicateCandidates(
+		issue.Type,
+		issue.Location.Latitude,
+		issue.Location.Longitude,
+		h.duplicates.RadiusMeters,
+		time.Now().Add(-h.duplicates.Window),
+	)
+}
+
+// isReporter reports whether the user reported the issue, including through a
+// duplicate merged into it
+func (h *Handler) isReporter(issue *models.Issue, username string) (bool, error) {
+	if issue.ReportedBy == username {
+		return true, nil
+	}
+	return h.db.IsIssueReporter(issue.ID, username)
+}
+
+// @Summary Merge duplicate issues
+// @Description Mark issues as duplicates of this open issue, moving their images and reporters onto it. Images beyond the limit of 10 per issue stay with their duplicate. Issues listed more than once are merged once.
+// @Tags issues
+// @Accept json
+// @Produce json
+// @Param id path int true "Primary issue ID"
+// @Param merge body models.IssueMerge true "Issues to merge"
+// @Success 200 {object} map[string]string
+// @Failure 400,404,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/merge [post]
+func (h *Handler) MergeIssues(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	var merge models.IssueMerge
+	if err := c.ShouldBindJSON(&merge); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	primary, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if primary == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if !models.IsOpenStatus(primary.Status) {
+		utils.RespondWithError(c, http.StatusConflict, "Cannot merge into an issue that is no longer open", nil)
+		return
+	}
+
+	for _, duplicateID := range merge.IssueIDs {
+		if duplicateID == id {
+			utils.RespondWithError(c, http.StatusBadRequest, "Cannot merge an issue into itself", nil)
+			return
+		}
+	}
+
+	// The statuses are checked again with the issues locked, so this is the final word
+	err = h.db.MergeIssues(id, merge.IssueIDs, userID.(string), maxIssueImages)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	case errors.Is(err, database.ErrMergePrimaryClosed):
+		utils.RespondWithError(c, http.StatusConflict, "Cannot merge into an issue that is no longer open", err)
+		return
+	case errors.Is(err, database.ErrCannotMarkDuplicate):
+		utils.RespondWithError(c, http.StatusConflict, err.Error(), err)
+		return
+	case err != nil:
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to merge issues", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Issues merged successfully"})
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func createDuplicateTestForm(linkDuplicate bool) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("type", "POTHOLE")
+	_ = writer.WriteField("description", "Pothole outside the bakery")
+	_ = writer.WriteField("latitude", "50.7184")
+	_ = writer.WriteField("longitude", "-3.5339")
+	if linkDuplicate {
+		_ = writer.WriteField("link_duplicate", "true")
+	}
+	writer.Close()
+	return &body, writer.FormDataContentType()
+}
+
+func TestCreateIssueDuplicateDetection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	router := gin.New()
+
+	handler := &Handler{db: mockDB, duplicates: DuplicateSettings{RadiusMeters: 75, Window: 24 * time.Hour}}
+	router.POST("/api/issues", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Next()
+	}, handler.CreateIssue)
+
+	existing := &models.DuplicateCandidate{Issue: models.Issue{ID: 7, Type: models.TypePothole, Status: models.StatusTriaged}, DistanceMeters: 12.5}
+
+	t.Run("Returns candidates", func(t *testing.T) {
+		mockDB.EXPECT().FindDuplicateCandidates(models.TypePothole, 50.7184, -3.5339, 75.0, gomock.Any()).
+			Do(func(_ models.IssueType, _, _, _ float64, since time.Time) {
+				assert.WithinDuration(t, time.Now().Add(-24*time.Hour), since, time.Minute)
+			}).
+			Return([]*models.DuplicateCandidate{existing}, nil)
+		mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(8), nil)
+
+		body, contentType := createDuplicateTestForm(false)
+		req, _ := http.NewRequest("POST", "/api/issues", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			ID                 int64                       `json:"id"`
+			PossibleDuplicates []models.DuplicateCandidate `json:"possible_duplicates"`
+			DuplicateOf        *int64                      `json:"duplicate_of"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(8), response.ID)
+		assert.Len(t, response.PossibleDuplicates, 1)
+		assert.Equal(t, 12.5, response.PossibleDuplicates[0].DistanceMeters)
+		assert.Nil(t, response.DuplicateOf)
+	})
+
+	t.Run("Links to the nearest candidate", func(t *testing.T) {
+		mockDB.EXPECT().FindDuplicateCandidates(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*models.DuplicateCandidate{existing}, nil)
+		mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(9), nil)
+		mockDB.EXPECT().MergeIssues(int64(7), []int64{9}, "resident", maxIssueImages).Return(nil)
+
+		body, contentType := createDuplicateTestForm(true)
+		req, _ := http.NewRequest("POST", "/api/issues", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(7), response["duplicate_of"])
+	})
+
+	t.Run("No candidates", func(t *testing.T) {
+		mockDB.EXPECT().FindDuplicateCandidates(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]*models.DuplicateCandidate{}, nil)
+		mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(10), nil)
+
+		body, contentType := createDuplicateTestForm(true)
+		req, _ := http.NewRequest("POST", "/api/issues", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"id": 10}`, w.Body.String())
+	})
+
+	t.Run("Lookup error", func(t *testing.T) {
+		mockDB.EXPECT().FindDuplicateCandidates(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("database error"))
+
+		body, contentType := createDuplicateTestForm(false)
+		req, _ := http.NewRequest("POST", "/api/issues", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestMergeIssuesHandler(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	merge := func(id string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/issues/"+id+"/merge", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned}, nil)
+		mockDB.EXPECT().MergeIssues(int64(1), []int64{2, 3}, "test_user", maxIssueImages).Return(nil)
+
+		w := merge("1", `{"issue_ids": [2, 3]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Missing issue IDs", func(t *testing.T) {
+		w := merge("1", `{"issue_ids": []}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Merge into itself", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusNew}, nil)
+
+		w := merge("1", `{"issue_ids": [1]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Primary closed", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusResolved}, nil)
+
+		w := merge("1", `{"issue_ids": [2]}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Primary not found", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		w := merge("99", `{"issue_ids": [2]}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Duplicate already in progress", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusNew}, nil)
+		mockDB.EXPECT().MergeIssues(int64(1), []int64{4}, "test_user", maxIssueImages).
+			Return(fmt.Errorf("issue 4 is IN_PROGRESS: %w", database.ErrCannotMarkDuplicate))
+
+		w := merge("1", `{"issue_ids": [4]}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "issue 4 is IN_PROGRESS")
+	})
+
+	t.Run("Duplicate not found", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusNew}, nil)
+		mockDB.EXPECT().MergeIssues(int64(1), []int64{99}, "test_user", maxIssueImages).Return(sql.ErrNoRows)
+
+		w := merge("1", `{"issue_ids": [99]}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusNew}, nil)
+		mockDB.EXPECT().MergeIssues(int64(1), []int64{2}, "test_user", maxIssueImages).Return(errors.New("database error"))
+
+		w := merge("1", `{"issue_ids": [2]}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -73,7 +73,7 @@ func setupEngineerTestRouter(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOpe
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
@@ -117,7 +117,7 @@ func setupEngineerUnauthorizedRouter(t *testing.T) *gin.Engine {
 		Email: "john.doe@example.com",
 	}, nil).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		// Pass through the middleware but check userType in the handler
 		c.Next()
 	}).AnyTimes()
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create engineer
+// @Description Onboard a new engineer
+// @Tags engineers
+// @Accept json
+// @Produce json
+// @Param engineer body models.EngineerCreate true "Engineer details"
+// @Success 201 {object} map[string]int64
+// @Failure 400,403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineers [post]
+func (h *Handler) CreateEngineer(c *gin.Context) {
+	var engineer models.EngineerCreate
+	if err := c.ShouldBindJSON(&engineer); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	id, err := h.db.CreateEngineer(&engineer)
+	if errors.Is(err, database.ErrEngineerEmailTaken) {
+		utils.RespondWithError(c, http.StatusConflict, "An engineer with this email already exists", err)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create engineer", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// @Summary Update engineer
+// @Description Update an engineer's details, or reactivate a retired engineer
+// @Tags engineers
+// @Accept json
+// @Produce json
+// @Param id path int true "Engineer ID"
+// @Param engineer body models.EngineerUpdate true "Engineer fields to change"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineers/{id} [put]
+func (h *Handler) UpdateEngineer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid engineer ID", err)
+		return
+	}
+
+	var update models.EngineerUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	err = h.db.UpdateEngineer(id, &update)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Engineer not found", nil)
+		return
+	}
+	if errors.Is(err, database.ErrEngineerEmailTaken) {
+		utils.RespondWithError(c, http.StatusConflict, "An engineer with this email already exists", err)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update engineer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Engineer updated successfully"})
+}
+
+// @Summary Retire engineer
+// @Description Mark an engineer as inactive. Their history is kept but they can no longer be assigned issues.
+// @Tags engineers
+// @Produce json
+// @Param id path int true "Engineer ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineers/{id} [delete]
+func (h *Handler) DeleteEngineer(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid engineer ID", err)
+		return
+	}
+
+	err = h.db.DeactivateEngineer(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Engineer not found", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to deactivate engineer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Engineer deactivated successfully"})
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateEngineer(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/engineers", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().CreateEngineer(gomock.Any()).
+			Do(func(engineer *models.EngineerCreate) {
+				assert.Equal(t, "Priya Patel", engineer.Name)
+				assert.Equal(t, "priya.patel@chalkstone.gov.uk", engineer.Email)
+				assert.Equal(t, "Roads and Infrastructure", engineer.Specialization)
+			}).
+			Return(int64(6), nil)
+
+		w := create(`{"name": "Priya Patel", "email": "priya.patel@chalkstone.gov.uk", "phone": "+44 1234 567890", "specialization": "Roads and Infrastructure"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.JSONEq(t, `{"id": 6}`, w.Body.String())
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		w := create(`{"name": "Priya Patel", "email": "not-an-email"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		w := create(`{"email": "priya.patel@chalkstone.gov.uk"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Email taken", func(t *testing.T) {
+		mockDB.EXPECT().CreateEngineer(gomock.Any()).Return(int64(0), database.ErrEngineerEmailTaken)
+
+		w := create(`{"name": "Priya Patel", "email": "john.smith@chalkstone.gov.uk"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().CreateEngineer(gomock.Any()).Return(int64(0), errors.New("database error"))
+
+		w := create(`{"name": "Priya Patel", "email": "priya.patel@chalkstone.gov.uk"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateEngineer(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	update := func(id, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/engineers/"+id, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().UpdateEngineer(int64(1), gomock.Any()).
+			Do(func(id int64, update *models.EngineerUpdate) {
+				assert.Equal(t, "Drainage Systems", *update.Specialization)
+				assert.True(t, *update.Active)
+				assert.Nil(t, update.Name)
+			}).
+			Return(nil)
+
+		w := update("1", `{"specialization": "Drainage Systems", "active": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockDB.EXPECT().UpdateEngineer(int64(999), gomock.Any()).Return(sql.ErrNoRows)
+
+		w := update("999", `{"name": "Nobody"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Email taken", func(t *testing.T) {
+		mockDB.EXPECT().UpdateEngineer(int64(1), gomock.Any()).Return(database.ErrEngineerEmailTaken)
+
+		w := update("1", `{"email": "emma.johnson@chalkstone.gov.uk"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := update("abc", `{"name": "Nobody"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteEngineer(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	remove := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/engineers/"+id, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().DeactivateEngineer(int64(2)).Return(nil)
+
+		w := remove("2")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockDB.EXPECT().DeactivateEngineer(int64(999)).Return(sql.ErrNoRows)
+
+		w := remove("999")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().DeactivateEngineer(int64(2)).Return(errors.New("database error"))
+
+		w := remove("2")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestEngineerManagementNonStaff(t *testing.T) {
+	router := setupUnauthorizedRouter(t)
+
+	requests := []*http.Request{}
+	req, _ := http.NewRequest("POST", "/api/engineers", bytes.NewBufferString(`{"name": "A", "email": "a@b.com"}`))
+	requests = append(requests, req)
+	req, _ = http.NewRequest("PUT", "/api/engineers/1", bytes.NewBufferString(`{"name": "A"}`))
+	requests = append(requests, req)
+	req, _ = http.NewRequest("DELETE", "/api/engineers/1", nil)
+	requests = append(requests, req)
+
+	for _, req := range requests {
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, req.Method)
+	}
+}
+
+func TestUpdateIssueInactiveEngineer(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().GetEngineerByID(int64(2)).Return(&models.Engineer{ID: 2, Name: "Retired Engineer", Active: false}, nil)
+
+	req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBufferString(`{"assigned_to": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Engineer is no longer active")
+}
+
+func TestEngineerListingsIncludeInactive(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().ListEngineers(true).Return([]*models.Engineer{}, nil)
+	req, _ := http.NewRequest("GET", "/api/engineers?include_inactive=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockDB.EXPECT().GetEngineerPerformance(true).Return([]*models.EngineerPerformance{}, nil)
+	req, _ = http.NewRequest("GET", "/api/analytics/engineers?include_inactive=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"chalkstone.council/internal/middleware"
 	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	dbMock "chalkstone.council/internal/database/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -23,7 +25,7 @@ func TestGetEngineerComprehensive(t *testing.T) {
 	router := gin.Default()
 	
 	// Create a real Handler instance with our mock DB
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Create a test group with middleware that sets userType for staff
 	api := router.Group("/api")
@@ -37,7 +39,7 @@ func TestGetEngineerComprehensive(t *testing.T) {
 	// Setup routes for testing - with and without auth
 	engineersGroup := api.Group("/engineers")
 	engineersGroup.GET("/:id", addStaffAuth, handler.GetEngineer)
-	engineersGroup.GET("/noauth/:id", handler.GetEngineer) // Route without staff auth for testing
+	engineersGroup.GET("/noauth/:id", middleware.RequirePermission(models.PermEngineersRead), handler.GetEngineer) // Route without staff auth for testing
 
 	// Test case 1: Successfully retrieve an engineer
 	t.Run("Success", func(t *testing.T) {
@@ -140,6 +142,6 @@ func TestGetEngineerComprehensive(t *testing.T) {
 		var response map[string]string
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "Staff access required", response["error"])
+		assert.Equal(t, "Insufficient permissions", response["error"])
 	})
 }
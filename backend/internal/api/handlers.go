@@ -1,14 +1,20 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"log"
+	"mime/multipart"
 	"net/http"
-	"os"
 	"strconv"
+	"time"
 
+	"chalkstone.council/internal/credentials"
 	"chalkstone.council/internal/database"
 	"chalkstone.council/internal/middleware"
 	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/notify"
+	"chalkstone.council/internal/oidc"
 	"chalkstone.council/internal/storage"
 	"chalkstone.council/internal/utils"
 
@@ -16,25 +22,50 @@ import (
 )
 
 type Handler struct {
-	db database.DatabaseOperations
+	db                     database.DatabaseOperations
+	images                 storage.ObjectStore
+	imageURLExpiry         time.Duration
+	notifier               notify.Notifier
+	loginPolicy            models.LoginPolicy
+	passwordPolicy         credentials.Policy
+	totpIssuer             string
+	oidc                   *oidc.Provider
+	duplicates             DuplicateSettings
+	autoAssignOnCreate     bool
+	photoLocationTolerance float64
+	imageEditWindow        time.Duration
 }
 
-func NewHandler(db database.DatabaseOperations) *Handler {
-	return &Handler{db: db}
+func NewHandler(db database.DatabaseOperations, images storage.ObjectStore) *Handler {
+	return &Handler{
+		db:                     db,
+		images:                 images,
+		imageURLExpiry:         LoadImageURLExpiry(),
+		notifier:               notify.FromEnv(),
+		loginPolicy:            LoadLoginPolicy(),
+		passwordPolicy:         credentials.LoadPolicy(),
+		totpIssuer:             LoadTOTPIssuer(),
+		oidc:                   oidc.FromEnv(),
+		duplicates:             LoadDuplicateSettings(),
+		autoAssignOnCreate:     LoadAutoAssignOnCreate(),
+		photoLocationTolerance: LoadPhotoLocationTolerance(),
+		imageEditWindow:        LoadImageEditWindow(),
+	}
 }
 
 // @Summary Create new issue
-// @Description Create a new issue report with optional images
+// @Description Create a new issue report with optional images. Issues whose photos were taken further than PHOTO_LOCATION_TOLERANCE_METERS from the reported location are flagged for review. Without a location the request fails, suggesting the location of the first photo that has one. When AUTO_ASSIGN_ON_CREATE is enabled the issue is assigned to the best available engineer.
 // @Tags issues
 // @Accept multipart/form-data
 // @Produce json
 // @Param type formData string true "Issue type"
 // @Param description formData string true "Issue description"
-// @Param latitude formData number true "Latitude of the issue location"
-// @Param longitude formData number true "Longitude of the issue location"
+// @Param latitude formData number false "Latitude of the issue location"
+// @Param longitude formData number false "Longitude of the issue location"
 // @Param images formData file false "Images of the issue (multiple allowed)"
-// @Success 201 {object} map[string]int64
-// @Failure 400 {object} map[string]string
+// @Param link_duplicate formData boolean false "Link the report to the nearest matching open issue"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security Bearer
@@ -56,10 +87,11 @@ func (h *Handler) CreateIssue(c *gin.Context) {
 	// Extract form fields
 	issueType := c.PostForm("type")
 	description := c.PostForm("description")
+	hasLocation := c.PostForm("latitude") != "" || c.PostForm("longitude") != ""
 	latitude, latErr := strconv.ParseFloat(c.PostForm("latitude"), 64)
 	longitude, lonErr := strconv.ParseFloat(c.PostForm("longitude"), 64)
 
-	if issueType == "" || description == "" || latErr != nil || lonErr != nil {
+	if issueType == "" || description == "" || (hasLocation && (latErr != nil || lonErr != nil)) {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid issue data", nil)
 		return
 	}
@@ -70,27 +102,44 @@ func (h *Handler) CreateIssue(c *gin.Context) {
 		return
 	}
 
-	// Process images (if provided)
-	var imageURLs []string
+	var files []*multipart.FileHeader
 	if c.Request.MultipartForm != nil {
-		files := c.Request.MultipartForm.File["images"]
-		for _, fileHeader := range files {
-			file, err := fileHeader.Open()
-			if err != nil {
-				utils.RespondWithError(c, http.StatusInternalServerError, "Failed to open image file", err)
-				return
-			}
-			defer file.Close()
+		files = c.Request.MultipartForm.File["images"]
+	}
 
-			// Upload image to MinIO (or other storage service)
-			imageURL, err := storage.UploadImage(file, fileHeader.Filename)
-			if err != nil {
-				utils.RespondWithError(c, http.StatusInternalServerError, "Failed to upload image", err)
-				return
-			}
+	// Read where the photos were taken before their metadata is stripped on upload
+	locations, err := photoLocations(files)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to open image file", err)
+		return
+	}
+	if !hasLocation {
+		suggestion := suggestedLocation(locations)
+		if suggestion == nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid issue data", nil)
+			return
+		}
+		// Let the reporter confirm the photo's location rather than assuming it
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Location is required",
+			"suggested_location": gin.H{
+				"latitude":  suggestion.Latitude,
+				"longitude": suggestion.Longitude,
+			},
+		})
+		return
+	}
 
-			imageURLs = append(imageURLs, imageURL)
+	// Process images (if provided)
+	images := models.IssueImages{}
+	for _, fileHeader := range files {
+		image, err := h.uploadImageFile(c, fileHeader)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to upload image", err)
+			return
 		}
+
+		images = append(images, image)
 	}
 
 	// Create issue object
@@ -107,8 +156,22 @@ func (h *Handler) CreateIssue(c *gin.Context) {
 			Latitude:  latitude,
 			Longitude: longitude,
 		}),
-		Images:     imageURLs,           // Stores empty array if no images are uploaded
-		ReportedBy: reportedBy.(string), // Replace with real authenticated user
+		Images:         images,              // Stores empty array if no images are uploaded
+		ReportedBy:     reportedBy.(string), // Replace with real authenticated user
+		LocationReview: h.locationReview(latitude, longitude, locations),
+	}
+
+	// Look for open reports of the same problem before storing the new one
+	candidates, err := h.findDuplicates(&issue)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check for duplicate issues", err)
+		return
+	}
+	for _, candidate := range candidates {
+		if err := h.presignIssue(c.Request.Context(), &candidate.Issue); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to check for duplicate issues", err)
+			return
+		}
 	}
 
 	// Store issue in DB
@@ -118,29 +181,54 @@ func (h *Handler) CreateIssue(c *gin.Context) {
 		return
 	}
 
+	response := gin.H{"id": id}
+	if issue.LocationReview != nil {
+		log.Printf("Issue %d flagged for location review: %s", id, *issue.LocationReview)
+		response["location_review"] = *issue.LocationReview
+	}
+	if len(candidates) > 0 {
+		response["possible_duplicates"] = candidates
+
+		linkDuplicate, _ := strconv.ParseBool(c.PostForm("link_duplicate"))
+		if linkDuplicate {
+			// The report is already stored, so a failed link only loses the grouping
+			primaryID := candidates[0].ID
+			if err := h.db.MergeIssues(primaryID, []int64{id}, issue.ReportedBy, maxIssueImages); err != nil {
+				log.Printf("Failed to link issue %d to duplicate %d: %v", id, primaryID, err)
+			} else {
+				response["duplicate_of"] = primaryID
+			}
+		}
+	}
+
+	// Reports folded into an existing issue are already being handled
+	if _, linked := response["duplicate_of"]; !linked && h.autoAssignOnCreate {
+		// As above, the report is stored; staff can still assign it by hand
+		decision, err := h.autoAssign(id, issue.Type, "system")
+		if err != nil {
+			log.Printf("Failed to auto-assign issue %d: %v", id, err)
+		} else if decision != nil {
+			response["assignment"] = decision
+		}
+	}
+
 	// Success response
-	c.JSON(http.StatusCreated, gin.H{"id": id})
+	c.JSON(http.StatusCreated, response)
 }
 
 // @Summary Update issue
-// @Description Update an existing issue
+// @Description Update an existing issue's status, assigned engineer or priority. Changing the priority recalculates the SLA due date. Engineers can only start and resolve the issues assigned to them.
 // @Tags issues
 // @Accept json
 // @Produce json
 // @Param id path int true "Issue ID"
 // @Param issue body models.IssueUpdate true "Issue update details"
 // @Success 200 {object} map[string]string
-// @Failure 400,404 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Security Bearer
 // @Router /issues/{id} [put]
 func (h *Handler) UpdateIssue(c *gin.Context) {
-	// Staff authorization check
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		utils.RespondWithError(c, http.StatusForbidden, "Staff access required", nil)
-		return
-	}
-
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
@@ -159,6 +247,59 @@ func (h *Handler) UpdateIssue(c *gin.Context) {
 		return
 	}
 
+	// Validate issue priority
+	if update.Priority != nil && !models.ValidateIssuePriority(*update.Priority) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid issue priority", nil)
+		return
+	}
+
+	// Engineers may only move their own issues through the workflow
+	updateAny := middleware.HasPermission(c, models.PermIssuesUpdate)
+	if !updateAny && (update.AssignedTo != nil || update.Priority != nil) {
+		utils.RespondWithError(c, http.StatusForbidden, "Engineers can only change the status of their issues", nil)
+		return
+	}
+	if !updateAny && update.Status != nil && !models.ValidateEngineerStatus(*update.Status) {
+		utils.RespondWithError(c, http.StatusForbidden, "Engineers can only start and resolve their issues", nil)
+		return
+	}
+	if update.AssignedTo != nil && !middleware.HasPermission(c, models.PermIssuesAssign) {
+		utils.RespondWithError(c, http.StatusForbidden, "Insufficient permissions", nil)
+		return
+	}
+
+	if update.Status != nil || !updateAny {
+		issue, err := h.db.GetIssue(id)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+			return
+		}
+		if issue == nil {
+			utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+			return
+		}
+
+		if !updateAny {
+			engineerID, err := h.currentEngineerID(c)
+			if err != nil {
+				utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get engineer", err)
+				return
+			}
+			if engineerID == nil || issue.AssignedTo == nil || *issue.AssignedTo != *engineerID {
+				utils.RespondWithError(c, http.StatusForbidden, "You can only update issues assigned to you", nil)
+				return
+			}
+			// Checked again when the update is made, in case of a reassignment meanwhile
+			update.EngineerID = engineerID
+		}
+
+		// Enforce the issue lifecycle
+		if update.Status != nil && !models.CanTransition(issue.Status, *update.Status) {
+			respondInvalidTransition(c, issue.Status)
+			return
+		}
+	}
+
 	// Validate engineer assignment
 	if update.AssignedTo != nil {
 		// Check if the engineer exists
@@ -171,9 +312,30 @@ func (h *Handler) UpdateIssue(c *gin.Context) {
 			utils.RespondWithError(c, http.StatusBadRequest, "Invalid engineer ID", nil)
 			return
 		}
+		if !engineer.Active {
+			utils.RespondWithError(c, http.StatusBadRequest, "Engineer is no longer active", nil)
+			return
+		}
 	}
 
-	if err := h.db.UpdateIssue(id, &update); err != nil {
+	if userID, exists := c.Get("userID"); exists {
+		update.UpdatedBy, _ = userID.(string)
+	}
+
+	// The issue may have changed since it was loaded above
+	err = h.db.UpdateIssue(id, &update)
+	var transitionErr *database.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		respondInvalidTransition(c, transitionErr.From)
+		return
+	case errors.Is(err, database.ErrNotAssigned):
+		utils.RespondWithError(c, http.StatusForbidden, "You can only update issues assigned to you", nil)
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	case err != nil:
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update issue", err)
 		return
 	}
@@ -181,6 +343,16 @@ func (h *Handler) UpdateIssue(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Issue updated successfully"})
 }
 
+// respondInvalidTransition reports a status change the issue's current status doesn't
+// allow, with the statuses it may move to instead
+func respondInvalidTransition(c *gin.Context, current models.IssueStatus) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error":               "Invalid status transition",
+		"current_status":      current,
+		"allowed_transitions": models.AllowedTransitions(current),
+	})
+}
+
 // @Summary Get issue by ID
 // @Description Retrieve an issue by its ID
 // @Tags issues
@@ -215,16 +387,61 @@ func (h *Handler) GetIssue(c *gin.Context) {
 		return
 	}
 
+	if err := h.presignIssue(c.Request.Context(), issue); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue images", err)
+		return
+	}
+	issue.AllowedTransitions = models.AllowedTransitions(issue.Status)
+
 	c.JSON(http.StatusOK, issue)
 }
 
+// @Summary Get issue history
+// @Description Get the full timeline of status changes, reassignments and comments for an issue
+// @Tags issues
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {array} models.IssueEvent
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/history [get]
+func (h *Handler) GetIssueHistory(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+
+	events, err := h.db.GetIssueHistory(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve issue history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
 // @Summary List issues
 // @Description Get paginated list of issues
 // @Tags issues
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Page size" default(10)
+// @Param sort query string false "Sort order: newest or supporters" default(newest)
+// @Param location_review query bool false "Only issues whose location needs review"
 // @Success 200 {array} models.Issue
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security Bearer
 // @Router /issues [get]
@@ -247,11 +464,71 @@ func (h *Handler) ListIssues(c *gin.Context) {
 		pageSize = 10
 	}
 
-	issues, err := h.db.ListIssues(page, pageSize)
+	sort := models.IssueSort(c.DefaultQuery("sort", string(models.SortNewest)))
+	if !models.ValidateIssueSort(sort) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid sort", nil)
+		return
+	}
+
+	locationReview, _ := strconv.ParseBool(c.Query("location_review"))
+
+	issues, err := h.db.ListIssues(page, pageSize, sort, locationReview)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list issues", err)
 		return
 	}
+	if err := h.presignIssues(c.Request.Context(), issues); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list issues", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":     page,
+		"pageSize": pageSize,
+		"issues":   issues,
+	})
+}
+
+// @Summary List my reports
+// @Description Get a paginated list of the issues reported by the authenticated user, including issues their reports were merged into
+// @Tags issues
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10)
+// @Success 200 {array} models.ReportedIssue
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me/issues [get]
+func (h *Handler) ListMyIssues(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Validate pagination parameters
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	issues, err := h.db.ListIssuesByReporter(userID.(string), page, pageSize)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list your issues", err)
+		return
+	}
+	for _, issue := range issues {
+		if err := h.presignIssue(c.Request.Context(), &issue.Issue); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list your issues", err)
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"page":     page,
@@ -261,14 +538,26 @@ func (h *Handler) ListIssues(c *gin.Context) {
 }
 
 // @Summary Get issues for map
-// @Description Retrieve issue locations and types for the map view
+// @Description Retrieve issue locations and types for the map view, optionally limited to a bounding box or a radius around a point
 // @Tags issues
 // @Produce json
-// @Success 200 {array} object{id=int64,type=string,location=object{latitude=float64,longitude=float64},status=string}
+// @Param bbox query string false "Bounding box as minLon,minLat,maxLon,maxLat"
+// @Param near query string false "Centre point as lat,lon"
+// @Param radius query number false "Search radius in meters (required with near)"
+// @Param status query string false "Issue status"
+// @Param type query string false "Issue type"
+// @Success 200 {array} object{id=int64,type=string,location=object{latitude=float64,longitude=float64},status=string,supporter_count=int}
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /issues/map [get]
 func (h *Handler) GetIssuesForMap(c *gin.Context) {
-	issues, err := h.db.GetIssuesForMap()
+	filter, err := parseMapFilter(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	issues, err := h.db.GetIssuesForMap(filter)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve issues for map", err)
 		return
@@ -283,7 +572,8 @@ func (h *Handler) GetIssuesForMap(c *gin.Context) {
 				"latitude":  issue.Location.Latitude,
 				"longitude": issue.Location.Longitude,
 			},
-			"status": issue.Status,
+			"status":          issue.Status,
+			"supporter_count": issue.SupporterCount,
 		}
 	}
 
@@ -291,20 +581,16 @@ func (h *Handler) GetIssuesForMap(c *gin.Context) {
 }
 
 // @Summary List engineers
-// @Description Get a list of all engineers
+// @Description Get a list of all active engineers
 // @Tags engineers
 // @Produce json
+// @Param include_inactive query bool false "Include retired engineers"
 // @Success 200 {array} models.Engineer
 // @Security Bearer
 // @Router /engineers [get]
 func (h *Handler) ListEngineers(c *gin.Context) {
-	// Staff authorization check
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		utils.RespondWithError(c, http.StatusForbidden, "Staff access required", nil)
-		return
-	}
-	engineers, err := h.db.ListEngineers()
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+	engineers, err := h.db.ListEngineers(includeInactive)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve engineers", err)
 		return
@@ -323,13 +609,6 @@ func (h *Handler) ListEngineers(c *gin.Context) {
 // @Security Bearer
 // @Router /engineers/{id} [get]
 func (h *Handler) GetEngineer(c *gin.Context) {
-	// Staff authorization check
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		utils.RespondWithError(c, http.StatusForbidden, "Staff access required", nil)
-		return
-	}
-
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -352,20 +631,16 @@ func (h *Handler) GetEngineer(c *gin.Context) {
 }
 
 // @Summary Get engineer performance
-// @Description Get performance metrics for each engineer
+// @Description Get performance metrics for each active engineer
 // @Tags analytics
 // @Produce json
+// @Param include_inactive query bool false "Include retired engineers"
 // @Success 200 {array} models.EngineerPerformance
 // @Security Bearer
 // @Router /analytics/engineers [get]
 func (h *Handler) EngineerPerformance(c *gin.Context) {
-	// Staff authorization check
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		utils.RespondWithError(c, http.StatusForbidden, "Staff access required", nil)
-		return
-	}
-	performanceData, err := h.db.GetEngineerPerformance()
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+	performanceData, err := h.db.GetEngineerPerformance(includeInactive)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve engineer performance", err)
 		return
@@ -382,13 +657,6 @@ func (h *Handler) EngineerPerformance(c *gin.Context) {
 // @Security Bearer
 // @Router /analytics/resolution-time [get]
 func (h *Handler) ResolutionTime(c *gin.Context) {
-	// Staff authorization check
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		utils.RespondWithError(c, http.StatusForbidden, "Staff access required", nil)
-		return
-	}
-
 	resolutionTime, err := h.db.GetAverageResolutionTime()
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve resolution time analytics", err)
@@ -398,6 +666,23 @@ func (h *Handler) ResolutionTime(c *gin.Context) {
 	c.JSON(http.StatusOK, resolutionTime)
 }
 
+// @Summary Get time in status analytics
+// @Description Get the average time issues spend in each status, computed from the issue history
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Security Bearer
+// @Router /analytics/time-in-status [get]
+func (h *Handler) TimeInStatus(c *gin.Context) {
+	timeInStatus, err := h.db.GetAverageTimeInStatus()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve time in status analytics", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeInStatus)
+}
+
 // @Summary Search issues
 // @Description Search issues by type and status
 // @Tags issues
@@ -427,6 +712,10 @@ func (h *Handler) SearchIssues(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to search issues", err)
 		return
 	}
+	if err := h.presignIssues(c.Request.Context(), issues); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to search issues", err)
+		return
+	}
 
 	c.JSON(http.StatusOK, issues)
 }
@@ -443,13 +732,6 @@ func (h *Handler) SearchIssues(c *gin.Context) {
 // @Security Bearer
 // @Router /issues/analytics [get]
 func (h *Handler) GetIssueAnalytics(c *gin.Context) {
-	// Ensure only staff can access
-	userType, _ := c.Get("userType")
-	if userType != "staff" {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Staff access required"})
-		return
-	}
-
 	startDate := c.Query("startDate")
 	endDate := c.Query("endDate")
 
@@ -465,7 +747,7 @@ func (h *Handler) GetIssueAnalytics(c *gin.Context) {
 		return
 	}
 
-	engineerPerformance, err := h.db.GetEngineerPerformance()
+	engineerPerformance, err := h.db.GetEngineerPerformance(false)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to retrieve engineer performance", err)
 		return
@@ -478,13 +760,14 @@ func (h *Handler) GetIssueAnalytics(c *gin.Context) {
 }
 
 // @Summary User login
-// @Description Authenticate user and receive JWT token
+// @Description Authenticate user and receive a short-lived JWT access token and a refresh token. Users with two-factor authentication, and all staff, receive a challenge token instead, to be exchanged at /auth/2fa. Repeated failures for a username slow down further attempts and eventually lock it for a while.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body object{username=string,password=string} true "Login credentials"
 // @Success 200 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]interface{}
 // @Router /auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var creds struct {
@@ -498,44 +781,78 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.db.GetUserByUsername(creds.Username)
+	attempts, err := h.db.GetLoginAttempts(creds.Username)
 	if err != nil {
+		log.Printf("Get login attempts error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process login"})
+		return
+	}
+	if retryAt := h.loginPolicy.RetryAt(attempts); time.Now().Before(retryAt) {
+		log.Printf("Login throttled for %q", creds.Username)
+		respondLoginThrottled(c, retryAt)
+		return
+	}
+
+	// Unknown usernames and wrong passwords are handled, logged and timed the same
+	user, err := h.db.GetUserByUsername(creds.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		log.Printf("Get user error: %v", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
-
-	if !utils.CheckPasswordHash(creds.Password, user.PasswordHash) {
-		log.Printf("Invalid credentials")
+	if user == nil {
+		checkDummyPassword(creds.Password)
+	}
+	if user == nil || !utils.CheckPasswordHash(creds.Password, user.PasswordHash) {
+		log.Printf("Login failed for %q", creds.Username)
+		h.recordFailedLogin(creds.Username)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	token, err := middleware.GenerateToken(user.Username, user.UserType)
+	// Failed attempts are only cleared once the second factor is accepted too
+	enrollment, err := h.db.GetTOTPEnrollment(user.Username)
+	if err != nil {
+		log.Printf("Get TOTP enrollment error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process login"})
+		return
+	}
+	if enrollment.Enabled() || models.RequiresTwoFactor(user.UserType) {
+		respondTwoFactorChallenge(c, user.Username, enrollment.Enabled())
+		return
+	}
+
+	if attempts != nil {
+		if err := h.db.ClearLoginAttempts(user.Username); err != nil {
+			log.Printf("Clear login attempts error: %v", err)
+		}
+	}
+
+	tokens, err := h.issueTokens(user.Username, user.UserType)
 	if err != nil {
 		log.Printf("Generate token error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
 }
 
 // @Summary Register new user
-// @Description Register a new user account
+// @Description Register a new user account. The optional email is used for password resets. Staff accounts require an invite code issued to the given email, and receive a challenge token to enrol in two-factor authentication instead of tokens. Usernames and passwords that break the policy are rejected with every rule they fail, and a username already in use with a 409 and the rule "taken".
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param user body object{username=string,password=string,is_staff=boolean} true "User registration details"
+// @Param user body object{username=string,password=string,email=string,invite_code=string} true "User registration details"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
+// @Failure 400,409 {object} map[string]string
 // @Router /auth/register [post]
 func (h *Handler) Register(c *gin.Context) {
 	var reg struct {
-		Username    string `json:"username" binding:"required"`
-		Password    string `json:"password" binding:"required"`
-		IsStaff     bool   `json:"is_staff"`
-		StaffSecret string `json:"staff_secret"`
+		Username   string `json:"username" binding:"required"`
+		Password   string `json:"password" binding:"required"`
+		Email      string `json:"email" binding:"omitempty,email,max=255"`
+		InviteCode string `json:"invite_code"`
 	}
 
 	if err := c.ShouldBindJSON(&reg); err != nil {
@@ -544,6 +861,23 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	if reg.InviteCode != "" && reg.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required to redeem an invite"})
+		return
+	}
+
+	violations, err := h.passwordPolicy.ValidatePassword(reg.Password, reg.Username)
+	if err != nil {
+		log.Printf("Check password error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
+	violations = append(credentials.ValidateUsername(reg.Username), violations...)
+	if len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username or password", "violations": violations})
+		return
+	}
+
 	hashedPassword, err := utils.HashPassword(reg.Password)
 	if err != nil {
 		log.Printf("Hash password error: %v", err)
@@ -551,47 +885,58 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Staff registration validation
-	userType := "public"
-	if reg.IsStaff {
-		// Require staff secret for staff registration
-		if reg.StaffSecret == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Staff secret is required for staff registration"})
+	// Staff roles are only granted by redeeming an invite
+	userType := models.RoleResident
+	if reg.InviteCode != "" {
+		userType, err = h.db.RedeemStaffInvite(middleware.HashToken(reg.InviteCode), reg.Email, reg.Username, hashedPassword)
+		if errors.Is(err, database.ErrInviteInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite code"})
 			return
 		}
-		
-		// Get staff secret from environment variable with fallback for development
-		staffSecret := os.Getenv("STAFF_SECRET")
-		if staffSecret == "" {
-			// Fallback secret for development only
-			// In production, this should always come from an environment variable
-			staffSecret = "citycouncilstaff2023"
-			log.Printf("WARNING: STAFF_SECRET environment variable not set, using fallback value")
-		}
-		
-		// Use a constant-time comparison to prevent timing attacks
-		if !utils.SecureCompare(reg.StaffSecret, staffSecret) {
-			// Use same error message and delay to prevent user enumeration
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid staff secret"})
+		if errors.Is(err, database.ErrEngineerAlreadyLinked) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This engineer already has an account"})
 			return
 		}
-		
-		userType = "staff"
+	} else {
+		var email *string
+		if reg.Email != "" {
+			email = &reg.Email
+		}
+		err = h.db.CreateUser(reg.Username, hashedPassword, userType, email)
+	}
+	if errors.Is(err, database.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+	if errors.Is(err, database.ErrUsernameTaken) {
+		// Reported like the policy rules, so the form can show it on the username field
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Invalid username or password",
+			"violations": []credentials.Violation{{
+				Field:   "username",
+				Rule:    "taken",
+				Message: "Username is already in use",
+			}},
+		})
+		return
 	}
-
-	err = h.db.CreateUser(reg.Username, hashedPassword, userType)
 	if err != nil {
 		log.Printf("Create user error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	token, err := middleware.GenerateToken(reg.Username, userType)
+	if models.RequiresTwoFactor(userType) {
+		respondTwoFactorChallenge(c, reg.Username, false)
+		return
+	}
+
+	tokens, err := h.issueTokens(reg.Username, userType)
 	if err != nil {
 		log.Printf("Generate token error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
 }
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"chalkstone.council/internal/database"
 	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
 	authMock "chalkstone.council/internal/middleware/mocks"
 	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	"os"
 
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -46,13 +49,13 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOperations,
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	return router, mockDB, mockAuth
 }
@@ -72,13 +75,11 @@ func setupUnauthorizedRouter(t *testing.T) *gin.Engine {
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
-		// Pass through the middleware but check userType in the handler
-		c.Next()
-	}).AnyTimes()
+	// Enforce the real permission matrix for the non-staff user
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).DoAndReturn(middleware.RequirePermission).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	return router
 }
@@ -118,6 +119,8 @@ func TestCreateIssue(t *testing.T) {
 	// Close the writer
 	writer.Close()
 
+	mockDB.EXPECT().FindDuplicateCandidates(models.TypePothole, 51.5074, -0.1278, gomock.Any(), gomock.Any()).
+		Return([]*models.DuplicateCandidate{}, nil)
 	mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(1), nil)
 
 	req, _ := http.NewRequest("POST", "/api/issues", body)
@@ -215,6 +218,8 @@ func TestCreateIssueFailsInDatabase(t *testing.T) {
 	writer.Close()
 
 	// Mock database error
+	mockDB.EXPECT().FindDuplicateCandidates(models.TypePothole, 51.5074, -0.1278, gomock.Any(), gomock.Any()).
+		Return([]*models.DuplicateCandidate{}, nil)
 	mockDB.EXPECT().CreateIssue(gomock.Any()).Return(int64(0), fmt.Errorf("database error"))
 
 	req, _ := http.NewRequest("POST", "/api/issues", body)
@@ -248,7 +253,7 @@ func TestGetIssue(t *testing.T) {
 			Longitude float64 `json:"longitude"`
 		}{Latitude: 51.5074, Longitude: -0.1278}),
 		ReportedBy: "test_user",
-		Images:     []string{"image1.jpg"},
+		Images:     models.IssueImages{{Original: "image1.jpg", Medium: "image1.jpg", Thumb: "image1.jpg"}},
 	}
 
 	mockDB.EXPECT().GetIssue(int64(1)).Return(mockIssue, nil)
@@ -270,6 +275,7 @@ func TestGetIssue(t *testing.T) {
 	assert.Equal(t, float64(1), response["id"])
 	assert.Equal(t, "POTHOLE", response["type"])
 	assert.Equal(t, "NEW", response["status"])
+	assert.Equal(t, []interface{}{"TRIAGED", "REJECTED", "DUPLICATE"}, response["allowed_transitions"])
 }
 
 // TestGetIssueNotFound tests the scenario when an issue is not found
@@ -326,11 +332,15 @@ func TestUpdateIssue(t *testing.T) {
 
 	// Mock the GetEngineerByID call that happens during validation
 	mockEngineer := &models.Engineer{
-		ID:   1,
-		Name: "Test Engineer",
+		ID:     1,
+		Name:   "Test Engineer",
+		Active: true,
 	}
 	mockDB.EXPECT().GetEngineerByID(int64(1)).Return(mockEngineer, nil)
 
+	// Mock the GetIssue call used to check the status transition
+	mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned}, nil)
+
 	body, _ := json.Marshal(updatePayload)
 	mockDB.EXPECT().UpdateIssue(int64(1), gomock.Any()).Return(nil)
 
@@ -419,6 +429,7 @@ func TestUpdateIssueDBFailure(t *testing.T) {
 	body, _ := json.Marshal(updatePayload)
 
 	// Mock database failure
+	mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusInProgress}, nil)
 	mockDB.EXPECT().UpdateIssue(int64(1), gomock.Any()).Return(fmt.Errorf("database error"))
 
 	req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBuffer(body))
@@ -465,7 +476,7 @@ func TestListIssues(t *testing.T) {
 		},
 	}
 
-	mockDB.EXPECT().ListIssues(1, 10).Return(mockIssues, nil)
+	mockDB.EXPECT().ListIssues(1, 10, models.SortNewest, false).Return(mockIssues, nil)
 
 	req, _ := http.NewRequest("GET", "/api/issues?page=1&pageSize=10", nil)
 	req.Header.Set("Authorization", "Bearer staff_token")
@@ -532,7 +543,7 @@ func TestGetIssueAnalytics(t *testing.T) {
 		Return(map[string]string{"POTHOLE": "2d 4h", "STREETLIGHT": "1d 6h"}, nil)
 
 	mockDB.EXPECT().
-		GetEngineerPerformance().
+		GetEngineerPerformance(false).
 		Return(engPerf, nil)
 
 	req, _ := http.NewRequest("GET", "/api/issues/analytics", nil)
@@ -746,7 +757,7 @@ func TestGetIssuesForMap(t *testing.T) {
 		},
 	}
 
-	mockDB.EXPECT().GetIssuesForMap().Return(mockMapIssues, nil)
+	mockDB.EXPECT().GetIssuesForMap(models.MapFilter{}).Return(mockMapIssues, nil)
 
 	req, _ := http.NewRequest("GET", "/api/issues/map", nil)
 	req.Header.Set("Authorization", "Bearer valid_token")
@@ -789,13 +800,13 @@ func TestListEngineers(t *testing.T) {
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	mockEngineers := []*models.Engineer{
 		{
@@ -814,7 +825,7 @@ func TestListEngineers(t *testing.T) {
 		},
 	}
 
-	mockDB.EXPECT().ListEngineers().Return(mockEngineers, nil)
+	mockDB.EXPECT().ListEngineers(false).Return(mockEngineers, nil)
 
 	req, _ := http.NewRequest("GET", "/api/engineers", nil)
 	req.Header.Set("Authorization", "Bearer staff_token")
@@ -851,13 +862,13 @@ func TestGetEngineerByID(t *testing.T) {
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	mockEngineer := &models.Engineer{
 		ID:             1,
@@ -904,13 +915,13 @@ func TestEngineerPerformance(t *testing.T) {
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	// Create mock engineer performance data
 	engPerfs := []*models.EngineerPerformance{
@@ -940,7 +951,7 @@ func TestEngineerPerformance(t *testing.T) {
 		},
 	}
 
-	mockDB.EXPECT().GetEngineerPerformance().Return(engPerfs, nil)
+	mockDB.EXPECT().GetEngineerPerformance(false).Return(engPerfs, nil)
 
 	req, _ := http.NewRequest("GET", "/api/analytics/engineers", nil)
 	req.Header.Set("Authorization", "Bearer staff_token")
@@ -978,13 +989,13 @@ func TestResolutionTime(t *testing.T) {
 		c.Next()
 	}).AnyTimes()
 
-	mockAuth.EXPECT().StaffOnly().Return(func(c *gin.Context) {
+	mockAuth.EXPECT().RequirePermission(gomock.Any()).Return(func(c *gin.Context) {
 		c.Set("userType", "staff")
 		c.Next()
 	}).AnyTimes()
 
 	router := gin.Default()
-	SetupRoutes(router, mockDB, mockAuth)
+	SetupRoutes(router, mockDB, storage.NewMemoryStore("", nil), mockAuth)
 
 	// Create mock resolution time data
 	resolutionTimeData := map[string]string{
@@ -1206,7 +1217,7 @@ func (h *TestRegisterHandler) Register(c *gin.Context) {
 	}
 
 	// In a test context, we'll just pass the password directly, not hash it
-	err := h.db.CreateUser(reg.Username, reg.Password, userType)
+	err := h.db.CreateUser(reg.Username, reg.Password, userType, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
@@ -1244,7 +1255,7 @@ func TestRegister(t *testing.T) {
 	}
 
 	// Mock the database call to create a user - with a direct password for testing
-	mockDB.EXPECT().CreateUser("newuser", "newpassword", "public").Return(nil)
+	mockDB.EXPECT().CreateUser("newuser", "newpassword", "public", gomock.Any()).Return(nil)
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1349,7 +1360,7 @@ func TestRegisterFail(t *testing.T) {
 	}
 
 	// Mock database error when creating user
-	mockDB.EXPECT().CreateUser("existinguser", "password", "public").Return(fmt.Errorf("user already exists"))
+	mockDB.EXPECT().CreateUser("existinguser", "password", "public", gomock.Any()).Return(fmt.Errorf("user already exists"))
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1382,7 +1393,7 @@ func TestRegisterStaff(t *testing.T) {
 	}
 
 	// Mock the database call for staff user creation
-	mockDB.EXPECT().CreateUser("staffuser", "staffpass", "staff").Return(nil)
+	mockDB.EXPECT().CreateUser("staffuser", "staffpass", "staff", gomock.Any()).Return(nil)
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1429,8 +1440,10 @@ func TestLoginUserNotFoundWithActualHandler(t *testing.T) {
 		"password": "password",
 	}
 
-	// Mock database error when user not found
-	mockDB.EXPECT().GetUserByUsername("nonexistentuser").Return(nil, fmt.Errorf("user not found"))
+	// Unknown usernames count as failed logins too
+	mockDB.EXPECT().GetLoginAttempts("nonexistentuser").Return(nil, nil)
+	mockDB.EXPECT().GetUserByUsername("nonexistentuser").Return(nil, sql.ErrNoRows)
+	mockDB.EXPECT().RecordFailedLogin("nonexistentuser", gomock.Any()).Return(&models.LoginAttempts{Username: "nonexistentuser", FailedCount: 1}, false, nil)
 
 	// Create request
 	body, _ := json.Marshal(loginPayload)
@@ -1465,12 +1478,12 @@ func TestRegisterDatabaseError(t *testing.T) {
 	// Prepare mock data
 	registerPayload := map[string]interface{}{
 		"username":  "existinguser",
-		"password":  "password",
+		"password":  "Resident-pass-1",
 		"is_staff": false,
 	}
 
 	// Mock database error when creating user
-	mockDB.EXPECT().CreateUser(gomock.Any(), gomock.Any(), "public").Return(fmt.Errorf("user already exists"))
+	mockDB.EXPECT().CreateUser(gomock.Any(), gomock.Any(), models.RoleResident, gomock.Any()).Return(fmt.Errorf("user already exists"))
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1483,20 +1496,22 @@ func TestRegisterDatabaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
-// TestRegisterWithStaffUser tests staff user registration
+// TestRegisterWithStaffUser tests staff user registration by redeeming an invite
 func TestRegisterWithStaffUser(t *testing.T) {
 	router, mockDB, _ := setupTestRouter(t)
 
-	// Prepare mock data for staff registration with the required secret
+	// Prepare mock data for staff registration with an invite
 	registerPayload := map[string]interface{}{
-		"username":     "staffuser",
-		"password":     "staffpass",
-		"is_staff":     true,
-		"staff_secret": "citycouncilstaff2023",
+		"username":    "staffuser",
+		"password":    "Staff-pass-2025",
+		"email":       "staff@chalkstone.gov.uk",
+		"invite_code": "invite-code",
 	}
 
-	// Mock the database call for staff user creation (with any password hash)
-	mockDB.EXPECT().CreateUser("staffuser", gomock.Any(), "staff").Return(nil)
+	// The invite is redeemed by its hash and creates the user with the invited role
+	mockDB.EXPECT().
+		RedeemStaffInvite(middleware.HashToken("invite-code"), "staff@chalkstone.gov.uk", "staffuser", gomock.Any()).
+		Return("staff", nil)
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1506,21 +1521,28 @@ func TestRegisterWithStaffUser(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
+	// Staff have to enrol in two-factor authentication before they get tokens
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "challenge_token")
+	assert.NotContains(t, w.Body.String(), "refresh_token")
 }
 
-// TestRegisterWithInvalidStaffSecret tests that staff registration fails when an invalid secret is provided
-func TestRegisterWithInvalidStaffSecret(t *testing.T) {
-	router, _, _ := setupTestRouter(t)
+// TestRegisterWithInvalidInvite tests that staff registration fails when the invite can't be redeemed
+func TestRegisterWithInvalidInvite(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
 
-	// Prepare mock data for staff registration with invalid secret
+	// Prepare mock data for staff registration with an unknown invite
 	registerPayload := map[string]interface{}{
-		"username":     "staffuser",
-		"password":     "staffpass",
-		"is_staff":     true,
-		"staff_secret": "wrongsecret", // Invalid secret
+		"username":    "staffuser",
+		"password":    "Staff-pass-2025",
+		"email":       "staff@chalkstone.gov.uk",
+		"invite_code": "wrong-code",
 	}
 
+	mockDB.EXPECT().
+		RedeemStaffInvite(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return("", database.ErrInviteInvalid)
+
 	// Create request
 	body, _ := json.Marshal(registerPayload)
 	req, _ := http.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(body))
@@ -1529,35 +1551,35 @@ func TestRegisterWithInvalidStaffSecret(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	// Should fail with bad request due to invalid secret
+	// Should fail with bad request due to the invalid invite
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
 	// Check response contains error message
 	var response map[string]string
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(t, response["error"], "Invalid staff secret")
+	assert.Contains(t, response["error"], "Invalid or expired invite code")
 }
 
-// TestRegisterStaffWithEnvVar tests staff registration with a secret provided via environment variable
-func TestRegisterStaffWithEnvVar(t *testing.T) {
+// TestRegisterIgnoresStaffSecret tests that the retired shared staff secret no longer grants staff access
+func TestRegisterIgnoresStaffSecret(t *testing.T) {
 	router, mockDB, _ := setupTestRouter(t)
 
-	// Set environment variable for staff secret
+	// A secret that used to be accepted through the environment
 	originalValue := os.Getenv("STAFF_SECRET")
 	os.Setenv("STAFF_SECRET", "custom_env_secret")
 	// Restore the original value when the test finishes
 	defer os.Setenv("STAFF_SECRET", originalValue)
 
-	// Prepare mock data for staff registration with the secret from env var
 	registerPayload := map[string]interface{}{
 		"username":     "staffuser",
-		"password":     "staffpass",
+		"password":     "Staff-pass-2025",
 		"is_staff":     true,
-		"staff_secret": "custom_env_secret", // Matches the environment variable
+		"staff_secret": "custom_env_secret",
 	}
 
-	// Mock the database call for staff user creation (with any password hash)
-	mockDB.EXPECT().CreateUser("staffuser", gomock.Any(), "staff").Return(nil)
+	// The account is created as a public user
+	mockDB.EXPECT().CreateUser("staffuser", gomock.Any(), models.RoleResident, gomock.Any()).Return(nil)
+	mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
 
 	// Create request
 	body, _ := json.Marshal(registerPayload)
@@ -1567,7 +1589,6 @@ func TestRegisterStaffWithEnvVar(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	// Should succeed
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// Check response contains token
@@ -1575,3 +1596,23 @@ func TestRegisterStaffWithEnvVar(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Contains(t, response, "token")
 }
+
+// TestRegisterInviteRequiresEmail tests that an invite can't be redeemed without the invited email
+func TestRegisterInviteRequiresEmail(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+
+	registerPayload := map[string]interface{}{
+		"username":    "staffuser",
+		"password":    "Staff-pass-2025",
+		"invite_code": "invite-code",
+	}
+
+	body, _ := json.Marshal(registerPayload)
+	req, _ := http.NewRequest("POST", "/api/auth/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultImageURLExpiry = 15 * time.Minute
+
+// LoadImageURLExpiry reads IMAGE_URL_EXPIRY_MINUTES, how long the image URLs handed
+// out with issues and comments work for
+func LoadImageURLExpiry() time.Duration {
+	if minutes, err := strconv.Atoi(os.Getenv("IMAGE_URL_EXPIRY_MINUTES")); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultImageURLExpiry
+}
+
+// uploadImage stores an uploaded image with its renditions and returns their object
+// keys, along with when the photo was taken. Keys are turned into URLs when served.
+func (h *Handler) uploadImage(c *gin.Context, file multipart.File, fileName string) (models.IssueImage, error) {
+	return storage.UploadImage(c.Request.Context(), h.images, file, fileName)
+}
+
+// uploadImageFile stores an image from a multipart upload like uploadImage, closing the
+// upload once it has been stored
+func (h *Handler) uploadImageFile(c *gin.Context, fileHeader *multipart.FileHeader) (models.IssueImage, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return models.IssueImage{}, err
+	}
+	defer file.Close()
+
+	return h.uploadImage(c, file, fileHeader.Filename)
+}
+
+// presignKey returns a short-lived URL for the stored image
+func (h *Handler) presignKey(ctx context.Context, key string) (string, error) {
+	expiry := h.imageURLExpiry
+	if expiry <= 0 {
+		expiry = defaultImageURLExpiry
+	}
+	return h.images.PresignGet(ctx, key, expiry)
+}
+
+func (h *Handler) presignKeys(ctx context.Context, keys []string) ([]string, error) {
+	urls := make([]string, len(keys))
+	for i, key := range keys {
+		url, err := h.presignKey(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		urls[i] = url
+	}
+	return urls, nil
+}
+
+// presignImages returns a copy of the photos with URLs to show them in place of their
+// object keys
+func (h *Handler) presignImages(ctx context.Context, images models.IssueImages) (models.IssueImages, error) {
+	presigned := make(models.IssueImages, len(images))
+	for i, image := range images {
+		renditions, err := h.presignKeys(ctx, []string{image.Original, image.Medium, image.Thumb})
+		if err != nil {
+			return nil, err
+		}
+		image.Original, image.Medium, image.Thumb = renditions[0], renditions[1], renditions[2]
+		presigned[i] = image
+	}
+	return presigned, nil
+}
+
+// presignIssue replaces the object keys of an issue's photos with URLs to show them
+func (h *Handler) presignIssue(ctx context.Context, issue *models.Issue) error {
+	images, err := h.presignImages(ctx, issue.Images)
+	if err != nil {
+		return err
+	}
+	afterImages, err := h.presignKeys(ctx, issue.AfterImages)
+	if err != nil {
+		return err
+	}
+	issue.Images = images
+	issue.AfterImages = afterImages
+	return nil
+}
+
+func (h *Handler) presignIssues(ctx context.Context, issues []*models.Issue) error {
+	for _, issue := range issues {
+		if err := h.presignIssue(ctx, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// @Summary Get image
+// @Description Serve an uploaded image from the object store through a presigned URL handed out with an issue or comment. Used when images are kept on the local filesystem or in memory; MinIO serves its presigned URLs itself. Links without a valid, unexpired signature are refused.
+// @Tags images
+// @Produce image/jpeg,image/png,image/gif,image/webp
+// @Param key path string true "Image key"
+// @Param expires query int true "Expiry of the link as a Unix time"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {file} binary
+// @Failure 403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /images/{key} [get]
+func (h *Handler) GetImage(c *gin.Context) {
+	verifier, ok := h.images.(storage.PresignVerifier)
+	if !ok || !verifier.VerifyPresigned(c.Param("key"), c.Request.URL.Query()) {
+		utils.RespondWithError(c, http.StatusForbidden, "Image link is invalid or has expired", nil)
+		return
+	}
+
+	body, info, err := h.images.Get(c.Request.Context(), c.Param("key"))
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.RespondWithError(c, http.StatusNotFound, "Image not found", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to load image", err)
+		return
+	}
+	defer body.Close()
+
+	// Keys are random and never reused, so images never change, but they may only be
+	// cached by the browser the link was handed to and only for as long as the link works
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+	maxAge := max(expires-time.Now().Unix(), 0)
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, map[string]string{
+		"Cache-Control":          fmt.Sprintf("private, max-age=%d, immutable", maxAge),
+		"X-Content-Type-Options": "nosniff",
+		"Content-Disposition":    "inline",
+		"ETag":                   strconv.Quote(info.Key),
+	})
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// testJPEG returns a w×h JPEG photo
+func testJPEG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil))
+	return buf.Bytes()
+}
+
+func TestCreateIssueStoresImages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	store := storage.NewMemoryStore("http://localhost:8080/api/images", nil)
+	handler := &Handler{db: mockDB, images: store, imageURLExpiry: time.Minute}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "test_user")
+		c.Next()
+	})
+	router.POST("/api/issues", handler.CreateIssue)
+	router.GET("/api/issues/:id", handler.GetIssue)
+	router.GET("/api/images/:key", handler.GetImage)
+
+	var images models.IssueImages
+	mockDB.EXPECT().CreateIssue(gomock.Any()).DoAndReturn(func(issue *models.IssueCreate) (int64, error) {
+		images = issue.Images
+		return int64(1), nil
+	})
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("type", "POTHOLE")
+	_ = writer.WriteField("description", "Test pothole description")
+	_ = writer.WriteField("latitude", "51.5074")
+	_ = writer.WriteField("longitude", "-0.1278")
+	fileWriter, _ := writer.CreateFormFile("images", "pothole.jpg")
+	_, _ = fileWriter.Write(testJPEG(t, 1600, 1200))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/issues", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Only the keys of the photo and its renditions are stored with the issue
+	assert.Len(t, images, 1)
+	assert.ElementsMatch(t, []string{images[0].Original, images[0].Medium, images[0].Thumb}, store.Keys())
+
+	// Reading the issue hands out presigned URLs they are served from
+	mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Images: images}, nil)
+	req, _ = http.NewRequest("GET", "/api/issues/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var issue models.Issue
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &issue))
+
+	for link, width := range map[string]int{issue.Images[0].Original: 1600, issue.Images[0].Medium: 1280, issue.Images[0].Thumb: 320} {
+		assert.True(t, strings.HasPrefix(link, "http://localhost:8080/api/images/"))
+		assert.Contains(t, link, "signature=")
+
+		req, _ = http.NewRequest("GET", strings.TrimPrefix(link, "http://localhost:8080"), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Cache-Control"), "private")
+		cfg, err := jpeg.DecodeConfig(w.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, width, cfg.Width)
+	}
+}
+
+func TestCreateIssueRejectsNonImages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := storage.NewMemoryStore("", nil)
+	handler := &Handler{images: store}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "test_user")
+		c.Next()
+	})
+	router.POST("/api/issues", handler.CreateIssue)
+
+	body, contentType := createTestMultipartForm(t, true)
+	req, _ := http.NewRequest("POST", "/api/issues", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, store.Keys())
+}
+
+func TestGetImageRequiresValidLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := storage.NewMemoryStore("", nil)
+	assert.NoError(t, store.Put(context.Background(), "known.png", bytes.NewReader([]byte("png")), 3, "image/png"))
+	handler := &Handler{images: store}
+
+	router := gin.New()
+	router.GET("/api/images/:key", handler.GetImage)
+
+	valid, _ := store.PresignGet(context.Background(), "known.png", time.Minute)
+	expired, _ := store.PresignGet(context.Background(), "known.png", -time.Minute)
+	forOther, _ := store.PresignGet(context.Background(), "other.png", time.Minute)
+	_, otherQuery, _ := strings.Cut(forOther, "?")
+
+	tests := map[string]struct {
+		path string
+		code int
+	}{
+		"presigned":          {valid, http.StatusOK},
+		"unsigned":           {"/known.png", http.StatusForbidden},
+		"expired":            {expired, http.StatusForbidden},
+		"signed for another": {"/known.png?" + otherQuery, http.StatusForbidden},
+		"tampered signature": {valid[:len(valid)-4] + "0000", http.StatusForbidden},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/images"+tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestGetImageCachedUntilLinkExpires(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := storage.NewMemoryStore("", nil)
+	assert.NoError(t, store.Put(context.Background(), "known.png", bytes.NewReader([]byte("png")), 3, "image/png"))
+	handler := &Handler{images: store}
+
+	router := gin.New()
+	router.GET("/api/images/:key", handler.GetImage)
+
+	presigned, _ := store.PresignGet(context.Background(), "known.png", 10*time.Minute)
+	req, _ := http.NewRequest("GET", "/api/images"+presigned, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var maxAge int
+	_, err := fmt.Sscanf(w.Header().Get("Cache-Control"), "private, max-age=%d, immutable", &maxAge)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, maxAge, 600)
+	assert.Greater(t, maxAge, 590)
+}
+
+func TestGetImageNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := storage.NewMemoryStore("", nil)
+	handler := &Handler{images: store}
+
+	router := gin.New()
+	router.GET("/api/images/:key", handler.GetImage)
+
+	presigned, _ := store.PresignGet(context.Background(), "unknown.png", time.Minute)
+	req, _ := http.NewRequest("GET", "/api/images"+presigned, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPresignIssue(t *testing.T) {
+	store := storage.NewMemoryStore("http://localhost:8080/api/images", nil)
+	handler := &Handler{images: store}
+	captured := time.Date(2024, 6, 1, 13, 30, 5, 0, time.UTC)
+	issue := &models.Issue{
+		Images:      models.IssueImages{{Original: "a.jpg", Medium: "a-medium.jpg", Thumb: "a-thumb.jpg", CapturedAt: &captured}},
+		AfterImages: []string{"b.jpg"},
+	}
+
+	assert.NoError(t, handler.presignIssue(context.Background(), issue))
+	assert.True(t, strings.HasPrefix(issue.Images[0].Original, "http://localhost:8080/api/images/a.jpg?"))
+	assert.True(t, strings.HasPrefix(issue.Images[0].Medium, "http://localhost:8080/api/images/a-medium.jpg?"))
+	assert.True(t, strings.HasPrefix(issue.Images[0].Thumb, "http://localhost:8080/api/images/a-thumb.jpg?"))
+	assert.Equal(t, &captured, issue.Images[0].CapturedAt)
+	assert.True(t, strings.HasPrefix(issue.AfterImages[0], "http://localhost:8080/api/images/b.jpg?"))
+
+	// URLs expire after the configured time
+	u, _ := url.Parse(issue.Images[0].Original)
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	assert.InDelta(t, time.Now().Add(defaultImageURLExpiry).Unix(), expires, 5)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultInviteExpiry = 72 * time.Hour
+
+// newOneTimeCode returns a random single-use code, such as an invite code or password
+// reset token, and the hash to store in its place
+func newOneTimeCode() (code, hash string, err error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	code = base64.RawURLEncoding.EncodeToString(b)
+	return code, middleware.HashToken(code), nil
+}
+
+// @Summary Create staff invite
+// @Description Create a single-use, expiring invite code for the given email and role. Engineer invites must name the engineer the account belongs to. The code is only shown in this response.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param invite body models.StaffInviteCreate true "Invite details"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400,403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /invites [post]
+func (h *Handler) CreateStaffInvite(c *gin.Context) {
+	var req models.StaffInviteCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !models.ValidateInviteRole(req.Role) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid role", nil)
+		return
+	}
+	if !h.checkEngineerLink(c, req.Role, req.EngineerID) {
+		return
+	}
+
+	expiry := defaultInviteExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	code, codeHash, err := newOneTimeCode()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create invite", err)
+		return
+	}
+
+	createdBy, _ := c.Get("userID")
+	invite := &models.StaffInvite{
+		CodeHash:   codeHash,
+		Email:      strings.TrimSpace(req.Email),
+		Role:       req.Role,
+		EngineerID: req.EngineerID,
+		ExpiresAt:  time.Now().Add(expiry),
+	}
+	invite.CreatedBy, _ = createdBy.(string)
+
+	if err := h.db.CreateStaffInvite(invite); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to create invite", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"invite": invite, "code": code})
+}
+
+// @Summary List staff invites
+// @Description List all staff invites with their redemption status
+// @Tags invites
+// @Produce json
+// @Success 200 {array} models.StaffInvite
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /invites [get]
+func (h *Handler) ListStaffInvites(c *gin.Context) {
+	invites, err := h.db.ListStaffInvites()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list invites", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+// @Summary Withdraw staff invite
+// @Description Delete an invite that hasn't been redeemed yet
+// @Tags invites
+// @Produce json
+// @Param id path int true "Invite ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /invites/{id} [delete]
+func (h *Handler) DeleteStaffInvite(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid invite ID", err)
+		return
+	}
+
+	err = h.db.DeleteStaffInvite(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Invite not found or already redeemed", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete invite", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite withdrawn successfully"})
+}
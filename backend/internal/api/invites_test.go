@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateStaffInvite(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	send := func(payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/invites", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		var stored *models.StaffInvite
+		mockDB.EXPECT().CreateStaffInvite(gomock.Any()).DoAndReturn(func(invite *models.StaffInvite) error {
+			stored = invite
+			invite.ID = 4
+			return nil
+		})
+
+		w := send(`{"email": "new.starter@chalkstone.gov.uk", "role": "dispatcher", "expires_in_hours": 24}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response struct {
+			Invite models.StaffInvite `json:"invite"`
+			Code   string             `json:"code"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(4), response.Invite.ID)
+		assert.NotEmpty(t, response.Code)
+
+		// Only the hash of the code is stored
+		assert.Equal(t, middleware.HashToken(response.Code), stored.CodeHash)
+		assert.NotContains(t, w.Body.String(), stored.CodeHash)
+		assert.Equal(t, "test_user", stored.CreatedBy)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("Default expiry", func(t *testing.T) {
+		mockDB.EXPECT().CreateStaffInvite(gomock.Any()).DoAndReturn(func(invite *models.StaffInvite) error {
+			assert.WithinDuration(t, time.Now().Add(defaultInviteExpiry), invite.ExpiresAt, time.Minute)
+			return nil
+		})
+
+		w := send(`{"email": "new.starter@chalkstone.gov.uk", "role": "admin"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		w := send(`{"email": "new.starter@chalkstone.gov.uk", "role": "superuser"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid email", func(t *testing.T) {
+		w := send(`{"email": "not-an-email", "role": "admin"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Engineer invite", func(t *testing.T) {
+		mockDB.EXPECT().GetEngineerByID(int64(3)).Return(&models.Engineer{ID: 3, Active: true}, nil)
+		mockDB.EXPECT().CreateStaffInvite(gomock.Any()).DoAndReturn(func(invite *models.StaffInvite) error {
+			assert.Equal(t, models.RoleEngineer, invite.Role)
+			assert.Equal(t, int64(3), *invite.EngineerID)
+			return nil
+		})
+
+		w := send(`{"email": "field@chalkstone.gov.uk", "role": "engineer", "engineer_id": 3}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Engineer invite without engineer", func(t *testing.T) {
+		w := send(`{"email": "field@chalkstone.gov.uk", "role": "engineer"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Engineer link on other role", func(t *testing.T) {
+		w := send(`{"email": "new.starter@chalkstone.gov.uk", "role": "dispatcher", "engineer_id": 3}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Retired engineer", func(t *testing.T) {
+		mockDB.EXPECT().GetEngineerByID(int64(4)).Return(&models.Engineer{ID: 4, Active: false}, nil)
+
+		w := send(`{"email": "field@chalkstone.gov.uk", "role": "engineer", "engineer_id": 4}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().CreateStaffInvite(gomock.Any()).Return(errors.New("db error"))
+
+		w := send(`{"email": "new.starter@chalkstone.gov.uk", "role": "admin"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestListAndDeleteStaffInvites(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().ListStaffInvites().Return([]*models.StaffInvite{
+		{ID: 1, Email: "a@chalkstone.gov.uk", Role: "admin", CodeHash: "secret-hash"},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/invites", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "a@chalkstone.gov.uk")
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+
+	mockDB.EXPECT().DeleteStaffInvite(int64(1)).Return(nil)
+	req, _ = http.NewRequest("DELETE", "/api/invites/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockDB.EXPECT().DeleteStaffInvite(int64(2)).Return(sql.ErrNoRows)
+	req, _ = http.NewRequest("DELETE", "/api/invites/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStaffInvitesNonStaff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := &Handler{db: dbMock.NewMockDatabaseOperations(ctrl)}
+	router := gin.New()
+	setPublic := func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", "public")
+		c.Next()
+	}
+	requireAdmin := middleware.RequirePermission(models.PermUsersManage)
+	router.POST("/api/invites", setPublic, requireAdmin, handler.CreateStaffInvite)
+	router.GET("/api/invites", setPublic, requireAdmin, handler.ListStaffInvites)
+	router.DELETE("/api/invites/:id", setPublic, requireAdmin, handler.DeleteStaffInvite)
+
+	for _, r := range []struct{ method, path string }{
+		{"POST", "/api/invites"},
+		{"GET", "/api/invites"},
+		{"DELETE", "/api/invites/1"},
+	} {
+		req, _ := http.NewRequest(r.method, r.path, bytes.NewBufferString(`{"email": "me@example.com", "role": "admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, r.method+" "+r.path)
+	}
+}
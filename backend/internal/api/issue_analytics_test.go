@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
 	"chalkstone.council/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -64,7 +65,7 @@ func TestGetIssueAnalyticsWithDateRange(t *testing.T) {
 		Return(mockResolutionTimes, nil)
 
 	mockDB.EXPECT().
-		GetEngineerPerformance().
+		GetEngineerPerformance(false).
 		Return(engPerf, nil)
 
 	// Make request with date parameters
@@ -111,7 +112,7 @@ func TestGetIssueAnalyticsNonStaffAccess(t *testing.T) {
 		c.Set("userType", "citizen") // Not "staff"
 		c.Next()
 	})
-	api.GET("/issues/analytics", handler.GetIssueAnalytics)
+	api.GET("/issues/analytics", middleware.RequirePermission(models.PermAnalyticsRead), handler.GetIssueAnalytics)
 	
 	// No expectations on mockDB since the request should fail due to authorization
 	
@@ -125,7 +126,7 @@ func TestGetIssueAnalyticsNonStaffAccess(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Staff access required", response["error"])
+	assert.Equal(t, "Insufficient permissions", response["error"])
 }
 
 func TestGetIssueAnalyticsAnalyticsError(t *testing.T) {
@@ -188,7 +189,7 @@ func TestGetIssueAnalyticsEngineerPerformanceError(t *testing.T) {
 	}, nil)
 
 	// Third call fails
-	mockDB.EXPECT().GetEngineerPerformance().Return(nil, errors.New("database error"))
+	mockDB.EXPECT().GetEngineerPerformance(false).Return(nil, errors.New("database error"))
 
 	req, _ := http.NewRequest("GET", "/api/issues/analytics", nil)
 	req.Header.Set("Authorization", "Bearer staff_token")
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetIssueHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	router := gin.Default()
+
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
+
+	api := router.Group("/api")
+
+	addStaffAuth := func(c *gin.Context) {
+		c.Set("userID", "test_staff")
+		c.Set("userType", "staff")
+		c.Next()
+	}
+
+	api.GET("/issues/:id/history", addStaffAuth, handler.GetIssueHistory)
+	api.GET("/no-auth/issues/:id/history", middleware.RequirePermission(models.PermIssuesRead), handler.GetIssueHistory)
+
+	t.Run("Success", func(t *testing.T) {
+		newStatus := "NEW"
+		triaged := "TRIAGED"
+		events := []*models.IssueEvent{
+			{ID: 1, IssueID: 1, EventType: models.EventCreated, NewValue: &newStatus, Actor: "resident", CreatedAt: time.Now().Add(-time.Hour)},
+			{ID: 2, IssueID: 1, EventType: models.EventStatusChanged, OldValue: &newStatus, NewValue: &triaged, Actor: "test_staff", CreatedAt: time.Now()},
+		}
+
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().GetIssueHistory(int64(1)).Return(events, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []models.IssueEvent
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response, 2)
+		assert.Equal(t, models.EventStatusChanged, response[1].EventType)
+		assert.Equal(t, "TRIAGED", *response[1].NewValue)
+		assert.Equal(t, "test_staff", response[1].Actor)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/issues/abc/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Issue Not Found", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/99/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1}, nil)
+		mockDB.EXPECT().GetIssueHistory(int64(1)).Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("GET", "/api/issues/1/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "Failed to retrieve issue history", response["error"])
+	})
+
+	t.Run("Unauthorized Access", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/no-auth/issues/1/history", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestTimeInStatusHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	router := gin.Default()
+
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
+
+	addSupervisorAuth := func(c *gin.Context) {
+		c.Set("userType", models.RoleSupervisor)
+		c.Next()
+	}
+
+	router.GET("/api/analytics/time-in-status", addSupervisorAuth, middleware.RequirePermission(models.PermAnalyticsRead), handler.TimeInStatus)
+	router.GET("/api/analytics/time-in-status-no-auth", middleware.RequirePermission(models.PermAnalyticsRead), handler.TimeInStatus)
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().GetAverageTimeInStatus().Return(map[string]string{
+			"NEW":         "1d 2h",
+			"IN_PROGRESS": "3d 0h",
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/analytics/time-in-status", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "1d 2h", response["NEW"])
+		assert.Equal(t, "3d 0h", response["IN_PROGRESS"])
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDB.EXPECT().GetAverageTimeInStatus().Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("GET", "/api/analytics/time-in-status", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Unauthorized Access", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/analytics/time-in-status-no-auth", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
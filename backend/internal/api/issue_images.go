@@ -0,0 +1,301 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/imaging"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIssueImages limits how many photos an issue can have
+const maxIssueImages = 10
+
+// defaultImageEditWindow gives reporters a day to add a photo they forgot or remove
+// one they regret
+const defaultImageEditWindow = 24 * time.Hour
+
+// LoadImageEditWindow reads IMAGE_EDIT_WINDOW_HOURS, how long after reporting an issue
+// its reporter may add, remove and reorder its photos. Zero leaves photo changes to
+// staff; unset or invalid values give the default.
+func LoadImageEditWindow() time.Duration {
+	if hours, err := strconv.Atoi(os.Getenv("IMAGE_EDIT_WINDOW_HOURS")); err == nil && hours >= 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return defaultImageEditWindow
+}
+
+// getIssueForImages loads the issue named in the URL and checks the current user may
+// change its photos: staff who may update the issue, or its reporter within the edit
+// window. Returns the issue and whether the user is staff. It writes the error
+// response and returns nil if the photos can't be changed.
+func (h *Handler) getIssueForImages(c *gin.Context) (*models.Issue, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return nil, false
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return nil, false
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return nil, false
+	}
+
+	if middleware.HasPermission(c, models.PermIssuesUpdate) {
+		return issue, true
+	}
+
+	// Engineers may change the photos of the issues assigned to them
+	if middleware.HasPermission(c, models.PermIssuesUpdateAssigned) {
+		engineerID, err := h.currentEngineerID(c)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get engineer", err)
+			return nil, false
+		}
+		if engineerID != nil && issue.AssignedTo != nil && *issue.AssignedTo == *engineerID {
+			return issue, true
+		}
+	}
+
+	reporter, err := h.isReporter(issue, c.GetString("userID"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return nil, false
+	}
+	if !reporter {
+		utils.RespondWithError(c, http.StatusForbidden, "Only the reporter or staff can change the photos of this issue", nil)
+		return nil, false
+	}
+	if time.Since(issue.CreatedAt) >= h.imageEditWindow {
+		utils.RespondWithError(c, http.StatusForbidden, "The photos of this issue can no longer be changed by its reporter", nil)
+		return nil, false
+	}
+	return issue, false
+}
+
+// deleteImageObjects removes the stored renditions of photos that are no longer
+// referenced. Failures only leave unreachable objects behind, so they are logged.
+func (h *Handler) deleteImageObjects(ctx context.Context, images models.IssueImages) {
+	for _, image := range images {
+		h.deleteObjects(ctx, image.Keys())
+	}
+}
+
+// deleteObjects removes stored objects, logging the ones that couldn't be deleted
+func (h *Handler) deleteObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := h.images.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete image '%s': %v", key, err)
+		}
+	}
+}
+
+// @Summary Add photos to an issue
+// @Description Add photos to an issue, after its existing ones. Staff who may update the issue can add photos at any time; its reporter only within IMAGE_EDIT_WINDOW_HOURS of reporting it. An issue can have at most 10 photos. Photos taken further than PHOTO_LOCATION_TOLERANCE_METERS from the issue flag it for location review.
+// @Tags images
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param images formData file true "Images to add (multiple allowed)"
+// @Success 201 {array} models.IssueImage
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/images [post]
+func (h *Handler) AddIssueImages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	// Parse multipart form (handle file uploads)
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB limit
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid form data", err)
+		return
+	}
+	files := c.Request.MultipartForm.File["images"]
+	if len(files) == 0 {
+		utils.RespondWithError(c, http.StatusBadRequest, "At least one image is required", nil)
+		return
+	}
+
+	issue, _ := h.getIssueForImages(c)
+	if issue == nil {
+		return
+	}
+
+	tooMany := fmt.Sprintf("An issue can have at most %d images", maxIssueImages)
+	if len(issue.Images)+len(files) > maxIssueImages {
+		utils.RespondWithError(c, http.StatusBadRequest, tooMany, nil)
+		return
+	}
+
+	// Check where the photos were taken before their metadata is stripped on upload,
+	// numbering them after the issue's existing photos
+	locations, err := photoLocations(files)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to open image file", err)
+		return
+	}
+	locations = append(make([]*imaging.Location, len(issue.Images)), locations...)
+	review := h.locationReview(issue.Location.Latitude, issue.Location.Longitude, locations)
+
+	images := models.IssueImages{}
+	for _, fileHeader := range files {
+		image, err := h.uploadImageFile(c, fileHeader)
+		if err != nil {
+			h.deleteImageObjects(c.Request.Context(), images)
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to upload image", err)
+			return
+		}
+
+		images = append(images, image)
+	}
+
+	added, err := h.db.AddIssueImages(issue.ID, images, userID.(string), maxIssueImages, review)
+	if err != nil {
+		// Nothing refers to the uploads unless they were added
+		h.deleteImageObjects(c.Request.Context(), images)
+
+		switch {
+		case errors.Is(err, database.ErrTooManyImages):
+			utils.RespondWithError(c, http.StatusBadRequest, tooMany, err)
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		default:
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to add images", err)
+		}
+		return
+	}
+
+	if review != nil {
+		log.Printf("Issue %d flagged for location review: %s", issue.ID, *review)
+	}
+
+	presigned, err := h.presignImages(c.Request.Context(), added)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to add images", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, presigned)
+}
+
+// @Summary Remove a photo from an issue
+// @Description Remove a photo from an issue and delete it from storage. Staff who may update the issue can remove any photo; its reporter only the photos they added, within IMAGE_EDIT_WINDOW_HOURS of reporting the issue.
+// @Tags images
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param imageId path int true "Image ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/images/{imageId} [delete]
+func (h *Handler) DeleteIssueImage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	imageID, err := strconv.ParseInt(c.Param("imageId"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid image ID", err)
+		return
+	}
+
+	issue, staff := h.getIssueForImages(c)
+	if issue == nil {
+		return
+	}
+
+	var image *models.IssueImage
+	for i := range issue.Images {
+		if issue.Images[i].ID == imageID {
+			image = &issue.Images[i]
+		}
+	}
+	if image == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Image not found", nil)
+		return
+	}
+
+	if !staff && image.UploadedBy != userID {
+		utils.RespondWithError(c, http.StatusForbidden, "Only staff can remove photos added by someone else", nil)
+		return
+	}
+
+	removed, err := h.db.DeleteIssueImage(issue.ID, imageID, userID.(string))
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Image not found", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to delete image", err)
+		return
+	}
+
+	h.deleteImageObjects(c.Request.Context(), models.IssueImages{*removed})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
+
+// @Summary Reorder the photos of an issue
+// @Description Set the order an issue's photos are shown in by listing every photo ID once. The same users who may add photos may reorder them.
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param order body models.ImageOrder true "Photo IDs in their new order"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/images [put]
+func (h *Handler) ReorderIssueImages(c *gin.Context) {
+	var order models.ImageOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	issue, _ := h.getIssueForImages(c)
+	if issue == nil {
+		return
+	}
+
+	err := h.db.ReorderIssueImages(issue.ID, order.Order)
+	if errors.Is(err, database.ErrImageOrderMismatch) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Order must list every image of the issue once", err)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to reorder images", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Images reordered successfully"})
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// setupIssueImageTestRouter registers the issue image routes with a fixed user identity
+func setupIssueImageTestRouter(t *testing.T, userID, userType string) (*gin.Engine, *dbMock.MockDatabaseOperations, *storage.MemoryStore) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	store := storage.NewMemoryStore("http://localhost:8080/api/images", nil)
+	handler := &Handler{db: mockDB, images: store, imageEditWindow: 24 * time.Hour, photoLocationTolerance: 250}
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("userType", userType)
+		c.Next()
+	})
+	api.POST("/issues/:id/images", handler.AddIssueImages)
+	api.PUT("/issues/:id/images", handler.ReorderIssueImages)
+	api.DELETE("/issues/:id/images/:imageId", handler.DeleteIssueImage)
+
+	return router, mockDB, store
+}
+
+func postIssueImage(t *testing.T, router *gin.Engine) *httptest.ResponseRecorder {
+	return postIssuePhoto(router, testJPEG(t, 64, 48))
+}
+
+func postIssuePhoto(router *gin.Engine, photo []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	fileWriter, _ := writer.CreateFormFile("images", "pothole.jpg")
+	_, _ = fileWriter.Write(photo)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/issues/1/images", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func reportedIssue(reportedBy string, age time.Duration, images ...models.IssueImage) *models.Issue {
+	return &models.Issue{ID: 1, ReportedBy: reportedBy, CreatedAt: time.Now().Add(-age), Images: images}
+}
+
+func TestAddIssueImages(t *testing.T) {
+	t.Run("Reporter Within Window", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().AddIssueImages(int64(1), gomock.Any(), "resident", maxIssueImages, nil).
+			DoAndReturn(func(id int64, images models.IssueImages, uploadedBy string, max int, review *string) (models.IssueImages, error) {
+				assert.Len(t, images, 1)
+				assert.Equal(t, "image/jpeg", images[0].ContentType)
+				assert.NotEmpty(t, images[0].Checksum)
+				images[0].ID = 7
+				return images, nil
+			})
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":7`)
+		// Keys are handed out as presigned URLs
+		assert.Len(t, store.Keys(), 1)
+		assert.Contains(t, w.Body.String(), "/api/images/"+store.Keys()[0]+"?")
+	})
+
+	t.Run("Photo Taken Elsewhere", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		issue := reportedIssue("resident", time.Hour, models.IssueImage{ID: 6})
+		issue.Location.Latitude, issue.Location.Longitude = 50.7284, -3.5339
+		mockDB.EXPECT().GetIssue(int64(1)).Return(issue, nil)
+		mockDB.EXPECT().AddIssueImages(int64(1), gomock.Any(), "resident", maxIssueImages, gomock.Any()).
+			DoAndReturn(func(id int64, images models.IssueImages, uploadedBy string, max int, review *string) (models.IssueImages, error) {
+				// Numbered after the issue's existing photo
+				if assert.NotNil(t, review) {
+					assert.Equal(t, "Photo 2 was taken 1.1 km from the reported location", *review)
+				}
+				return images, nil
+			})
+
+		w := postIssuePhoto(router, geotaggedJPEG(t, 50.7184, -3.5339, "2024:06:01 14:30:05"))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Reporter After Window", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", 48*time.Hour), nil)
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("Another Resident", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "neighbour", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().IsIssueReporter(int64(1), "neighbour").Return(false, nil)
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Reporter Of Merged Duplicate", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "neighbour", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().IsIssueReporter(int64(1), "neighbour").Return(true, nil)
+		mockDB.EXPECT().AddIssueImages(int64(1), gomock.Any(), "neighbour", maxIssueImages, nil).
+			DoAndReturn(func(id int64, images models.IssueImages, uploadedBy string, max int, review *string) (models.IssueImages, error) {
+				return images, nil
+			})
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Staff Any Time", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "dispatcher_user", models.RoleDispatcher)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", 30*24*time.Hour), nil)
+		mockDB.EXPECT().AddIssueImages(int64(1), gomock.Any(), "dispatcher_user", maxIssueImages, nil).
+			DoAndReturn(func(id int64, images models.IssueImages, uploadedBy string, max int, review *string) (models.IssueImages, error) {
+				return images, nil
+			})
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Too Many Images", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().AddIssueImages(int64(1), gomock.Any(), "resident", maxIssueImages, nil).
+			Return(nil, database.ErrTooManyImages)
+
+		w := postIssueImage(t, router)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		// The upload is removed again
+		assert.Empty(t, store.Keys())
+	})
+}
+
+func TestDeleteIssueImage(t *testing.T) {
+	image := models.IssueImage{ID: 7, Original: "a.jpg", Medium: "a-medium.jpg", Thumb: "a-thumb.jpg", UploadedBy: "resident"}
+	stored := func(store *storage.MemoryStore) {
+		for _, key := range image.Keys() {
+			assert.NoError(t, store.Put(context.Background(), key, strings.NewReader("jpeg"), 4, "image/jpeg"))
+		}
+	}
+	deleteImage := func(router *gin.Engine, imageID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/issues/1/images/"+imageID, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Reporter Removes Own Photo", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		stored(store)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour, image), nil)
+		mockDB.EXPECT().DeleteIssueImage(int64(1), int64(7), "resident").Return(&image, nil)
+
+		w := deleteImage(router, "7")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("Reporter Cannot Remove Staff Photo", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		stored(store)
+		staffImage := image
+		staffImage.UploadedBy = "engineer_user"
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour, staffImage), nil)
+
+		w := deleteImage(router, "7")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Len(t, store.Keys(), 3)
+	})
+
+	t.Run("Assigned Engineer", func(t *testing.T) {
+		router, mockDB, store := setupIssueImageTestRouter(t, "engineer_user", models.RoleEngineer)
+		stored(store)
+		issue := reportedIssue("resident", 30*24*time.Hour, image)
+		engineerID := int64(3)
+		issue.AssignedTo = &engineerID
+		mockDB.EXPECT().GetIssue(int64(1)).Return(issue, nil)
+		mockDB.EXPECT().GetUserByUsername("engineer_user").Return(&models.User{Username: "engineer_user", EngineerID: &engineerID}, nil)
+		mockDB.EXPECT().DeleteIssueImage(int64(1), int64(7), "engineer_user").Return(&image, nil)
+
+		w := deleteImage(router, "7")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("Image Not Found", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "dispatcher_user", models.RoleDispatcher)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour, image), nil)
+
+		w := deleteImage(router, "8")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestReorderIssueImages(t *testing.T) {
+	reorder := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/issues/1/images", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "resident", models.RoleResident)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().ReorderIssueImages(int64(1), []int64{8, 7}).Return(nil)
+
+		w := reorder(router, `{"order": [8, 7]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Order Mismatch", func(t *testing.T) {
+		router, mockDB, _ := setupIssueImageTestRouter(t, "dispatcher_user", models.RoleDispatcher)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(reportedIssue("resident", time.Hour), nil)
+		mockDB.EXPECT().ReorderIssueImages(int64(1), []int64{8}).Return(database.ErrImageOrderMismatch)
+
+		w := reorder(router, `{"order": [8]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"net/http"
+
+	"chalkstone.council/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary JSON Web Key Set
+// @Description Public keys that access tokens are signed with, identified by their kid, so other services can verify them. Keys scheduled to become active are listed in advance. Empty when tokens are signed with a shared secret.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} map[string][]middleware.JWK
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": middleware.PublicKeys()})
+}
@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"chalkstone.council/internal/middleware"
 	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	dbMock "chalkstone.council/internal/database/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -23,7 +25,7 @@ func TestListEngineersComprehensive(t *testing.T) {
 	router := gin.Default()
 	
 	// Create a real Handler instance with our mock DB
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Create a test group with middleware that sets userType for staff
 	api := router.Group("/api")
@@ -37,7 +39,7 @@ func TestListEngineersComprehensive(t *testing.T) {
 	// Setup routes for testing - with and without auth
 	engineersGroup := api.Group("/engineers")
 	engineersGroup.GET("", addStaffAuth, handler.ListEngineers)
-	engineersGroup.GET("/noauth", handler.ListEngineers) // Route without staff auth for testing
+	engineersGroup.GET("/noauth", middleware.RequirePermission(models.PermEngineersRead), handler.ListEngineers) // Route without staff auth for testing
 
 	// Test case 1: Successfully retrieve engineers
 	t.Run("Success", func(t *testing.T) {
@@ -60,7 +62,7 @@ func TestListEngineersComprehensive(t *testing.T) {
 		}
 		
 		// Set up mock expectation
-		mockDB.EXPECT().ListEngineers().Return(mockEngineers, nil)
+		mockDB.EXPECT().ListEngineers(false).Return(mockEngineers, nil)
 		
 		// Create request
 		req, _ := http.NewRequest("GET", "/api/engineers", nil)
@@ -84,7 +86,7 @@ func TestListEngineersComprehensive(t *testing.T) {
 	// Test case 2: Empty list of engineers
 	t.Run("Empty List", func(t *testing.T) {
 		// Set up mock expectation for empty list
-		mockDB.EXPECT().ListEngineers().Return([]*models.Engineer{}, nil)
+		mockDB.EXPECT().ListEngineers(false).Return([]*models.Engineer{}, nil)
 		
 		// Create request
 		req, _ := http.NewRequest("GET", "/api/engineers", nil)
@@ -104,7 +106,7 @@ func TestListEngineersComprehensive(t *testing.T) {
 	// Test case 3: Database error
 	t.Run("Database Error", func(t *testing.T) {
 		// Set up mock expectation for database error
-		mockDB.EXPECT().ListEngineers().Return(nil, errors.New("database error"))
+		mockDB.EXPECT().ListEngineers(false).Return(nil, errors.New("database error"))
 		
 		// Create request
 		req, _ := http.NewRequest("GET", "/api/engineers", nil)
@@ -135,6 +137,6 @@ func TestListEngineersComprehensive(t *testing.T) {
 		var response map[string]string
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "Staff access required", response["error"])
+		assert.Equal(t, "Insufficient permissions", response["error"])
 	})
 }
@@ -51,7 +51,7 @@ func TestListIssuesDatabaseError(t *testing.T) {
 	router, mockDB, _ := setupTestRouter(t)
 	
 	// Expect ListIssues to be called but return an error
-	mockDB.EXPECT().ListIssues(1, 10).Return(nil, errors.New("database error"))
+	mockDB.EXPECT().ListIssues(1, 10, models.SortNewest, false).Return(nil, errors.New("database error"))
 	
 	req, _ := http.NewRequest("GET", "/api/issues", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -86,7 +86,7 @@ func TestListIssuesCustomPagination(t *testing.T) {
 	}
 	
 	// Test with custom page and page size
-	mockDB.EXPECT().ListIssues(2, 5).Return(mockIssues, nil)
+	mockDB.EXPECT().ListIssues(2, 5, models.SortNewest, false).Return(mockIssues, nil)
 	
 	req, _ := http.NewRequest("GET", "/api/issues?page=2&pageSize=5", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -126,7 +126,7 @@ func TestListIssuesInvalidPagination(t *testing.T) {
 	}
 	
 	// Test with invalid page and page size (should use defaults)
-	mockDB.EXPECT().ListIssues(1, 10).Return(mockIssues, nil)
+	mockDB.EXPECT().ListIssues(1, 10, models.SortNewest, false).Return(mockIssues, nil)
 	
 	req, _ := http.NewRequest("GET", "/api/issues?page=-1&pageSize=200", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -149,7 +149,7 @@ func TestListIssuesEmptyResult(t *testing.T) {
 	router, mockDB, _ := setupTestRouter(t)
 	
 	// Return empty list
-	mockDB.EXPECT().ListIssues(1, 10).Return([]*models.Issue{}, nil)
+	mockDB.EXPECT().ListIssues(1, 10, models.SortNewest, false).Return([]*models.Issue{}, nil)
 	
 	req, _ := http.NewRequest("GET", "/api/issues", nil)
 	req.Header.Set("Authorization", "Bearer test_token")
@@ -167,3 +167,47 @@ func TestListIssuesEmptyResult(t *testing.T) {
 	assert.True(t, ok, "Issues should be an array")
 	assert.Equal(t, 0, len(issues))
 }
+
+func TestListIssuesSortBySupporters(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().ListIssues(1, 10, models.SortSupporters, false).Return([]*models.Issue{
+		{ID: 2, SupporterCount: 12},
+		{ID: 1, SupporterCount: 3},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/issues?sort=supporters", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Issues []models.Issue `json:"issues"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 12, response.Issues[0].SupporterCount)
+
+	// Unknown sort orders are rejected
+	req, _ = http.NewRequest("GET", "/api/issues?sort=loudest", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListIssuesNeedingLocationReview(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	review := "Photo 1 was taken 1.1 km from the reported location"
+	mockDB.EXPECT().ListIssues(1, 10, models.SortNewest, true).Return([]*models.Issue{
+		{ID: 3, LocationReview: &review},
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/issues?location_review=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), review)
+}
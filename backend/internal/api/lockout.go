@@ -0,0 +1,140 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLoginFreeAttempts    = 3
+	defaultLoginBaseDelay       = time.Second
+	defaultLoginMaxAttempts     = 10
+	defaultLoginLockoutDuration = 15 * time.Minute
+	defaultLockoutAuditDays     = 30
+)
+
+// LoadLoginPolicy reads LOGIN_MAX_ATTEMPTS and LOGIN_LOCKOUT_MINUTES, falling back to
+// the defaults when they are unset or invalid. LOGIN_MAX_ATTEMPTS=0 disables lockout;
+// the backoff between failed attempts still applies.
+func LoadLoginPolicy() models.LoginPolicy {
+	policy := models.LoginPolicy{
+		FreeAttempts:    defaultLoginFreeAttempts,
+		BaseDelay:       defaultLoginBaseDelay,
+		MaxAttempts:     defaultLoginMaxAttempts,
+		LockoutDuration: defaultLoginLockoutDuration,
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_ATTEMPTS")); err == nil && n >= 0 {
+		policy.MaxAttempts = n
+	}
+	if minutes, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && minutes > 0 {
+		policy.LockoutDuration = time.Duration(minutes) * time.Minute
+	}
+	return policy
+}
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// checkDummyPassword spends as long as a real password check, so unknown usernames
+// can't be told apart from wrong passwords by the response time
+func checkDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = utils.HashPassword("not-a-real-password")
+	})
+	utils.CheckPasswordHash(password, dummyPasswordHash)
+}
+
+// respondLoginThrottled tells the client when it may try to log in again
+func respondLoginThrottled(c *gin.Context, retryAt time.Time) {
+	seconds := int(time.Until(retryAt).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, try again later",
+		"retry_after": seconds,
+	})
+}
+
+// recordFailedLogin counts a failed login and audit-logs the username being locked out
+func (h *Handler) recordFailedLogin(username string) {
+	attempts, locked, err := h.db.RecordFailedLogin(username, h.loginPolicy)
+	if err != nil {
+		log.Printf("Record failed login error: %v", err)
+		return
+	}
+	if locked {
+		log.Printf("AUDIT account locked: username=%q failed_attempts=%d locked_until=%s",
+			username, attempts.FailedCount, attempts.LockedUntil.UTC().Format(time.RFC3339))
+	}
+}
+
+// @Summary Unlock account
+// @Description Lift the login lockout of a username before it expires
+// @Tags users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]string
+// @Failure 403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /users/{username}/lockout [delete]
+func (h *Handler) UnlockAccount(c *gin.Context) {
+	username := c.Param("username")
+	actor := c.GetString("userID")
+
+	err := h.db.UnlockAccount(username, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Account is not locked", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to unlock account", err)
+		return
+	}
+
+	log.Printf("AUDIT account unlocked: username=%q by=%q", username, actor)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked successfully"})
+}
+
+// @Summary List account lockouts
+// @Description Get the audit log of login lockouts, newest first
+// @Tags users
+// @Produce json
+// @Param days query int false "How many days back to list (default 30)"
+// @Success 200 {array} models.AccountLockout
+// @Failure 400,403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /users/lockouts [get]
+func (h *Handler) ListAccountLockouts(c *gin.Context) {
+	days := defaultLockoutAuditDays
+	if param := c.Query("days"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid days", err)
+			return
+		}
+		days = n
+	}
+
+	lockouts, err := h.db.ListAccountLockouts(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list lockouts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lockouts)
+}
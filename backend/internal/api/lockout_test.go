@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testLoginPolicy = models.LoginPolicy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxAttempts:     10,
+	LockoutDuration: 15 * time.Minute,
+}
+
+func setupLockoutTestRouter(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOperations) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB, loginPolicy: testLoginPolicy}
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/auth/login", handler.Login)
+
+	users := api.Group("/users")
+	users.Use(func(c *gin.Context) {
+		c.Set("userID", "admin_user")
+		c.Set("userType", models.RoleAdmin)
+		c.Next()
+	})
+	users.GET("/lockouts", handler.ListAccountLockouts)
+	users.DELETE("/:username/lockout", handler.UnlockAccount)
+
+	return router, mockDB
+}
+
+func sendLogin(router *gin.Engine, username, password string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginLockout(t *testing.T) {
+	router, mockDB := setupLockoutTestRouter(t)
+
+	t.Run("Locked account", func(t *testing.T) {
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		mockDB.EXPECT().GetLoginAttempts("target").Return(&models.LoginAttempts{
+			Username: "target", FailedCount: 10, LastFailedAt: time.Now(), LockedUntil: &lockedUntil,
+		}, nil)
+
+		// The password isn't checked while locked
+		w := sendLogin(router, "target", "password")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("target").Return(&models.LoginAttempts{
+			Username: "target", FailedCount: 6, LastFailedAt: time.Now(),
+		}, nil)
+
+		w := sendLogin(router, "target", "password")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("Failure that locks the account", func(t *testing.T) {
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		mockDB.EXPECT().GetLoginAttempts("ghost").Return(&models.LoginAttempts{
+			Username: "ghost", FailedCount: 9, LastFailedAt: time.Now().Add(-time.Hour),
+		}, nil)
+		mockDB.EXPECT().GetUserByUsername("ghost").Return(nil, sql.ErrNoRows)
+		mockDB.EXPECT().RecordFailedLogin("ghost", testLoginPolicy).Return(&models.LoginAttempts{
+			Username: "ghost", FailedCount: 10, LockedUntil: &lockedUntil,
+		}, true, nil)
+
+		// Unknown usernames get the same response as wrong passwords
+		w := sendLogin(router, "ghost", "password")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "Invalid credentials"}`, w.Body.String())
+	})
+
+	t.Run("Success clears failures", func(t *testing.T) {
+		hash, _ := utils.HashPassword("password")
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(&models.LoginAttempts{
+			Username: "jane", FailedCount: 2, LastFailedAt: time.Now(),
+		}, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(&models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleResident}, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(nil, nil)
+		mockDB.EXPECT().ClearLoginAttempts("jane").Return(nil)
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		w := sendLogin(router, "jane", "password")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestUnlockAccount(t *testing.T) {
+	router, mockDB := setupLockoutTestRouter(t)
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().UnlockAccount("target", "admin_user").Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/api/users/target/lockout", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Not locked", func(t *testing.T) {
+		mockDB.EXPECT().UnlockAccount("jane", "admin_user").Return(sql.ErrNoRows)
+
+		req, _ := http.NewRequest("DELETE", "/api/users/jane/lockout", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestListAccountLockouts(t *testing.T) {
+	router, mockDB := setupLockoutTestRouter(t)
+
+	t.Run("Success", func(t *testing.T) {
+		mockDB.EXPECT().ListAccountLockouts(gomock.Any()).DoAndReturn(func(since time.Time) ([]*models.AccountLockout, error) {
+			assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), since, time.Minute)
+			return []*models.AccountLockout{{ID: 1, Username: "target", FailedCount: 10}}, nil
+		})
+
+		req, _ := http.NewRequest("GET", "/api/users/lockouts?days=7", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response []models.AccountLockout
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response, 1)
+	})
+
+	t.Run("Invalid days", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/users/lockouts?days=0", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	"chalkstone.council/internal/utils"
 	dbMock "chalkstone.council/internal/database/mocks"
 	"github.com/gin-gonic/gin"
@@ -27,7 +28,7 @@ func TestRealLoginHandler(t *testing.T) {
 	router := gin.Default()
 	
 	// Create a real Handler instance with our mock DB
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up the login route with the real handler
 	api := router.Group("/api")
@@ -50,11 +51,14 @@ func TestRealLoginHandler(t *testing.T) {
 			ID:           1,
 			Username:     "testuser",
 			PasswordHash: hashedPassword,
-			UserType:     "staff",
+			UserType:     "resident",
 		}
 		
-		// Mock database call
+		// Mock database calls
+		mockDB.EXPECT().GetLoginAttempts("testuser").Return(nil, nil)
 		mockDB.EXPECT().GetUserByUsername("testuser").Return(mockUser, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("testuser").Return(nil, nil)
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
 		
 		// Create request
 		jsonData, _ := json.Marshal(loginPayload)
@@ -71,6 +75,8 @@ func TestRealLoginHandler(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 		assert.Contains(t, response, "token")
+		assert.Contains(t, response, "refresh_token")
+		assert.Contains(t, response, "expires_at")
 	})
 	
 	// Test case 2: Invalid password
@@ -90,7 +96,9 @@ func TestRealLoginHandler(t *testing.T) {
 			UserType:     "staff",
 		}
 		
+		mockDB.EXPECT().GetLoginAttempts("testuser").Return(nil, nil)
 		mockDB.EXPECT().GetUserByUsername("testuser").Return(mockUser, nil)
+		mockDB.EXPECT().RecordFailedLogin("testuser", gomock.Any()).Return(&models.LoginAttempts{Username: "testuser", FailedCount: 1}, false, nil)
 		
 		jsonData, _ := json.Marshal(loginPayload)
 		req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(jsonData))
@@ -114,6 +122,7 @@ func TestRealLoginHandler(t *testing.T) {
 			"password": "testpassword",
 		}
 		
+		mockDB.EXPECT().GetLoginAttempts("testuser").Return(nil, nil)
 		mockDB.EXPECT().GetUserByUsername("testuser").Return(nil, errors.New("database error"))
 		
 		jsonData, _ := json.Marshal(loginPayload)
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"os"
+	"testing"
+
+	"chalkstone.council/internal/middleware"
+)
+
+// testJWTSecret signs the tokens issued by handlers under test
+const testJWTSecret = "api-test-secret"
+
+func TestMain(m *testing.M) {
+	middleware.SetSecretKeyForTesting(testJWTSecret)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"chalkstone.council/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxMapRadiusMeters caps radius searches so a single request can't scan the whole city
+const maxMapRadiusMeters = 50000
+
+// parseMapFilter reads the bbox, near, radius, status and type query parameters of the map endpoint
+func parseMapFilter(c *gin.Context) (models.MapFilter, error) {
+	var filter models.MapFilter
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		values, err := parseFloatList(bbox, 4)
+		if err != nil {
+			return filter, errors.New("Invalid bbox, expected minLon,minLat,maxLon,maxLat")
+		}
+		box := models.BoundingBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+		if !box.Valid() {
+			return filter, errors.New("Invalid bbox, expected minLon,minLat,maxLon,maxLat")
+		}
+		filter.BBox = &box
+	}
+
+	if near := c.Query("near"); near != "" {
+		values, err := parseFloatList(near, 2)
+		if err != nil || !models.ValidLatitude(values[0]) || !models.ValidLongitude(values[1]) {
+			return filter, errors.New("Invalid near, expected lat,lon")
+		}
+		radius, err := strconv.ParseFloat(c.Query("radius"), 64)
+		if err != nil || radius <= 0 || radius > maxMapRadiusMeters {
+			return filter, errors.New("Invalid radius, expected meters between 0 and 50000")
+		}
+		filter.NearLat, filter.NearLon = &values[0], &values[1]
+		filter.RadiusMeters = radius
+	} else if c.Query("radius") != "" {
+		return filter, errors.New("radius requires near")
+	}
+
+	if status := c.Query("status"); status != "" {
+		if !models.ValidateIssueStatus(models.IssueStatus(status)) {
+			return filter, errors.New("Invalid status")
+		}
+		filter.Status = models.IssueStatus(status)
+	}
+
+	if issueType := c.Query("type"); issueType != "" {
+		if !models.ValidateIssueType(models.IssueType(issueType)) {
+			return filter, errors.New("Invalid issue type")
+		}
+		filter.Type = models.IssueType(issueType)
+	}
+
+	return filter, nil
+}
+
+// parseFloatList parses a comma separated list of exactly n numbers
+func parseFloatList(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, errors.New("wrong number of values")
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chalkstone.council/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetIssuesForMapBoundingBox(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().GetIssuesForMap(gomock.Any()).
+		Do(func(filter models.MapFilter) {
+			assert.Equal(t, &models.BoundingBox{MinLon: -3.6, MinLat: 50.7, MaxLon: -3.5, MaxLat: 50.8}, filter.BBox)
+			assert.Equal(t, models.TypePothole, filter.Type)
+			assert.Equal(t, models.StatusNew, filter.Status)
+			assert.Nil(t, filter.NearLat)
+		}).
+		Return([]*models.Issue{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/issues/map?bbox=-3.6,50.7,-3.5,50.8&type=POTHOLE&status=NEW", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetIssuesForMapNear(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().GetIssuesForMap(gomock.Any()).
+		Do(func(filter models.MapFilter) {
+			assert.Nil(t, filter.BBox)
+			assert.Equal(t, 50.72, *filter.NearLat)
+			assert.Equal(t, -3.53, *filter.NearLon)
+			assert.Equal(t, 250.0, filter.RadiusMeters)
+		}).
+		Return([]*models.Issue{}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/issues/map?near=50.72,-3.53&radius=250", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetIssuesForMapInvalidQuery(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+
+	queries := []string{
+		"bbox=-3.6,50.7,-3.5",
+		"bbox=-3.5,50.7,-3.6,50.8",
+		"bbox=a,b,c,d",
+		"near=50.72,-3.53",
+		"near=50.72,-3.53&radius=-1",
+		"near=50.72,-3.53&radius=100000",
+		"near=95,-3.53&radius=100",
+		"radius=100",
+		"status=BROKEN",
+		"type=UFO",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/issues/map?"+query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
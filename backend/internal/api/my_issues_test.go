@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListMyIssues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	router := gin.New()
+	router.GET("/api/me/issues", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", "public")
+		c.Next()
+	}, handler.ListMyIssues)
+	router.GET("/api/no-auth/me/issues", handler.ListMyIssues)
+
+	t.Run("Success", func(t *testing.T) {
+		engineerName := "John Smith"
+		engineerID := int64(1)
+		issue := &models.ReportedIssue{AssignedEngineerName: &engineerName}
+		issue.ID = 7
+		issue.Status = models.StatusInProgress
+		issue.ReportedBy = "resident"
+		issue.AssignedTo = &engineerID
+
+		mockDB.EXPECT().ListIssuesByReporter("resident", 2, 5).Return([]*models.ReportedIssue{issue}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/me/issues?page=2&pageSize=5", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Page     int                      `json:"page"`
+			PageSize int                      `json:"pageSize"`
+			Issues   []map[string]interface{} `json:"issues"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, response.Page)
+		assert.Equal(t, 5, response.PageSize)
+		assert.Len(t, response.Issues, 1)
+		assert.Equal(t, "IN_PROGRESS", response.Issues[0]["status"])
+		assert.Equal(t, "John Smith", response.Issues[0]["assigned_engineer_name"])
+		assert.Contains(t, response.Issues[0], "updated_at")
+	})
+
+	t.Run("Invalid Pagination Uses Defaults", func(t *testing.T) {
+		mockDB.EXPECT().ListIssuesByReporter("resident", 1, 10).Return([]*models.ReportedIssue{}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/me/issues?page=-1&pageSize=500", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mockDB.EXPECT().ListIssuesByReporter("resident", 1, 10).Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("GET", "/api/me/issues", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/no-auth/me/issues", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"chalkstone.council/internal/credentials"
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/oidc"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+	oidcLoginTTL    = 10 * time.Minute
+
+	// Provisioned usernames leave room for the suffix added when the name is taken
+	oidcUsernameLength = 24
+)
+
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// @Summary Log in with the council identity provider
+// @Description Start an OpenID Connect login. Redirects to the identity provider with a PKCE challenge; the provider sends the user back to /auth/oidc/callback. Returns 404 when OIDC login isn't configured.
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/login [get]
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "OIDC login is not configured", nil)
+		return
+	}
+
+	state, stateHash, err := newOneTimeCode()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start login", err)
+		return
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start login", err)
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start login", err)
+		return
+	}
+
+	authURL, err := h.oidc.AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadGateway, "Identity provider is unavailable", err)
+		return
+	}
+
+	login := &models.OIDCLogin{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcLoginTTL),
+	}
+	if err := h.db.CreateOIDCLogin(login); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start login", err)
+		return
+	}
+
+	// The state is also bound to this browser, so a callback URL can't be replayed in
+	// someone else's session
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(oidcLoginTTL.Seconds()), oidcCookiePath, "", h.oidcSecureCookie(), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary Identity provider callback
+// @Description Finish an OpenID Connect login. Verifies the state and ID token, maps the user's groups to a staff role, creates the account on first login and issues the usual access and refresh tokens. When OIDC_POST_LOGIN_REDIRECT is set the tokens are passed to that page in the URL fragment instead of the response body. The identity provider is responsible for multi-factor authentication, so no TOTP challenge follows.
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the login request"
+// @Success 200 {object} map[string]interface{}
+// @Success 302
+// @Failure 400,401,403,404,409 {object} map[string]string
+// @Failure 500,502 {object} map[string]string
+// @Router /auth/oidc/callback [get]
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "OIDC login is not configured", nil)
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.oidcFailure(c, http.StatusUnauthorized, "Login was cancelled or refused by the identity provider",
+			errors.New(providerErr+": "+c.Query("error_description")))
+		return
+	}
+
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oidcStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, "", h.oidcSecureCookie(), true)
+	if state == "" || c.Query("code") == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookie)) != 1 {
+		h.oidcFailure(c, http.StatusBadRequest, "Invalid login state, please start the login again", nil)
+		return
+	}
+
+	login, err := h.db.ConsumeOIDCLogin(middleware.HashToken(state))
+	if errors.Is(err, database.ErrOIDCLoginInvalid) {
+		h.oidcFailure(c, http.StatusBadRequest, "Login has expired, please start it again", nil)
+		return
+	}
+	if err != nil {
+		h.oidcFailure(c, http.StatusInternalServerError, "Failed to complete login", err)
+		return
+	}
+
+	claims, err := h.oidc.Exchange(c.Request.Context(), c.Query("code"), login.CodeVerifier, login.Nonce)
+	if err != nil {
+		h.oidcFailure(c, http.StatusUnauthorized, "Login with the identity provider failed", err)
+		return
+	}
+
+	role := h.oidc.RoleForGroups(claims.Groups)
+	if role == "" {
+		log.Printf("OIDC login refused: subject=%q has no group with a staff role", claims.Subject)
+		h.oidcFailure(c, http.StatusForbidden, "Your account has no access to this service", nil)
+		return
+	}
+
+	identity := &models.OIDCIdentity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Username:      oidcUsername(claims),
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Role:          role,
+	}
+	user, err := h.db.ProvisionOIDCUser(identity)
+	if errors.Is(err, database.ErrUsernameTaken) {
+		// Someone else has the name already, e.g. a local account; never take it over
+		identity.Username = withIdentitySuffix(identity.Username, claims)
+		user, err = h.db.ProvisionOIDCUser(identity)
+	}
+	switch {
+	case errors.Is(err, database.ErrNoEngineerForEmail):
+		h.oidcFailure(c, http.StatusForbidden, "No engineer record matches your email address", err)
+		return
+	case errors.Is(err, database.ErrEmailNotVerified):
+		h.oidcFailure(c, http.StatusForbidden, "Your identity provider hasn't verified your email address", err)
+		return
+	case errors.Is(err, database.ErrUsernameTaken), errors.Is(err, database.ErrEngineerAlreadyLinked):
+		h.oidcFailure(c, http.StatusConflict, "Your account conflicts with an existing account, contact an administrator", err)
+		return
+	case err != nil:
+		h.oidcFailure(c, http.StatusInternalServerError, "Failed to complete login", err)
+		return
+	}
+
+	tokens, err := h.issueTokens(user.Username, user.UserType)
+	if err != nil {
+		h.oidcFailure(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+	log.Printf("AUDIT OIDC login: username=%q role=%q issuer=%q subject=%q",
+		user.Username, user.UserType, claims.Issuer, claims.Subject)
+
+	if h.oidc.PostLoginRedirect != "" {
+		fragment := url.Values{}
+		for key, value := range tokens {
+			fragment.Set(key, value.(string))
+		}
+		c.Redirect(http.StatusFound, h.oidc.PostLoginRedirect+"#"+fragment.Encode())
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// oidcFailure reports a failed callback to the frontend when OIDC_POST_LOGIN_REDIRECT
+// is set, as the user arrives there from the identity provider rather than from an API
+// call, and as a JSON error otherwise
+func (h *Handler) oidcFailure(c *gin.Context, code int, message string, err error) {
+	if h.oidc.PostLoginRedirect == "" {
+		utils.RespondWithError(c, code, message, err)
+		return
+	}
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+	}
+	fragment := url.Values{}
+	fragment.Set("error", message)
+	c.Redirect(http.StatusFound, h.oidc.PostLoginRedirect+"#"+fragment.Encode())
+}
+
+func (h *Handler) oidcSecureCookie() bool {
+	return strings.HasPrefix(h.oidc.RedirectURL, "https://")
+}
+
+// oidcUsername derives a username for a new account from the preferred username or
+// email the identity provider asserts, falling back to one derived from the subject
+func oidcUsername(claims *oidc.Claims) string {
+	name := claims.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	name = invalidUsernameChars.ReplaceAllString(name, "")
+	name = strings.TrimLeft(name, "._-")
+	if len(name) > oidcUsernameLength {
+		name = name[:oidcUsernameLength]
+	}
+	if len(credentials.ValidateUsername(name)) > 0 {
+		return "staff-" + identityHash(claims)[:8]
+	}
+	return name
+}
+
+// withIdentitySuffix makes a taken username unique to the identity
+func withIdentitySuffix(username string, claims *oidc.Claims) string {
+	return username + "-" + identityHash(claims)[:6]
+}
+
+func identityHash(claims *oidc.Claims) string {
+	sum := sha256.Sum256([]byte(claims.Issuer + "\x00" + claims.Subject))
+	return hex.EncodeToString(sum[:])
+}
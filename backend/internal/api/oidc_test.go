@@ -0,0 +1,218 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/oidc"
+	"chalkstone.council/internal/oidc/oidctest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const oidcTestCallback = "http://localhost:8080/api/auth/oidc/callback"
+
+// setupOIDCTestRouter registers the OIDC routes against a local mock identity provider
+// that asserts the given user
+func setupOIDCTestRouter(t *testing.T, user oidctest.User) (*gin.Engine, *dbMock.MockDatabaseOperations, *Handler) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+
+	idp, server, err := oidctest.NewServer("council-api")
+	assert.NoError(t, err)
+	t.Cleanup(server.Close)
+	idp.SetUser(user)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:      idp.Issuer,
+		ClientID:    "council-api",
+		RedirectURL: oidcTestCallback,
+		Scopes:      []string{"openid", "profile", "email"},
+		GroupsClaim: "groups",
+		GroupRoles:  map[string]string{"highways-dispatch": "dispatcher", "highways-crew": "engineer"},
+	}, server.Client())
+	handler := &Handler{db: mockDB, oidc: provider}
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", handler.OIDCLogin)
+	router.GET("/api/auth/oidc/callback", handler.OIDCCallback)
+	return router, mockDB, handler
+}
+
+// startOIDCLogin starts a login, lets the mock provider approve it, and returns the
+// callback request the browser would make next
+func startOIDCLogin(t *testing.T, router *gin.Engine, mockDB *dbMock.MockDatabaseOperations) (*http.Request, *models.OIDCLogin) {
+	var stored *models.OIDCLogin
+	mockDB.EXPECT().CreateOIDCLogin(gomock.Any()).DoAndReturn(func(login *models.OIDCLogin) error {
+		stored = login
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/auth/oidc/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, middleware.HashToken(cookies[0].Value), stored.StateHash)
+
+	authURL, _ := url.Parse(w.Header().Get("Location"))
+	assert.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+	assert.Equal(t, stored.Nonce, authURL.Query().Get("nonce"))
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL.String())
+	assert.NoError(t, err)
+	resp.Body.Close()
+	callback := resp.Header.Get("Location")
+	assert.True(t, strings.HasPrefix(callback, oidcTestCallback))
+
+	callbackReq, _ := http.NewRequest("GET", strings.TrimPrefix(callback, "http://localhost:8080"), nil)
+	callbackReq.AddCookie(cookies[0])
+	return callbackReq, stored
+}
+
+func TestOIDCLoginProvisionsStaff(t *testing.T) {
+	router, mockDB, _ := setupOIDCTestRouter(t, oidctest.User{
+		Subject: "0a1b2c", Email: "j.smith@chalkstone.gov.uk", PreferredUsername: "j.smith",
+		Groups: []string{"all-staff", "highways-dispatch"},
+	})
+	callback, login := startOIDCLogin(t, router, mockDB)
+
+	mockDB.EXPECT().ConsumeOIDCLogin(login.StateHash).Return(login, nil)
+	mockDB.EXPECT().ProvisionOIDCUser(gomock.Any()).DoAndReturn(func(identity *models.OIDCIdentity) (*models.User, error) {
+		assert.Equal(t, "0a1b2c", identity.Subject)
+		assert.Equal(t, "j.smith", identity.Username)
+		assert.Equal(t, "dispatcher", identity.Role)
+		assert.True(t, identity.EmailVerified)
+		return &models.User{ID: 7, Username: "j.smith", UserType: "dispatcher"}, nil
+	})
+	mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := decodeBody(t, w)
+	assert.NotEmpty(t, response["token"])
+	assert.NotEmpty(t, response["refresh_token"])
+}
+
+func TestOIDCLoginRedirectsTokensToFrontend(t *testing.T) {
+	router, mockDB, handler := setupOIDCTestRouter(t, oidctest.User{
+		Subject: "0a1b2c", PreferredUsername: "admin", Groups: []string{"highways-dispatch"},
+	})
+	handler.oidc.PostLoginRedirect = "https://report.chalkstone.gov.uk/login/complete"
+	callback, login := startOIDCLogin(t, router, mockDB)
+
+	mockDB.EXPECT().ConsumeOIDCLogin(login.StateHash).Return(login, nil)
+	gomock.InOrder(
+		// "admin" is reserved, so the username comes from the subject; when that is taken
+		// by another account the identity suffix is added
+		mockDB.EXPECT().ProvisionOIDCUser(gomock.Any()).DoAndReturn(func(identity *models.OIDCIdentity) (*models.User, error) {
+			assert.True(t, strings.HasPrefix(identity.Username, "staff-"))
+			return nil, database.ErrUsernameTaken
+		}),
+		mockDB.EXPECT().ProvisionOIDCUser(gomock.Any()).DoAndReturn(func(identity *models.OIDCIdentity) (*models.User, error) {
+			assert.Regexp(t, `^staff-[0-9a-f]{8}-[0-9a-f]{6}$`, identity.Username)
+			return &models.User{ID: 8, Username: identity.Username, UserType: identity.Role}, nil
+		}),
+	)
+	mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	location, _ := url.Parse(w.Header().Get("Location"))
+	assert.Equal(t, "report.chalkstone.gov.uk", location.Host)
+	fragment, _ := url.ParseQuery(location.Fragment)
+	assert.NotEmpty(t, fragment.Get("token"))
+	assert.NotEmpty(t, fragment.Get("refresh_token"))
+}
+
+func TestOIDCLoginRefusesUsersWithoutStaffGroup(t *testing.T) {
+	router, mockDB, _ := setupOIDCTestRouter(t, oidctest.User{
+		Subject: "0a1b2c", PreferredUsername: "visitor", Groups: []string{"all-staff"},
+	})
+	callback, login := startOIDCLogin(t, router, mockDB)
+	mockDB.EXPECT().ConsumeOIDCLogin(login.StateHash).Return(login, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOIDCLoginRefusesEngineerWithoutRecord(t *testing.T) {
+	router, mockDB, _ := setupOIDCTestRouter(t, oidctest.User{
+		Subject: "0a1b2c", Email: "new.starter@chalkstone.gov.uk", Groups: []string{"highways-crew"},
+	})
+	callback, login := startOIDCLogin(t, router, mockDB)
+	mockDB.EXPECT().ConsumeOIDCLogin(login.StateHash).Return(login, nil)
+	mockDB.EXPECT().ProvisionOIDCUser(gomock.Any()).Return(nil, database.ErrNoEngineerForEmail)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOIDCLoginRefusesEngineerWithUnverifiedEmail(t *testing.T) {
+	router, mockDB, _ := setupOIDCTestRouter(t, oidctest.User{
+		Subject: "0a1b2c", Email: "s.field@chalkstone.gov.uk", EmailUnverified: true, Groups: []string{"highways-crew"},
+	})
+	callback, login := startOIDCLogin(t, router, mockDB)
+	mockDB.EXPECT().ConsumeOIDCLogin(login.StateHash).Return(login, nil)
+	mockDB.EXPECT().ProvisionOIDCUser(gomock.Any()).DoAndReturn(func(identity *models.OIDCIdentity) (*models.User, error) {
+		assert.Equal(t, models.RoleEngineer, identity.Role)
+		assert.False(t, identity.EmailVerified)
+		return nil, database.ErrEmailNotVerified
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "verified")
+}
+
+func TestOIDCCallbackRequiresMatchingState(t *testing.T) {
+	router, mockDB, _ := setupOIDCTestRouter(t, oidctest.User{Subject: "0a1b2c", Groups: []string{"highways-dispatch"}})
+	callback, _ := startOIDCLogin(t, router, mockDB)
+
+	// The callback URL alone, without the browser's state cookie, is refused
+	req, _ := http.NewRequest("GET", callback.URL.String(), nil)
+	req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "someone-elses-state"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A state that was already used or has expired
+	mockDB.EXPECT().ConsumeOIDCLogin(gomock.Any()).Return(nil, database.ErrOIDCLoginInvalid)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &Handler{}
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", handler.OIDCLogin)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/auth/oidc/login", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/imaging"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPhotoLocationToleranceMeters allows for the error of phone GPS and for
+// photos taken from across the road or further along the street
+const defaultPhotoLocationToleranceMeters = 250
+
+// LoadPhotoLocationTolerance reads PHOTO_LOCATION_TOLERANCE_METERS, how far from the
+// reported location an issue's photos may have been taken before the issue is flagged
+// for review. Zero disables the check; unset or invalid values give the default.
+func LoadPhotoLocationTolerance() float64 {
+	if meters, err := strconv.ParseFloat(os.Getenv("PHOTO_LOCATION_TOLERANCE_METERS"), 64); err == nil && meters >= 0 {
+		return meters
+	}
+	return defaultPhotoLocationToleranceMeters
+}
+
+// photoLocations reads where each uploaded photo was taken, which is lost once the
+// photos are uploaded. Photos without a recorded location give nil.
+func photoLocations(files []*multipart.FileHeader) ([]*imaging.Location, error) {
+	locations := make([]*imaging.Location, len(files))
+	for i, fileHeader := range files {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		meta, err := storage.ImageMetadata(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		locations[i] = meta.Location
+	}
+	return locations, nil
+}
+
+// suggestedLocation returns the location of the first photo that has one
+func suggestedLocation(locations []*imaging.Location) *imaging.Location {
+	for _, location := range locations {
+		if location != nil {
+			return location
+		}
+	}
+	return nil
+}
+
+// locationReview explains why the reported location needs checking when a photo was
+// taken further from it than the tolerance, naming the furthest photo. Returns nil if
+// every photo was taken nearby or has no location.
+func (h *Handler) locationReview(latitude, longitude float64, locations []*imaging.Location) *string {
+	if h.photoLocationTolerance <= 0 {
+		return nil
+	}
+
+	furthest, distance := -1, h.photoLocationTolerance
+	for i, location := range locations {
+		if location == nil {
+			continue
+		}
+		if d := models.HaversineDistance(latitude, longitude, location.Latitude, location.Longitude); d > distance {
+			furthest, distance = i, d
+		}
+	}
+	if furthest < 0 {
+		return nil
+	}
+
+	reason := fmt.Sprintf("Photo %d was taken %s from the reported location", furthest+1, formatDistance(distance))
+	return &reason
+}
+
+func formatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+// @Summary Resolve a location review
+// @Description Clear the location review of an issue once staff have checked its reported location. The reason is kept in the issue history.
+// @Tags issues
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,404,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/location-review [delete]
+func (h *Handler) ResolveLocationReview(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	err = h.db.ResolveLocationReview(id, userID.(string))
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if errors.Is(err, database.ErrNoLocationReview) {
+		utils.RespondWithError(c, http.StatusConflict, "Issue has no location to review", err)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to resolve location review", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location review resolved"})
+}
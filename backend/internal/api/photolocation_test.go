@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/imaging"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// geotaggedJPEG returns a JPEG whose EXIF data says it was taken at the given place
+// and time, in the little-endian layout phones write
+func geotaggedJPEG(t *testing.T, lat, lon float64, taken string) []byte {
+	le := binary.LittleEndian
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entry := func(tag, typ uint16, count, value uint32) {
+		tiff = le.AppendUint16(tiff, tag)
+		tiff = le.AppendUint16(tiff, typ)
+		tiff = le.AppendUint32(tiff, count)
+		tiff = le.AppendUint32(tiff, value)
+	}
+	ref := func(s string) uint32 { return uint32(s[0]) }
+	// Whole degrees as a fraction, with zero minutes and seconds
+	degrees := func(v float64) []byte {
+		b := le.AppendUint32(nil, uint32(math.Round(math.Abs(v)*1e6)))
+		b = le.AppendUint32(b, 1e6)
+		for i := 0; i < 2; i++ {
+			b = le.AppendUint32(b, 0)
+			b = le.AppendUint32(b, 1)
+		}
+		return b
+	}
+	latRef, lonRef := "N", "E"
+	if lat < 0 {
+		latRef = "S"
+	}
+	if lon < 0 {
+		lonRef = "W"
+	}
+
+	// IFD0 at 8 points at the Exif directory at 38 and the GPS directory at 56
+	tiff = le.AppendUint16(tiff, 2)
+	entry(0x8769, 4, 1, 38)
+	entry(0x8825, 4, 1, 56)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = le.AppendUint16(tiff, 1)
+	entry(0x9003, 2, 20, 110)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = le.AppendUint16(tiff, 4)
+	entry(0x0001, 2, 2, ref(latRef))
+	entry(0x0002, 5, 3, 130)
+	entry(0x0003, 2, 2, ref(lonRef))
+	entry(0x0004, 5, 3, 154)
+	tiff = le.AppendUint32(tiff, 0)
+	tiff = append(tiff, taken+"\x00"...)
+	tiff = append(tiff, degrees(lat)...)
+	tiff = append(tiff, degrees(lon)...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	photo := testJPEG(t, 64, 48)
+	out := append([]byte{}, photo[:2]...)
+	out = append(out, segment...)
+	return append(out, photo[2:]...)
+}
+
+func photoLocationRouter(handler *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "test_user")
+		c.Next()
+	})
+	router.POST("/api/issues", handler.CreateIssue)
+	return router
+}
+
+func postIssueWithPhoto(router *gin.Engine, location *imaging.Location, photo []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("type", "POTHOLE")
+	_ = writer.WriteField("description", "Test pothole description")
+	if location != nil {
+		_ = writer.WriteField("latitude", strconv.FormatFloat(location.Latitude, 'f', -1, 64))
+		_ = writer.WriteField("longitude", strconv.FormatFloat(location.Longitude, 'f', -1, 64))
+	}
+	fileWriter, _ := writer.CreateFormFile("images", "pothole.jpg")
+	_, _ = fileWriter.Write(photo)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/api/issues", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGeotaggedJPEG(t *testing.T) {
+	meta := imaging.ReadMetadata(geotaggedJPEG(t, 50.7184, -3.5339, "2024:06:01 14:30:05"))
+	if assert.NotNil(t, meta.Location) {
+		assert.InDelta(t, 50.7184, meta.Location.Latitude, 1e-6)
+		assert.InDelta(t, -3.5339, meta.Location.Longitude, 1e-6)
+	}
+	assert.NotNil(t, meta.CapturedAt)
+}
+
+func TestCreateIssueFlagsPhotoTakenElsewhere(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB, images: storage.NewMemoryStore("", nil), photoLocationTolerance: 250}
+	router := photoLocationRouter(handler)
+	photo := geotaggedJPEG(t, 50.7184, -3.5339, "2024:06:01 14:30:05")
+
+	tests := []struct {
+		name     string
+		reported imaging.Location
+		review   string
+	}{
+		{"nearby", imaging.Location{Latitude: 50.7190, Longitude: -3.5339}, ""},
+		{"elsewhere", imaging.Location{Latitude: 50.7284, Longitude: -3.5339}, "Photo 1 was taken 1.1 km from the reported location"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *models.IssueCreate
+			mockDB.EXPECT().CreateIssue(gomock.Any()).DoAndReturn(func(issue *models.IssueCreate) (int64, error) {
+				created = issue
+				return int64(1), nil
+			})
+
+			w := postIssueWithPhoto(router, &tt.reported, photo)
+			assert.Equal(t, http.StatusCreated, w.Code)
+			response := decodeBody(t, w)
+
+			if tt.review == "" {
+				assert.Nil(t, created.LocationReview)
+				assert.NotContains(t, response, "location_review")
+			} else if assert.NotNil(t, created.LocationReview) {
+				assert.Equal(t, tt.review, *created.LocationReview)
+				assert.Equal(t, tt.review, response["location_review"])
+			}
+
+			// The capture time is kept with the image after its metadata is stripped
+			if assert.Len(t, created.Images, 1) && assert.NotNil(t, created.Images[0].CapturedAt) {
+				expected := time.Date(2024, 6, 1, 14, 30, 5, 0, time.Local)
+				assert.True(t, expected.Equal(*created.Images[0].CapturedAt))
+			}
+		})
+	}
+}
+
+func TestCreateIssueSuggestsPhotoLocation(t *testing.T) {
+	store := storage.NewMemoryStore("", nil)
+	router := photoLocationRouter(&Handler{images: store, photoLocationTolerance: 250})
+
+	w := postIssueWithPhoto(router, nil, geotaggedJPEG(t, 50.7184, -3.5339, "2024:06:01 14:30:05"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	response := decodeBody(t, w)
+	assert.Equal(t, "Location is required", response["error"])
+	assert.Equal(t, map[string]interface{}{"latitude": 50.7184, "longitude": -3.5339}, response["suggested_location"])
+
+	// Nothing is stored until the reporter confirms the location
+	assert.Empty(t, store.Keys())
+
+	// Without a location in the photo there is nothing to suggest
+	w = postIssueWithPhoto(router, nil, testJPEG(t, 64, 48))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, decodeBody(t, w), "suggested_location")
+}
+
+func TestLocationReviewDisabled(t *testing.T) {
+	handler := &Handler{}
+	far := &imaging.Location{Latitude: 51.5074, Longitude: -0.1278}
+	assert.Nil(t, handler.locationReview(50.7184, -3.5339, []*imaging.Location{nil, far}))
+
+	handler.photoLocationTolerance = 250
+	review := handler.locationReview(50.7184, -3.5339, []*imaging.Location{nil, far})
+	if assert.NotNil(t, review) {
+		assert.Contains(t, *review, "Photo 2 was taken")
+	}
+}
+
+func TestResolveLocationReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "dispatcher_user")
+		c.Next()
+	})
+	router.DELETE("/api/issues/:id/location-review", handler.ResolveLocationReview)
+
+	resolve := func(id string) int {
+		req, _ := http.NewRequest("DELETE", "/api/issues/"+id+"/location-review", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	mockDB.EXPECT().ResolveLocationReview(int64(1), "dispatcher_user").Return(nil)
+	assert.Equal(t, http.StatusOK, resolve("1"))
+
+	mockDB.EXPECT().ResolveLocationReview(int64(1), "dispatcher_user").Return(database.ErrNoLocationReview)
+	assert.Equal(t, http.StatusConflict, resolve("1"))
+
+	mockDB.EXPECT().ResolveLocationReview(int64(2), "dispatcher_user").Return(sql.ErrNoRows)
+	assert.Equal(t, http.StatusNotFound, resolve("2"))
+
+	assert.Equal(t, http.StatusBadRequest, resolve("abc"))
+}
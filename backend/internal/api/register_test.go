@@ -9,8 +9,12 @@ import (
 	"os"
 	"testing"
 
+	"chalkstone.council/internal/database"
 	dbMock "chalkstone.council/internal/database/mocks"
 	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/credentials"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -34,8 +38,8 @@ func setupAuthTest() func() {
 	return func() {
 		// Restore original environment variable
 		os.Setenv("JWT_SECRET", origJwtSecret)
-		// Restore original secret key in middleware
-		middleware.SetSecretKeyForTesting(origJwtSecret)
+		// Restore the secret key of the other tests
+		middleware.SetSecretKeyForTesting(testJWTSecret)
 	}
 }
 
@@ -51,7 +55,7 @@ func TestRegisterInvalidJSON(t *testing.T) {
 	defer ctrl.Finish()
 	
 	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up routes
 	api := router.Group("/api")
@@ -84,7 +88,7 @@ func TestRegisterMissingRequiredFields(t *testing.T) {
 	defer ctrl.Finish()
 	
 	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up routes
 	api := router.Group("/api")
@@ -142,10 +146,10 @@ func TestRegisterCreateUserError(t *testing.T) {
 	
 	// Mock CreateUser to return an error
 	mockDB.EXPECT().
-		CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+		CreateUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(errors.New("database error"))
 	
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up routes
 	api := router.Group("/api")
@@ -186,14 +190,17 @@ func TestRegisterSuccess(t *testing.T) {
 	
 	// Expected arguments for CreateUser
 	username := "test_user"
-	userType := "public"
+	userType := models.RoleResident
 	
 	// Mock the CreateUser call to succeed
 	mockDB.EXPECT().
-		CreateUser(username, gomock.Any(), userType).
+		CreateUser(username, gomock.Any(), userType, gomock.Any()).
+		Return(nil)
+	mockDB.EXPECT().
+		CreateRefreshToken(gomock.Any()).
 		Return(nil)
 	
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up routes
 	api := router.Group("/api")
@@ -264,12 +271,12 @@ func TestRegisterStaffUser(t *testing.T) {
 	username := "staffuser"
 	userType := "staff"
 	
-	// Mock the CreateUser call to succeed
+	// Mock redeeming the invite, which creates the staff user
 	mockDB.EXPECT().
-		CreateUser(username, gomock.Any(), userType).
-		Return(nil)
+		RedeemStaffInvite(middleware.HashToken("invite-code"), "staff@chalkstone.gov.uk", username, gomock.Any()).
+		Return(userType, nil)
 	
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Set up routes
 	api := router.Group("/api")
@@ -277,10 +284,10 @@ func TestRegisterStaffUser(t *testing.T) {
 	
 	// Create a valid registration payload for staff user
 	reg := map[string]interface{}{
-		"username":     username,
-		"password":     "password123",
-		"is_staff":     true,
-		"staff_secret": "citycouncilstaff2023",
+		"username":    username,
+		"password":    "password123",
+		"email":       "staff@chalkstone.gov.uk",
+		"invite_code": "invite-code",
 	}
 	body, _ := json.Marshal(reg)
 	
@@ -295,31 +302,122 @@ func TestRegisterStaffUser(t *testing.T) {
 	// Verify - should succeed with 200 OK
 	assert.Equal(t, http.StatusOK, w.Code)
 	
-	// Verify response body contains token
-	var response map[string]string
+	// Verify the response is a two-factor enrolment challenge rather than tokens
+	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
-	if err != nil {
-		t.Logf("JSON unmarshal error: %v", err)
-	}
 	assert.NoError(t, err)
-	assert.Contains(t, response, "token")
-	
-	// Validate the token by parsing it
-	tokenString := response["token"]
+	assert.NotContains(t, response, "token")
+	assert.Equal(t, true, response["enrollment_required"])
 
-	// Parse the token
-	claims := &middleware.UserClaims{}
-	// Use the exact same secret that was set in setupAuthTest
-	testSecret := "test_secret_key_for_testing"
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-		return []byte(testSecret), nil
-	})
+	challengeUser, err := middleware.ParseChallengeToken(response["challenge_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, username, challengeUser)
+}
 
-	if err != nil {
-		t.Logf("Token validation error: %v", err)
+func TestRegisterPolicyViolations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+
+	handler := &Handler{db: mockDB, passwordPolicy: credentials.Policy{MinPasswordLength: 10, MinCharClasses: 3}}
+	router.POST("/api/register", handler.Register)
+
+	body, _ := json.Marshal(map[string]string{"username": "jo hn", "password": "jo hn"})
+	req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Every failed rule is listed, for the username and the password
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Error      string                  `json:"error"`
+		Violations []credentials.Violation `json:"violations"`
 	}
-	assert.NoError(t, err)
-	assert.True(t, token.Valid)
-	assert.Equal(t, username, claims.UserID)
-	assert.Equal(t, userType, claims.UserType)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	rules := []string{}
+	for _, v := range response.Violations {
+		rules = append(rules, v.Field+":"+v.Rule)
+	}
+	assert.Equal(t, []string{"username:characters", "password:min_length", "password:char_classes", "password:contains_username"}, rules)
+}
+
+func TestRegisterReservedDeletedUserName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+
+	handler := &Handler{db: mockDB, passwordPolicy: credentials.Policy{MinPasswordLength: 10}}
+	router.POST("/api/register", handler.Register)
+
+	// Deleted accounts are renamed to deleted-user-<id>, so nobody may register one
+	body, _ := json.Marshal(map[string]string{"username": "deleted-user-12", "password": "a-long-enough-password"})
+	req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Violations []credentials.Violation `json:"violations"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Violations, 1) {
+		assert.Equal(t, "reserved", response.Violations[0].Rule)
+	}
+}
+
+func TestRegisterUsernameTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	ctrl := gomock.NewController(t)
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+
+	handler := &Handler{db: mockDB, passwordPolicy: credentials.Policy{MinPasswordLength: 10}}
+	router.POST("/api/register", handler.Register)
+
+	send := func(payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assertTaken := func(t *testing.T, w *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response struct {
+			Violations []credentials.Violation `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Violations, 1) {
+			assert.Equal(t, "username", response.Violations[0].Field)
+			assert.Equal(t, "taken", response.Violations[0].Rule)
+		}
+	}
+
+	t.Run("Resident", func(t *testing.T) {
+		mockDB.EXPECT().CreateUser("jsmith", gomock.Any(), models.RoleResident, nil).Return(database.ErrUsernameTaken)
+
+		assertTaken(t, send(map[string]string{"username": "jsmith", "password": "a-long-enough-password"}))
+	})
+
+	t.Run("Invite", func(t *testing.T) {
+		mockDB.EXPECT().
+			RedeemStaffInvite(middleware.HashToken("invite-code"), "staff@chalkstone.gov.uk", "jsmith", gomock.Any()).
+			Return("", database.ErrUsernameTaken)
+
+		assertTaken(t, send(map[string]string{
+			"username":    "jsmith",
+			"password":    "a-long-enough-password",
+			"email":       "staff@chalkstone.gov.uk",
+			"invite_code": "invite-code",
+		}))
+	})
 }
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -22,7 +25,7 @@ func TestResolutionTimeHandler(t *testing.T) {
 	router := gin.Default()
 	
 	// Create a real Handler instance with our mock DB
-	handler := NewHandler(mockDB)
+	handler := NewHandler(mockDB, storage.NewMemoryStore("", nil))
 	
 	// Create a test group with middleware that sets userType
 	api := router.Group("/api")
@@ -38,7 +41,7 @@ func TestResolutionTimeHandler(t *testing.T) {
 	analyticsGroup.GET("/resolution-time", addStaffAuth, handler.ResolutionTime)
 	
 	// Add a route without staff authentication for testing unauthorized access
-	analyticsGroup.GET("/resolution-time-no-auth", handler.ResolutionTime)
+	analyticsGroup.GET("/resolution-time-no-auth", middleware.RequirePermission(models.PermAnalyticsRead), handler.ResolutionTime)
 
 	// Test case 1: Successful retrieval
 	t.Run("Success", func(t *testing.T) {
@@ -106,6 +109,6 @@ func TestResolutionTimeHandler(t *testing.T) {
 		var response map[string]string
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "Staff access required", response["error"])
+		assert.Equal(t, "Insufficient permissions", response["error"])
 	})
 }
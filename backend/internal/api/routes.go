@@ -3,17 +3,23 @@ package api
 import (
 	"chalkstone.council/internal/database"
 	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine, db database.DatabaseOperations, auth middleware.Authenticator) {
+func SetupRoutes(r *gin.Engine, db database.DatabaseOperations, images storage.ObjectStore, auth middleware.Authenticator) {
 	// Add health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
-	handler := NewHandler(db)
+	handler := NewHandler(db, images)
+
+	// Public keys for other services verifying our tokens
+	r.GET("/.well-known/jwks.json", handler.GetJWKS)
+
 	api := r.Group("/api")
 
 	// Auth routes
@@ -22,6 +28,21 @@ func SetupRoutes(r *gin.Engine, db database.DatabaseOperations, auth middleware.
 	{
 		authGroup.POST("/login", handler.Login)
 		authGroup.POST("/register", handler.Register)
+		authGroup.POST("/password-reset", handler.RequestPasswordReset)
+		authGroup.POST("/password-reset/confirm", handler.ConfirmPasswordReset)
+		authGroup.POST("/2fa", handler.VerifyTwoFactor)
+		authGroup.POST("/2fa/enroll", handler.EnrollTwoFactorWithChallenge)
+		authGroup.GET("/oidc/login", handler.OIDCLogin)
+		authGroup.GET("/oidc/callback", handler.OIDCCallback)
+	}
+
+	// Session routes - every signed in client refreshes each time its access token
+	// expires, and many residents may share an office or mobile carrier IP
+	sessionGroup := api.Group("/auth")
+	sessionGroup.Use(middleware.RateLimit(middleware.NewIPRateLimiter(1, 20))) // ~60 req/min
+	{
+		sessionGroup.POST("/refresh", handler.RefreshToken)
+		sessionGroup.POST("/logout", auth.AuthMiddleware(), handler.Logout)
 	}
 
 	// Issues - Public routes
@@ -30,38 +51,101 @@ func SetupRoutes(r *gin.Engine, db database.DatabaseOperations, auth middleware.
 		public.GET("/map", handler.GetIssuesForMap)
 	}
 
+	// Images - No login, but only through the expiring signed URLs handed out with issues
+	// and comments, for stores that can't presign URLs themselves
+	api.GET("/images/:key", handler.GetImage)
+
 	// Issues - Authenticated routes
 	authenticatedUser := api.Group("/issues")
 	authenticatedUser.Use(auth.AuthMiddleware())
 	{
 		authenticatedUser.POST("", handler.CreateIssue)
 		authenticatedUser.GET("/:id", handler.GetIssue)
+		authenticatedUser.GET("/:id/comments", handler.ListComments)
+		authenticatedUser.POST("/:id/comments", handler.CreateComment)
+		authenticatedUser.PUT("/:id/comments/:commentId", handler.UpdateComment)
+		authenticatedUser.DELETE("/:id/comments/:commentId", handler.DeleteComment)
+		authenticatedUser.POST("/:id/support", handler.SupportIssue)
+		authenticatedUser.DELETE("/:id/support", handler.UnsupportIssue)
+		authenticatedUser.POST("/:id/images", handler.AddIssueImages)
+		authenticatedUser.PUT("/:id/images", handler.ReorderIssueImages)
+		authenticatedUser.DELETE("/:id/images/:imageId", handler.DeleteIssueImage)
+
+	}
 
+	// Current user - Authenticated routes
+	me := api.Group("/me")
+	me.Use(auth.AuthMiddleware())
+	{
+		me.GET("/issues", handler.ListMyIssues)
+		me.PUT("/password", handler.ChangePassword)
+		me.DELETE("", handler.DeleteAccount)
+		me.POST("/2fa", handler.StartTwoFactorEnrollment)
+		me.POST("/2fa/confirm", handler.ConfirmTwoFactorEnrollment)
+		me.DELETE("/2fa", handler.DisableTwoFactor)
 	}
 
-	// Issues - Staff Protected routes
+	// Issues - Staff routes, authorized per permission
 	staff := api.Group("/issues")
-	staff.Use(auth.AuthMiddleware(), auth.StaffOnly())
+	staff.Use(auth.AuthMiddleware())
 	{
-		staff.PUT("/:id", handler.UpdateIssue)
-		staff.GET("", handler.ListIssues)
-		staff.GET("/search", handler.SearchIssues)
-		staff.GET("/analytics", handler.GetIssueAnalytics)
+		staff.PUT("/:id", auth.RequirePermission(models.PermIssuesUpdate, models.PermIssuesUpdateAssigned), handler.UpdateIssue)
+		staff.GET("/:id/history", auth.RequirePermission(models.PermIssuesRead), handler.GetIssueHistory)
+		staff.POST("/:id/merge", auth.RequirePermission(models.PermIssuesMerge), handler.MergeIssues)
+		staff.POST("/:id/auto-assign", auth.RequirePermission(models.PermIssuesAssign), handler.AutoAssignIssue)
+		staff.DELETE("/:id/location-review", auth.RequirePermission(models.PermIssuesUpdate), handler.ResolveLocationReview)
+		staff.GET("", auth.RequirePermission(models.PermIssuesRead), handler.ListIssues)
+		staff.GET("/search", auth.RequirePermission(models.PermIssuesRead), handler.SearchIssues)
+		staff.GET("/overdue", auth.RequirePermission(models.PermIssuesRead), handler.ListOverdueIssues)
+		staff.GET("/analytics", auth.RequirePermission(models.PermAnalyticsRead), handler.GetIssueAnalytics)
 	}
 
-	// Engineers - Staff Protected routes
+	// Engineers - Staff routes, authorized per permission
 	engineers := api.Group("/engineers")
-	engineers.Use(auth.AuthMiddleware(), auth.StaffOnly())
+	engineers.Use(auth.AuthMiddleware())
+	{
+		engineers.GET("", auth.RequirePermission(models.PermEngineersRead), handler.ListEngineers)
+		engineers.GET("/:id", auth.RequirePermission(models.PermEngineersRead), handler.GetEngineer)
+		engineers.POST("", auth.RequirePermission(models.PermEngineersManage), handler.CreateEngineer)
+		engineers.PUT("/:id", auth.RequirePermission(models.PermEngineersManage), handler.UpdateEngineer)
+		engineers.DELETE("/:id", auth.RequirePermission(models.PermEngineersManage), handler.DeleteEngineer)
+	}
+
+	// Engineer work queue - Engineer routes
+	engineerWork := api.Group("/engineer/me/issues")
+	engineerWork.Use(auth.AuthMiddleware(), auth.RequirePermission(models.PermIssuesUpdateAssigned))
+	{
+		engineerWork.GET("", handler.ListMyAssignedIssues)
+		engineerWork.PATCH("/:id/start", handler.StartIssueWork)
+		engineerWork.PATCH("/:id/resolve", handler.ResolveIssueWork)
+	}
+
+	// Users - Admin routes
+	users := api.Group("/users")
+	users.Use(auth.AuthMiddleware(), auth.RequirePermission(models.PermUsersManage))
+	{
+		users.PUT("/:username/role", handler.UpdateUserRole)
+		users.DELETE("/:username/sessions", handler.RevokeUserSessions)
+		users.GET("/lockouts", handler.ListAccountLockouts)
+		users.DELETE("/:username/lockout", handler.UnlockAccount)
+		users.DELETE("/:username/2fa", handler.ResetUserTwoFactor)
+	}
+
+	// Staff invites - Admin routes
+	invites := api.Group("/invites")
+	invites.Use(auth.AuthMiddleware(), auth.RequirePermission(models.PermUsersManage))
 	{
-		engineers.GET("", handler.ListEngineers)
-		engineers.GET("/:id", handler.GetEngineer)
+		invites.POST("", handler.CreateStaffInvite)
+		invites.GET("", handler.ListStaffInvites)
+		invites.DELETE("/:id", handler.DeleteStaffInvite)
 	}
 
-	// Analytics - Staff Protected routes
+	// Analytics - Supervisor routes
 	analytics := api.Group("/analytics")
-	analytics.Use(auth.AuthMiddleware(), auth.StaffOnly())
+	analytics.Use(auth.AuthMiddleware(), auth.RequirePermission(models.PermAnalyticsRead))
 	{
 		analytics.GET("/engineers", handler.EngineerPerformance)
 		analytics.GET("/resolution-time", handler.ResolutionTime)
+		analytics.GET("/time-in-status", handler.TimeInStatus)
 	}
 }
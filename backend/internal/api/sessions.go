@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// issueTokens starts a new session: a short-lived access token and a refresh token
+// stored hashed in the database
+func (h *Handler) issueTokens(username, userType string) (gin.H, error) {
+	tokenID, err := middleware.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := middleware.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.RefreshToken{
+		Username:        username,
+		TokenHash:       refreshHash,
+		AccessJTI:       tokenID,
+		AccessExpiresAt: now.Add(middleware.AccessTokenTTL),
+		ExpiresAt:       now.Add(middleware.RefreshTokenTTL),
+	}
+	if err := h.db.CreateRefreshToken(session); err != nil {
+		return nil, err
+	}
+
+	token, err := middleware.SignAccessToken(username, userType, tokenID, session.AccessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return tokenResponse(token, refreshToken, session.AccessExpiresAt), nil
+}
+
+func tokenResponse(token, refreshToken string, expiresAt time.Time) gin.H {
+	return gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_at":    expiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once; reusing one revokes all of the user's sessions.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{refresh_token=string} true "Refresh token"
+// @Success 200 {object} map[string]string
+// @Failure 400,401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tokenID, err := middleware.NewTokenID()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+	refreshToken, refreshHash, err := middleware.NewRefreshToken()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	now := time.Now()
+	next := &models.RefreshToken{
+		TokenHash:       refreshHash,
+		AccessJTI:       tokenID,
+		AccessExpiresAt: now.Add(middleware.AccessTokenTTL),
+		ExpiresAt:       now.Add(middleware.RefreshTokenTTL),
+	}
+
+	user, err := h.db.RotateRefreshToken(middleware.HashToken(req.RefreshToken), next)
+	if errors.Is(err, database.ErrRefreshTokenReused) {
+		log.Printf("Refresh token reused; all sessions revoked")
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid refresh token", nil)
+		return
+	}
+	if errors.Is(err, database.ErrRefreshTokenInvalid) {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid refresh token", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to refresh token", err)
+		return
+	}
+
+	token, err := middleware.SignAccessToken(user.Username, user.UserType, tokenID, next.AccessExpiresAt)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(token, refreshToken, next.AccessExpiresAt))
+}
+
+// @Summary Log out
+// @Description Revoke the current access token and, if given, the refresh token of this session
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{refresh_token=string} false "Refresh token of this session"
+// @Success 200 {object} map[string]string
+// @Failure 400,401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	tokenID := c.GetString("tokenID")
+	expiresAt := c.GetTime("tokenExpiresAt")
+	if tokenID != "" && !expiresAt.IsZero() {
+		if err := h.db.RevokeToken(tokenID, expiresAt); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to log out", err)
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		// An unknown or already revoked refresh token leaves nothing more to do
+		err := h.db.RevokeRefreshToken(userID.(string), middleware.HashToken(req.RefreshToken))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to log out", err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// @Summary Revoke all sessions of a user
+// @Description Log a user out everywhere, e.g. after a lost device. Their refresh tokens and the access tokens issued with them stop working immediately.
+// @Tags auth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]int
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /users/{username}/sessions [delete]
+func (h *Handler) RevokeUserSessions(c *gin.Context) {
+	username := c.Param("username")
+	count, err := h.db.RevokeAllSessions(username)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to revoke sessions", err)
+		return
+	}
+
+	actor, _ := c.Get("userID")
+	log.Printf("All sessions of %s revoked by %v (%d active)", username, actor, count)
+
+	c.JSON(http.StatusOK, gin.H{"revoked_sessions": count})
+}
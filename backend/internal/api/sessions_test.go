@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefreshToken(t *testing.T) {
+	cleanup := setupAuthTest()
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	router := gin.New()
+	router.POST("/api/auth/refresh", handler.RefreshToken)
+
+	send := func(payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/auth/refresh", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Rotates the refresh token", func(t *testing.T) {
+		var next *models.RefreshToken
+		mockDB.EXPECT().RotateRefreshToken(middleware.HashToken("old-refresh-token"), gomock.Any()).
+			DoAndReturn(func(_ string, token *models.RefreshToken) (*models.User, error) {
+				next = token
+				return &models.User{Username: "staff_user", UserType: "staff"}, nil
+			})
+
+		w := send(`{"refresh_token": "old-refresh-token"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEqual(t, "old-refresh-token", response["refresh_token"])
+		assert.Equal(t, middleware.HashToken(response["refresh_token"]), next.TokenHash)
+
+		// The access token carries the ID stored with the new refresh token
+		claims := &middleware.UserClaims{}
+		_, err := jwt.ParseWithClaims(response["token"], claims, func(t *jwt.Token) (interface{}, error) {
+			return []byte("test_secret_key_for_testing"), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "staff_user", claims.UserID)
+		assert.Equal(t, "staff", claims.UserType)
+		assert.Equal(t, next.AccessJTI, claims.ID)
+		assert.WithinDuration(t, time.Now().Add(middleware.AccessTokenTTL), claims.ExpiresAt.Time, time.Minute)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockDB.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any()).Return(nil, database.ErrRefreshTokenInvalid)
+
+		w := send(`{"refresh_token": "unknown"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Reused token", func(t *testing.T) {
+		mockDB.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any()).Return(nil, database.ErrRefreshTokenReused)
+
+		w := send(`{"refresh_token": "old-refresh-token"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+		w := send(`{"refresh_token": "old-refresh-token"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		w := send(`{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestRefreshTokenRateLimit(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+
+	send := func(path string) int {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Refreshing isn't held to the login limit of ~5 requests a minute
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusBadRequest, send("/api/auth/refresh"))
+	}
+
+	codes := make([]int, 0, 10)
+	for i := 0; i < 10; i++ {
+		codes = append(codes, send("/api/auth/password-reset"))
+	}
+	assert.Contains(t, codes, http.StatusTooManyRequests)
+}
+
+func TestLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	expiresAt := time.Now().Add(10 * time.Minute)
+	router := gin.New()
+	router.POST("/api/auth/logout", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", "public")
+		c.Set("tokenID", "access-jti")
+		c.Set("tokenExpiresAt", expiresAt)
+		c.Next()
+	}, handler.Logout)
+
+	send := func(payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/auth/logout", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Revokes access and refresh token", func(t *testing.T) {
+		mockDB.EXPECT().RevokeToken("access-jti", expiresAt).Return(nil)
+		mockDB.EXPECT().RevokeRefreshToken("resident", middleware.HashToken("refresh-token")).Return(nil)
+
+		w := send(`{"refresh_token": "refresh-token"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Access token only", func(t *testing.T) {
+		mockDB.EXPECT().RevokeToken("access-jti", expiresAt).Return(nil)
+
+		w := send("")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().RevokeToken("access-jti", expiresAt).Return(errors.New("db error"))
+
+		w := send("")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	mockDB.EXPECT().RevokeAllSessions("lost_laptop_user").Return(3, nil)
+
+	req, _ := http.NewRequest("DELETE", "/api/users/lost_laptop_user/sessions", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked_sessions": 3}`, w.Body.String())
+
+	router = setupUnauthorizedRouter(t)
+	req, _ = http.NewRequest("DELETE", "/api/users/lost_laptop_user/sessions", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List overdue issues
+// @Description Get open issues that have passed their SLA due date, most overdue first
+// @Tags issues
+// @Produce json
+// @Success 200 {array} models.Issue
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/overdue [get]
+func (h *Handler) ListOverdueIssues(c *gin.Context) {
+	issues, err := h.db.ListOverdueIssues()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list overdue issues", err)
+		return
+	}
+	if err := h.presignIssues(c.Request.Context(), issues); err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list overdue issues", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, issues)
+}
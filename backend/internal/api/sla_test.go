@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListOverdueIssues(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	t.Run("Success", func(t *testing.T) {
+		dueAt := time.Now().Add(-48 * time.Hour)
+		mockDB.EXPECT().ListOverdueIssues().Return([]*models.Issue{
+			{ID: 3, Type: models.TypeBlockedDrain, Status: models.StatusAssigned, Priority: models.PriorityHigh, DueAt: &dueAt},
+		}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/issues/overdue", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var issues []models.Issue
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &issues))
+		assert.Len(t, issues, 1)
+		assert.Equal(t, models.PriorityHigh, issues[0].Priority)
+		assert.NotNil(t, issues[0].DueAt)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().ListOverdueIssues().Return(nil, errors.New("database error"))
+
+		req, _ := http.NewRequest("GET", "/api/issues/overdue", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestListOverdueIssuesNonStaff(t *testing.T) {
+	router := setupUnauthorizedRouter(t)
+
+	req, _ := http.NewRequest("GET", "/api/issues/overdue", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
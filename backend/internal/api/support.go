@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Support an issue
+// @Description Register that the authenticated resident is also affected by an open issue
+// @Tags issues
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} map[string]int
+// @Failure 400,403,404,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/support [post]
+func (h *Handler) SupportIssue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	// Support is for residents; staff prioritise issues directly
+	if !middleware.HasPermission(c, models.PermIssuesSupport) {
+		utils.RespondWithError(c, http.StatusForbidden, "Only residents can support issues", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if !models.IsOpenStatus(issue.Status) {
+		utils.RespondWithError(c, http.StatusConflict, "Issue is no longer open", nil)
+		return
+	}
+
+	count, err := h.db.AddIssueSupporter(id, userID.(string))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to support issue", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"supporter_count": count})
+}
+
+// @Summary Withdraw support for an issue
+// @Description Remove the authenticated user's support for an issue
+// @Tags issues
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} map[string]int
+// @Failure 400,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /issues/{id}/support [delete]
+func (h *Handler) UnsupportIssue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Unauthorized access", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+
+	count, err := h.db.RemoveIssueSupporter(id, userID.(string))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to withdraw support", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"supporter_count": count})
+}
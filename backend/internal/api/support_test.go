@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSupportIssue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	router := gin.New()
+	router.POST("/api/issues/:id/support", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", "public")
+		c.Next()
+	}, handler.SupportIssue)
+	router.DELETE("/api/issues/:id/support", func(c *gin.Context) {
+		c.Set("userID", "resident")
+		c.Set("userType", "public")
+		c.Next()
+	}, handler.UnsupportIssue)
+	router.POST("/api/staff/issues/:id/support", func(c *gin.Context) {
+		c.Set("userID", "staff_user")
+		c.Set("userType", "staff")
+		c.Next()
+	}, handler.SupportIssue)
+	router.POST("/api/no-auth/issues/:id/support", handler.SupportIssue)
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Support", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().AddIssueSupporter(int64(1), "resident").Return(4, nil)
+
+		w := send("POST", "/api/issues/1/support")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]int
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 4, response["supporter_count"])
+	})
+
+	t.Run("Withdraw support", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().RemoveIssueSupporter(int64(1), "resident").Return(3, nil)
+
+		w := send("DELETE", "/api/issues/1/support")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"supporter_count": 3}`, w.Body.String())
+	})
+
+	t.Run("Closed issue", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(2)).Return(&models.Issue{ID: 2, Status: models.StatusClosed}, nil)
+
+		w := send("POST", "/api/issues/2/support")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		w := send("POST", "/api/issues/99/support")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := send("POST", "/api/issues/abc/support")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Staff cannot support", func(t *testing.T) {
+		w := send("POST", "/api/staff/issues/1/support")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		w := send("POST", "/api/no-auth/issues/1/support")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusNew}, nil)
+		mockDB.EXPECT().AddIssueSupporter(int64(1), "resident").Return(0, errors.New("database error"))
+
+		w := send("POST", "/api/issues/1/support")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/totp"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTOTPIssuer = "Chalkstone Council"
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+// LoadTOTPIssuer reads TOTP_ISSUER, the name authenticator apps show next to the
+// account, falling back to the council's name
+func LoadTOTPIssuer() string {
+	if issuer := strings.TrimSpace(os.Getenv("TOTP_ISSUER")); issuer != "" {
+		return issuer
+	}
+	return defaultTOTPIssuer
+}
+
+// newRecoveryCodes returns single-use recovery codes formatted as xxxxx-xxxxx, and the
+// hashes to store in their place
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b)[:recoveryCodeLength])
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, middleware.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code the way it was stored, ignoring case, spaces
+// and hyphens
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return middleware.HashToken(code)
+}
+
+// respondTwoFactorChallenge asks the client for the user's second factor instead of
+// issuing tokens. Users who must use two-factor authentication but haven't set it up
+// yet have to enrol before they can finish logging in.
+func respondTwoFactorChallenge(c *gin.Context, username string, enrolled bool) {
+	challenge, expiresAt, err := middleware.GenerateChallengeToken(username)
+	if err != nil {
+		log.Printf("Generate challenge token error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"enrollment_required": !enrolled,
+		"challenge_token":     challenge,
+		"expires_at":          expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// startTOTPEnrollment creates a pending secret for the user and responds with it and
+// the provisioning URI to show as a QR code
+func (h *Handler) startTOTPEnrollment(c *gin.Context, username string) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start enrolment", err)
+		return
+	}
+
+	err = h.db.StartTOTPEnrollment(username, secret)
+	if errors.Is(err, database.ErrTwoFactorEnabled) {
+		utils.RespondWithError(c, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to start enrolment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(h.totpIssuer, username, secret),
+	})
+}
+
+// confirmTOTPEnrollment enables a pending enrolment if the code matches its secret and
+// returns the user's new recovery codes. It returns nil codes if the code is wrong.
+func (h *Handler) confirmTOTPEnrollment(enrollment *models.TOTPEnrollment, code string) ([]string, error) {
+	step, ok := totp.Verify(enrollment.Secret, code, time.Now())
+	if !ok {
+		return nil, nil
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.db.ConfirmTOTPEnrollment(enrollment.Username, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkSecondFactor verifies a TOTP code or recovery code of a user with a confirmed
+// enrolment. Every code is only accepted once.
+func (h *Handler) checkSecondFactor(enrollment *models.TOTPEnrollment, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		err := h.db.UseRecoveryCode(enrollment.Username, hashRecoveryCode(recoveryCode))
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		log.Printf("AUDIT recovery code used: username=%q", enrollment.Username)
+		return true, nil
+	}
+
+	step, ok := totp.Verify(enrollment.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	err := h.db.UseTOTPStep(enrollment.Username, step)
+	if errors.Is(err, database.ErrTOTPCodeReused) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// @Summary Complete two-factor login
+// @Description Exchange the challenge token returned by login, together with a TOTP code or a recovery code, for an access token and a refresh token. Staff who haven't enrolled yet confirm their enrolment with their first code here and receive their recovery codes. Wrong codes count as failed logins.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{challenge_token=string,code=string,recovery_code=string} true "Challenge token and second factor"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401 {object} map[string]string
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa [post]
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		utils.RespondWithError(c, http.StatusBadRequest, "Provide either a code or a recovery code", nil)
+		return
+	}
+
+	username, err := middleware.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired challenge token", nil)
+		return
+	}
+
+	attempts, err := h.db.GetLoginAttempts(username)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process login", err)
+		return
+	}
+	if retryAt := h.loginPolicy.RetryAt(attempts); time.Now().Before(retryAt) {
+		respondLoginThrottled(c, retryAt)
+		return
+	}
+
+	user, err := h.db.GetUserByUsername(username)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired challenge token", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process login", err)
+		return
+	}
+
+	enrollment, err := h.db.GetTOTPEnrollment(username)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process login", err)
+		return
+	}
+	if enrollment == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Two-factor authentication is not set up, enrol first", nil)
+		return
+	}
+
+	var recoveryCodes []string
+	var ok bool
+	if enrollment.Enabled() {
+		ok, err = h.checkSecondFactor(enrollment, req.Code, req.RecoveryCode)
+	} else if req.Code != "" {
+		recoveryCodes, err = h.confirmTOTPEnrollment(enrollment, req.Code)
+		ok = recoveryCodes != nil
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to process login", err)
+		return
+	}
+	if !ok {
+		log.Printf("Two-factor login failed for %q", username)
+		h.recordFailedLogin(username)
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid code", nil)
+		return
+	}
+
+	if attempts != nil {
+		if err := h.db.ClearLoginAttempts(username); err != nil {
+			log.Printf("Clear login attempts error: %v", err)
+		}
+	}
+
+	tokens, err := h.issueTokens(user.Username, user.UserType)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to generate token", err)
+		return
+	}
+	if recoveryCodes != nil {
+		tokens["recovery_codes"] = recoveryCodes
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Enrol in two-factor authentication during login
+// @Description Start enrolment with the challenge token returned by login, for staff who must use two-factor authentication but haven't set it up. Returns the secret and an otpauth:// URI to show as a QR code; the first code is then sent to /auth/2fa.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body object{challenge_token=string} true "Challenge token"
+// @Success 200 {object} map[string]string
+// @Failure 400,401,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/enroll [post]
+func (h *Handler) EnrollTwoFactorWithChallenge(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	username, err := middleware.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusUnauthorized, "Invalid or expired challenge token", nil)
+		return
+	}
+
+	h.startTOTPEnrollment(c, username)
+}
+
+// @Summary Start two-factor enrolment
+// @Description Create a new TOTP secret for the authenticated user. Returns the secret and an otpauth:// URI to show as a QR code. Two-factor authentication is enabled once a code is confirmed.
+// @Tags account
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 401,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me/2fa [post]
+func (h *Handler) StartTwoFactorEnrollment(c *gin.Context) {
+	h.startTOTPEnrollment(c, c.GetString("userID"))
+}
+
+// @Summary Confirm two-factor enrolment
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns single-use recovery codes, which are only shown once.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body object{code=string} true "Code from the authenticator app"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me/2fa/confirm [post]
+func (h *Handler) ConfirmTwoFactorEnrollment(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	enrollment, err := h.db.GetTOTPEnrollment(c.GetString("userID"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to confirm enrolment", err)
+		return
+	}
+	if enrollment == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Start enrolment first", nil)
+		return
+	}
+	if enrollment.Enabled() {
+		utils.RespondWithError(c, http.StatusConflict, "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	recoveryCodes, err := h.confirmTOTPEnrollment(enrollment, req.Code)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to confirm enrolment", err)
+		return
+	}
+	if recoveryCodes == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid code", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Remove the authenticated user's authenticator app and recovery codes. Staff accounts can't disable two-factor authentication.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param body body object{password=string} true "Current password"
+// @Success 200 {object} map[string]string
+// @Failure 400,401,403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /me/2fa [delete]
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user := h.checkCurrentPassword(c, req.Password)
+	if user == nil {
+		return
+	}
+	if models.RequiresTwoFactor(user.UserType) {
+		utils.RespondWithError(c, http.StatusForbidden, "Two-factor authentication is required for staff accounts", nil)
+		return
+	}
+
+	err := h.db.DisableTOTP(user.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Two-factor authentication is not enabled", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// @Summary Reset two-factor authentication of a user
+// @Description Remove a user's authenticator app and recovery codes, e.g. after a lost phone. Staff have to enrol again at their next login.
+// @Tags users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]string
+// @Failure 403,404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /users/{username}/2fa [delete]
+func (h *Handler) ResetUserTwoFactor(c *gin.Context) {
+	username := c.Param("username")
+	actor := c.GetString("userID")
+
+	err := h.db.DisableTOTP(username)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "Two-factor authentication is not enabled", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+		return
+	}
+
+	log.Printf("AUDIT two-factor reset: username=%q by=%q", username, actor)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset successfully"})
+}
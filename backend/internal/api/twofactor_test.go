@@ -0,0 +1,274 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/totp"
+	"chalkstone.council/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// setupTwoFactorTestRouter registers the two-factor routes, with "jane" as the
+// authenticated user of the /me routes
+func setupTwoFactorTestRouter(t *testing.T, role string) (*gin.Engine, *dbMock.MockDatabaseOperations) {
+	gin.SetMode(gin.TestMode)
+	middleware.SetSecretKeyForTesting("test-secret")
+	ctrl := gomock.NewController(t)
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB, loginPolicy: testLoginPolicy, totpIssuer: defaultTOTPIssuer}
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/auth/login", handler.Login)
+	api.POST("/auth/2fa", handler.VerifyTwoFactor)
+	api.POST("/auth/2fa/enroll", handler.EnrollTwoFactorWithChallenge)
+
+	authenticated := api.Group("")
+	authenticated.Use(func(c *gin.Context) {
+		c.Set("userID", "jane")
+		c.Set("userType", role)
+		c.Next()
+	})
+	authenticated.POST("/me/2fa", handler.StartTwoFactorEnrollment)
+	authenticated.POST("/me/2fa/confirm", handler.ConfirmTwoFactorEnrollment)
+	authenticated.DELETE("/me/2fa", handler.DisableTwoFactor)
+	authenticated.DELETE("/users/:username/2fa", handler.ResetUserTwoFactor)
+
+	return router, mockDB
+}
+
+func currentCode(t *testing.T, secret string) string {
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+	return code
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestLoginTwoFactorChallenge(t *testing.T) {
+	router, mockDB := setupTwoFactorTestRouter(t, models.RoleResident)
+	hash, _ := utils.HashPassword("password")
+	confirmedAt := time.Now()
+
+	t.Run("Staff without enrolment", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("dispatch").Return(nil, nil)
+		mockDB.EXPECT().GetUserByUsername("dispatch").Return(&models.User{Username: "dispatch", PasswordHash: hash, UserType: models.RoleDispatcher}, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("dispatch").Return(nil, nil)
+
+		w := sendLogin(router, "dispatch", "password")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		response := decodeBody(t, w)
+		assert.NotContains(t, response, "token")
+		assert.Equal(t, true, response["enrollment_required"])
+		username, err := middleware.ParseChallengeToken(response["challenge_token"].(string))
+		assert.NoError(t, err)
+		assert.Equal(t, "dispatch", username)
+	})
+
+	t.Run("Resident with two-factor enabled", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(&models.LoginAttempts{Username: "jane", FailedCount: 1, LastFailedAt: time.Now()}, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(&models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleResident}, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(&models.TOTPEnrollment{Username: "jane", ConfirmedAt: &confirmedAt}, nil)
+
+		// Failed attempts aren't cleared until the second factor is accepted
+		w := sendLogin(router, "jane", "password")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		response := decodeBody(t, w)
+		assert.NotContains(t, response, "token")
+		assert.Equal(t, false, response["enrollment_required"])
+	})
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	router, mockDB := setupTwoFactorTestRouter(t, models.RoleResident)
+	secret, _ := totp.GenerateSecret()
+	confirmedAt := time.Now()
+	enabled := &models.TOTPEnrollment{Username: "jane", Secret: secret, ConfirmedAt: &confirmedAt}
+	user := &models.User{Username: "jane", UserType: models.RoleResident}
+	challenge, _, _ := middleware.GenerateChallengeToken("jane")
+
+	send := func(payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		return sendAccountRequest(router, "POST", "/api/auth/2fa", string(body))
+	}
+
+	t.Run("Valid code", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(&models.LoginAttempts{Username: "jane", FailedCount: 1, LastFailedAt: time.Now()}, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(enabled, nil)
+		mockDB.EXPECT().UseTOTPStep("jane", gomock.Any()).Return(nil)
+		mockDB.EXPECT().ClearLoginAttempts("jane").Return(nil)
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		w := send(map[string]string{"challenge_token": challenge, "code": currentCode(t, secret)})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, decodeBody(t, w)["token"])
+	})
+
+	t.Run("Wrong code counts as a failed login", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(nil, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(enabled, nil)
+		mockDB.EXPECT().RecordFailedLogin("jane", testLoginPolicy).Return(&models.LoginAttempts{Username: "jane", FailedCount: 1}, false, nil)
+
+		code := "000000"
+		if code == currentCode(t, secret) {
+			code = "111111"
+		}
+		w := send(map[string]string{"challenge_token": challenge, "code": code})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Replayed code", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(nil, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(enabled, nil)
+		mockDB.EXPECT().UseTOTPStep("jane", gomock.Any()).Return(database.ErrTOTPCodeReused)
+		mockDB.EXPECT().RecordFailedLogin("jane", testLoginPolicy).Return(&models.LoginAttempts{Username: "jane", FailedCount: 1}, false, nil)
+
+		w := send(map[string]string{"challenge_token": challenge, "code": currentCode(t, secret)})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(nil, nil)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(user, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(enabled, nil)
+		mockDB.EXPECT().UseRecoveryCode("jane", middleware.HashToken("abcdefghij")).Return(nil)
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		w := send(map[string]string{"challenge_token": challenge, "recovery_code": "ABCDE-FGHIJ"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("First code confirms a pending enrolment", func(t *testing.T) {
+		staff := &models.User{Username: "dispatch", UserType: models.RoleDispatcher}
+		pending := &models.TOTPEnrollment{Username: "dispatch", Secret: secret}
+		staffChallenge, _, _ := middleware.GenerateChallengeToken("dispatch")
+
+		mockDB.EXPECT().GetLoginAttempts("dispatch").Return(nil, nil)
+		mockDB.EXPECT().GetUserByUsername("dispatch").Return(staff, nil)
+		mockDB.EXPECT().GetTOTPEnrollment("dispatch").Return(pending, nil)
+		mockDB.EXPECT().ConfirmTOTPEnrollment("dispatch", gomock.Any(), gomock.Len(recoveryCodeCount)).Return(nil)
+		mockDB.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		w := send(map[string]string{"challenge_token": staffChallenge, "code": currentCode(t, secret)})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		response := decodeBody(t, w)
+		assert.NotEmpty(t, response["token"])
+		assert.Len(t, response["recovery_codes"], recoveryCodeCount)
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		lockedUntil := time.Now().Add(time.Minute)
+		mockDB.EXPECT().GetLoginAttempts("jane").Return(&models.LoginAttempts{Username: "jane", FailedCount: 10, LockedUntil: &lockedUntil}, nil)
+
+		w := send(map[string]string{"challenge_token": challenge, "code": currentCode(t, secret)})
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("Access token instead of challenge", func(t *testing.T) {
+		access, _ := middleware.GenerateToken("jane", models.RoleResident)
+
+		w := send(map[string]string{"challenge_token": access, "code": "123456"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Code and recovery code together", func(t *testing.T) {
+		w := send(map[string]string{"challenge_token": challenge, "code": "123456", "recovery_code": "abcde-fghij"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	router, mockDB := setupTwoFactorTestRouter(t, models.RoleResident)
+
+	t.Run("Start during login", func(t *testing.T) {
+		challenge, _, _ := middleware.GenerateChallengeToken("dispatch")
+		mockDB.EXPECT().StartTOTPEnrollment("dispatch", gomock.Any()).Return(nil)
+
+		w := sendAccountRequest(router, "POST", "/api/auth/2fa/enroll", `{"challenge_token": "`+challenge+`"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		response := decodeBody(t, w)
+		assert.NotEmpty(t, response["secret"])
+		assert.Contains(t, response["provisioning_uri"], "otpauth://totp/")
+	})
+
+	t.Run("Start while already enabled", func(t *testing.T) {
+		mockDB.EXPECT().StartTOTPEnrollment("jane", gomock.Any()).Return(database.ErrTwoFactorEnabled)
+
+		w := sendAccountRequest(router, "POST", "/api/me/2fa", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		secret, _ := totp.GenerateSecret()
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(&models.TOTPEnrollment{Username: "jane", Secret: secret}, nil)
+		mockDB.EXPECT().ConfirmTOTPEnrollment("jane", gomock.Any(), gomock.Any()).Return(nil)
+
+		w := sendAccountRequest(router, "POST", "/api/me/2fa/confirm", `{"code": "`+currentCode(t, secret)+`"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, decodeBody(t, w)["recovery_codes"], recoveryCodeCount)
+	})
+
+	t.Run("Confirm with wrong code", func(t *testing.T) {
+		secret, _ := totp.GenerateSecret()
+		mockDB.EXPECT().GetTOTPEnrollment("jane").Return(&models.TOTPEnrollment{Username: "jane", Secret: secret}, nil)
+
+		w := sendAccountRequest(router, "POST", "/api/me/2fa/confirm", `{"code": "12345"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	hash, _ := utils.HashPassword("password")
+
+	t.Run("Resident", func(t *testing.T) {
+		router, mockDB := setupTwoFactorTestRouter(t, models.RoleResident)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(&models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleResident}, nil)
+		mockDB.EXPECT().DisableTOTP("jane").Return(nil)
+
+		w := sendAccountRequest(router, "DELETE", "/api/me/2fa", `{"password": "password"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Staff can't opt out", func(t *testing.T) {
+		router, mockDB := setupTwoFactorTestRouter(t, models.RoleEngineer)
+		mockDB.EXPECT().GetUserByUsername("jane").Return(&models.User{Username: "jane", PasswordHash: hash, UserType: models.RoleEngineer}, nil)
+
+		w := sendAccountRequest(router, "DELETE", "/api/me/2fa", `{"password": "password"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Admin reset", func(t *testing.T) {
+		router, mockDB := setupTwoFactorTestRouter(t, models.RoleAdmin)
+		mockDB.EXPECT().DisableTOTP("lost_phone").Return(nil)
+		mockDB.EXPECT().DisableTOTP("nobody").Return(sql.ErrNoRows)
+
+		w := sendAccountRequest(router, "DELETE", "/api/users/lost_phone/2fa", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = sendAccountRequest(router, "DELETE", "/api/users/nobody/2fa", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
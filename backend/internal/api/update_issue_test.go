@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"chalkstone.council/internal/database"
 	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/middleware"
 	"chalkstone.council/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -35,7 +37,7 @@ func TestUpdateIssueNonStaffAccess(t *testing.T) {
 		c.Set("userType", "public") // Non-staff user
 		c.Next()
 	})
-	api.PUT("/issues/:id", handler.UpdateIssue)
+	api.PUT("/issues/:id", middleware.RequirePermission(models.PermIssuesUpdate, models.PermIssuesUpdateAssigned), handler.UpdateIssue)
 	
 	// Create a sample update payload
 	status := models.StatusInProgress
@@ -279,6 +281,11 @@ func TestUpdateIssueDBUpdateError(t *testing.T) {
 	
 	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
 	
+	// Mock the GetIssue call used to check the status transition
+	mockDB.EXPECT().
+		GetIssue(int64(1)).
+		Return(&models.Issue{ID: 1, Status: models.StatusAssigned}, nil)
+
 	// Mock the UpdateIssue call to return an error
 	mockDB.EXPECT().
 		UpdateIssue(gomock.Any(), gomock.Any()).
@@ -332,6 +339,7 @@ func TestUpdateIssueSuccess(t *testing.T) {
 		ID:             engineerID,
 		Name:           "Test Engineer",
 		Specialization: "Pothole Repair",
+		Active:         true,
 	}
 	
 	// Mock the GetEngineerByID call to return the valid engineer
@@ -339,6 +347,11 @@ func TestUpdateIssueSuccess(t *testing.T) {
 		GetEngineerByID(engineerID).
 		Return(engineer, nil)
 	
+	// Mock the GetIssue call used to check the status transition
+	mockDB.EXPECT().
+		GetIssue(int64(1)).
+		Return(&models.Issue{ID: 1, Status: models.StatusAssigned}, nil)
+	
 	// Mock the UpdateIssue call to succeed
 	mockDB.EXPECT().
 		UpdateIssue(int64(1), gomock.Any()).
@@ -346,6 +359,7 @@ func TestUpdateIssueSuccess(t *testing.T) {
 			// Validate update has expected values
 			assert.Equal(t, models.StatusInProgress, *update.Status)
 			assert.Equal(t, engineerID, *update.AssignedTo)
+			assert.Equal(t, "test_staff", update.UpdatedBy)
 			return nil
 		})
 	
@@ -388,3 +402,275 @@ func TestUpdateIssueSuccess(t *testing.T) {
 	assert.Contains(t, response, "message")
 	assert.Equal(t, "Issue updated successfully", response["message"])
 }
+
+func TestUpdateIssueInvalidTransition(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	
+	// Mock the GetIssue call to return a resolved issue
+	mockDB.EXPECT().
+		GetIssue(int64(1)).
+		Return(&models.Issue{ID: 1, Status: models.StatusResolved}, nil)
+	
+	handler := &Handler{
+		db: mockDB,
+	}
+	
+	// Set up routes with staff authentication middleware
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", "test_staff")
+		c.Set("userType", "staff") // Staff user
+		c.Next()
+	})
+	api.PUT("/issues/:id", handler.UpdateIssue)
+	
+	// Try to move a resolved issue back to NEW
+	status := models.StatusNew
+	update := models.IssueUpdate{
+		Status: &status,
+	}
+	body, _ := json.Marshal(update)
+	
+	// Create request
+	req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	
+	// Test
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	// Verify - should return conflict with the legal next states
+	assert.Equal(t, http.StatusConflict, w.Code)
+	
+	var response struct {
+		Error              string               `json:"error"`
+		CurrentStatus      models.IssueStatus   `json:"current_status"`
+		AllowedTransitions []models.IssueStatus `json:"allowed_transitions"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Invalid status transition", response.Error)
+	assert.Equal(t, models.StatusResolved, response.CurrentStatus)
+	assert.Equal(t, []models.IssueStatus{models.StatusClosed, models.StatusReopened}, response.AllowedTransitions)
+}
+
+func TestUpdateIssueConcurrentTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	router := gin.New()
+	router.PUT("/api/issues/:id", func(c *gin.Context) {
+		c.Set("userID", "test_dispatcher")
+		c.Set("userType", models.RoleDispatcher)
+		c.Next()
+	}, handler.UpdateIssue)
+
+	// The issue was reopened by someone else between loading and updating it
+	mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusResolved}, nil)
+	mockDB.EXPECT().UpdateIssue(int64(1), gomock.Any()).
+		Return(&database.TransitionError{From: models.StatusReopened, To: models.StatusClosed})
+
+	req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBufferString(`{"status": "CLOSED"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response struct {
+		CurrentStatus      models.IssueStatus   `json:"current_status"`
+		AllowedTransitions []models.IssueStatus `json:"allowed_transitions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.StatusReopened, response.CurrentStatus)
+	assert.Equal(t, models.AllowedTransitions(models.StatusReopened), response.AllowedTransitions)
+}
+
+func TestUpdateIssueNotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	
+	// Mock the GetIssue call to return no issue
+	mockDB.EXPECT().
+		GetIssue(int64(99)).
+		Return(nil, nil)
+	
+	handler := &Handler{
+		db: mockDB,
+	}
+	
+	// Set up routes with staff authentication middleware
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", "test_staff")
+		c.Set("userType", "staff") // Staff user
+		c.Next()
+	})
+	api.PUT("/issues/:id", handler.UpdateIssue)
+	
+	status := models.StatusTriaged
+	update := models.IssueUpdate{
+		Status: &status,
+	}
+	body, _ := json.Marshal(update)
+	
+	// Create request
+	req, _ := http.NewRequest("PUT", "/api/issues/99", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	
+	// Test
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	// Verify - should return not found
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateIssuePriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{
+		db: mockDB,
+	}
+
+	api := router.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("userID", "test_staff")
+		c.Set("userType", "staff")
+		c.Next()
+	})
+	api.PUT("/issues/:id", handler.UpdateIssue)
+
+	t.Run("Valid priority", func(t *testing.T) {
+		mockDB.EXPECT().
+			UpdateIssue(int64(1), gomock.Any()).
+			Do(func(id int64, update *models.IssueUpdate) {
+				assert.Equal(t, models.PriorityUrgent, *update.Priority)
+				assert.Nil(t, update.Status)
+			}).
+			Return(nil)
+
+		req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBufferString(`{"priority": "URGENT"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid priority", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/api/issues/1", bytes.NewBufferString(`{"priority": "CRITICAL"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Invalid issue priority", response["error"])
+	})
+}
+
+func TestUpdateIssueAsEngineer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+	handler := &Handler{db: mockDB}
+
+	router := gin.New()
+	router.PUT("/api/issues/:id", func(c *gin.Context) {
+		c.Set("userID", "field_engineer")
+		c.Set("userType", models.RoleEngineer)
+		c.Next()
+	}, middleware.RequirePermission(models.PermIssuesUpdate, models.PermIssuesUpdateAssigned), handler.UpdateIssue)
+
+	send := func(id, payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/issues/"+id, bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	engineerID := int64(3)
+	otherEngineerID := int64(4)
+	engineerUser := &models.User{Username: "field_engineer", UserType: models.RoleEngineer, EngineerID: &engineerID}
+
+	t.Run("Own issue", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().GetUserByUsername("field_engineer").Return(engineerUser, nil)
+		mockDB.EXPECT().UpdateIssue(int64(1), gomock.Any()).
+			Do(func(id int64, update *models.IssueUpdate) {
+				assert.Equal(t, &engineerID, update.EngineerID)
+			}).
+			Return(nil)
+
+		w := send("1", `{"status": "IN_PROGRESS"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Reassigned before the update", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().GetUserByUsername("field_engineer").Return(engineerUser, nil)
+		mockDB.EXPECT().UpdateIssue(int64(1), gomock.Any()).Return(database.ErrNotAssigned)
+
+		w := send("1", `{"status": "IN_PROGRESS"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Issue assigned to someone else", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(2)).Return(&models.Issue{ID: 2, Status: models.StatusAssigned, AssignedTo: &otherEngineerID}, nil)
+		mockDB.EXPECT().GetUserByUsername("field_engineer").Return(engineerUser, nil)
+
+		w := send("2", `{"status": "IN_PROGRESS"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Unassigned issue", func(t *testing.T) {
+		mockDB.EXPECT().GetIssue(int64(3)).Return(&models.Issue{ID: 3, Status: models.StatusTriaged}, nil)
+		mockDB.EXPECT().GetUserByUsername("field_engineer").Return(engineerUser, nil)
+
+		w := send("3", `{"status": "IN_PROGRESS"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Closing is not allowed", func(t *testing.T) {
+		w := send("1", `{"status": "CLOSED"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Reassigning is not allowed", func(t *testing.T) {
+		w := send("1", `{"assigned_to": 4}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Changing priority is not allowed", func(t *testing.T) {
+		w := send("1", `{"priority": "LOW"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentEngineerID returns the engineer the authenticated user's account is linked
+// to, or nil if it isn't linked to one
+func (h *Handler) currentEngineerID(c *gin.Context) (*int64, error) {
+	user, err := h.db.GetUserByUsername(c.GetString("userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user.EngineerID, nil
+}
+
+// checkEngineerLink validates the engineer an account with the given role is linked to.
+// Engineer accounts must name an active engineer; other roles must not name one.
+// It writes the error response and returns false if the link is invalid.
+func (h *Handler) checkEngineerLink(c *gin.Context, role string, engineerID *int64) bool {
+	if role != models.RoleEngineer {
+		if engineerID != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "Only engineer accounts can be linked to an engineer", nil)
+			return false
+		}
+		return true
+	}
+
+	if engineerID == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Engineer accounts must be linked to an engineer", nil)
+		return false
+	}
+	engineer, err := h.db.GetEngineerByID(*engineerID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to validate engineer", err)
+		return false
+	}
+	if engineer == nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid engineer ID", nil)
+		return false
+	}
+	if !engineer.Active {
+		utils.RespondWithError(c, http.StatusBadRequest, "Engineer is no longer active", nil)
+		return false
+	}
+	return true
+}
+
+// @Summary Change user role
+// @Description Change the role of a user account. Engineer accounts must be linked to an active engineer. The user's sessions are revoked so the new role applies from their next login.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param role body models.UserRoleUpdate true "New role"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404,409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /users/{username}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	username := c.Param("username")
+
+	var req models.UserRoleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !models.ValidateRole(req.Role) {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid role", nil)
+		return
+	}
+
+	// Keeps at least one admin able to undo the change
+	actor := c.GetString("userID")
+	if username == actor {
+		utils.RespondWithError(c, http.StatusBadRequest, "You can't change your own role", nil)
+		return
+	}
+
+	if !h.checkEngineerLink(c, req.Role, req.EngineerID) {
+		return
+	}
+
+	err := h.db.UpdateUserRole(username, req.Role, req.EngineerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(c, http.StatusNotFound, "User not found", nil)
+		return
+	}
+	if errors.Is(err, database.ErrEngineerAlreadyLinked) {
+		utils.RespondWithError(c, http.StatusConflict, "Engineer is already linked to another user", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update user role", err)
+		return
+	}
+
+	log.Printf("Role of %s changed to %s by %s", username, req.Role, actor)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated successfully"})
+}
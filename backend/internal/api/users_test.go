@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateUserRole(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+
+	send := func(username, payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/users/"+username+"/role", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Promote to dispatcher", func(t *testing.T) {
+		mockDB.EXPECT().UpdateUserRole("jane", models.RoleDispatcher, nil).Return(nil)
+
+		w := send("jane", `{"role": "dispatcher"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Link engineer account", func(t *testing.T) {
+		engineerID := int64(2)
+		mockDB.EXPECT().GetEngineerByID(engineerID).Return(&models.Engineer{ID: engineerID, Active: true}, nil)
+		mockDB.EXPECT().UpdateUserRole("emma", models.RoleEngineer, &engineerID).Return(nil)
+
+		w := send("emma", `{"role": "engineer", "engineer_id": 2}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Engineer already linked", func(t *testing.T) {
+		engineerID := int64(2)
+		mockDB.EXPECT().GetEngineerByID(engineerID).Return(&models.Engineer{ID: engineerID, Active: true}, nil)
+		mockDB.EXPECT().UpdateUserRole("other", models.RoleEngineer, &engineerID).Return(database.ErrEngineerAlreadyLinked)
+
+		w := send("other", `{"role": "engineer", "engineer_id": 2}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Engineer without link", func(t *testing.T) {
+		w := send("emma", `{"role": "engineer"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		w := send("jane", `{"role": "staff"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Own role", func(t *testing.T) {
+		w := send("test_user", `{"role": "resident"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockDB.EXPECT().UpdateUserRole("nobody", models.RoleAdmin, nil).Return(sql.ErrNoRows)
+
+		w := send("nobody", `{"role": "admin"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mockDB.EXPECT().UpdateUserRole("jane", models.RoleAdmin, nil).Return(errors.New("db error"))
+
+		w := send("jane", `{"role": "admin"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Requires admin", func(t *testing.T) {
+		router := setupUnauthorizedRouter(t)
+		req, _ := http.NewRequest("PUT", "/api/users/jane/role", bytes.NewBufferString(`{"role": "admin"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"chalkstone.council/internal/database"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireEngineerID returns the engineer the authenticated account is linked to.
+// It writes the error response and returns false if there is none.
+func (h *Handler) requireEngineerID(c *gin.Context) (int64, bool) {
+	engineerID, err := h.currentEngineerID(c)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get engineer", err)
+		return 0, false
+	}
+	if engineerID == nil {
+		utils.RespondWithError(c, http.StatusForbidden, "Your account is not linked to an engineer", nil)
+		return 0, false
+	}
+	return *engineerID, true
+}
+
+// @Summary List my assigned issues
+// @Description Get the open issues assigned to the authenticated engineer, most urgent first. Issues of the same priority are ordered by distance from the given point, then by due date.
+// @Tags engineer
+// @Produce json
+// @Param near query string false "Current position as lat,lon"
+// @Success 200 {array} models.WorkItem
+// @Failure 400,403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineer/me/issues [get]
+func (h *Handler) ListMyAssignedIssues(c *gin.Context) {
+	var latitude, longitude *float64
+	if near := c.Query("near"); near != "" {
+		values, err := parseFloatList(near, 2)
+		if err != nil || !models.ValidLatitude(values[0]) || !models.ValidLongitude(values[1]) {
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid near, expected lat,lon", err)
+			return
+		}
+		latitude, longitude = &values[0], &values[1]
+	}
+
+	engineerID, ok := h.requireEngineerID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.db.ListEngineerIssues(engineerID, latitude, longitude)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list your issues", err)
+		return
+	}
+	for _, item := range items {
+		if err := h.presignIssue(c.Request.Context(), &item.Issue); err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, "Failed to list your issues", err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// @Summary Start work on an issue
+// @Description Mark an issue assigned to the authenticated engineer as IN_PROGRESS
+// @Tags engineer
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineer/me/issues/{id}/start [patch]
+func (h *Handler) StartIssueWork(c *gin.Context) {
+	h.recordIssueWork(c, models.StatusInProgress)
+}
+
+// @Summary Resolve an issue
+// @Description Mark an issue assigned to the authenticated engineer as RESOLVED, with a note on the fix and optional photos of the finished work
+// @Tags engineer
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param resolution_note formData string true "How the issue was resolved"
+// @Param images formData file false "After photos (multiple allowed)"
+// @Success 200 {object} map[string]string
+// @Failure 400,403,404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Security Bearer
+// @Router /engineer/me/issues/{id}/resolve [patch]
+func (h *Handler) ResolveIssueWork(c *gin.Context) {
+	h.recordIssueWork(c, models.StatusResolved)
+}
+
+// recordIssueWork moves one of the engineer's own issues to the given status
+func (h *Handler) recordIssueWork(c *gin.Context, status models.IssueStatus) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Invalid ID", err)
+		return
+	}
+
+	work := models.IssueWork{Status: status, UpdatedBy: c.GetString("userID")}
+	if status == models.StatusResolved {
+		// Parse multipart form (handle file uploads)
+		if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB limit
+			utils.RespondWithError(c, http.StatusBadRequest, "Invalid form data", err)
+			return
+		}
+		note := strings.TrimSpace(c.PostForm("resolution_note"))
+		if note == "" {
+			utils.RespondWithError(c, http.StatusBadRequest, "Resolution note is required", nil)
+			return
+		}
+		work.ResolutionNote = &note
+	}
+
+	engineerID, ok := h.requireEngineerID(c)
+	if !ok {
+		return
+	}
+
+	issue, err := h.db.GetIssue(id)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to get issue", err)
+		return
+	}
+	if issue == nil {
+		utils.RespondWithError(c, http.StatusNotFound, "Issue not found", nil)
+		return
+	}
+	if issue.AssignedTo == nil || *issue.AssignedTo != engineerID {
+		utils.RespondWithError(c, http.StatusForbidden, "You can only update issues assigned to you", nil)
+		return
+	}
+	if !models.CanWorkTransition(issue.Status, status) {
+		respondInvalidTransition(c, issue.Status)
+		return
+	}
+
+	// Process after photos (if provided)
+	uploaded := models.IssueImages{}
+	if c.Request.MultipartForm != nil {
+		for _, fileHeader := range c.Request.MultipartForm.File["images"] {
+			image, err := h.uploadImageFile(c, fileHeader)
+			if err != nil {
+				h.deleteImageObjects(c.Request.Context(), uploaded)
+				utils.RespondWithError(c, http.StatusInternalServerError, "Failed to upload image", err)
+				return
+			}
+
+			uploaded = append(uploaded, image)
+			work.AfterImages = append(work.AfterImages, image.Original)
+		}
+	}
+
+	err = h.db.RecordIssueWork(id, engineerID, &work)
+	if err != nil {
+		// Nothing refers to the uploads unless the work was recorded
+		h.deleteImageObjects(c.Request.Context(), uploaded)
+	}
+	var transitionErr *database.TransitionError
+	if errors.As(err, &transitionErr) {
+		// Moved on since it was loaded above
+		respondInvalidTransition(c, transitionErr.From)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		// Reassigned since it was loaded above
+		utils.RespondWithError(c, http.StatusForbidden, "You can only update issues assigned to you", nil)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "Failed to update issue", err)
+		return
+	}
+
+	// After photos are only kept at full size, so the smaller renditions go
+	for _, image := range uploaded {
+		h.deleteObjects(c.Request.Context(), image.Keys()[1:])
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Issue updated successfully"})
+}
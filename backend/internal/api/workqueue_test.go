@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chalkstone.council/internal/database"
+	dbMock "chalkstone.council/internal/database/mocks"
+	"chalkstone.council/internal/models"
+	"chalkstone.council/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// createResolveForm builds a multipart resolve form
+func createResolveForm(note string) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if note != "" {
+		_ = writer.WriteField("resolution_note", note)
+	}
+	writer.Close()
+	return &buf, writer.FormDataContentType()
+}
+
+func TestListMyAssignedIssues(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+	engineerID := int64(3)
+
+	t.Run("Success", func(t *testing.T) {
+		distance := 120.5
+		mockDB.EXPECT().GetUserByUsername("test_user").Return(&models.User{Username: "test_user", EngineerID: &engineerID}, nil)
+		mockDB.EXPECT().ListEngineerIssues(engineerID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ int64, lat, lon *float64) ([]*models.WorkItem, error) {
+				assert.Equal(t, 51.5, *lat)
+				assert.Equal(t, -0.12, *lon)
+				return []*models.WorkItem{{Issue: models.Issue{ID: 1, AssignedTo: &engineerID}, DistanceMeters: &distance}}, nil
+			})
+
+		req, _ := http.NewRequest("GET", "/api/engineer/me/issues?near=51.5,-0.12", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response []models.WorkItem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response, 1)
+		assert.Equal(t, distance, *response[0].DistanceMeters)
+	})
+
+	t.Run("Without location", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("test_user").Return(&models.User{Username: "test_user", EngineerID: &engineerID}, nil)
+		mockDB.EXPECT().ListEngineerIssues(engineerID, nil, nil).Return([]*models.WorkItem{}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/engineer/me/issues", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid location", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/engineer/me/issues?near=91,0", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Account not linked", func(t *testing.T) {
+		mockDB.EXPECT().GetUserByUsername("test_user").Return(&models.User{Username: "test_user"}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/engineer/me/issues", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRecordIssueWork(t *testing.T) {
+	router, mockDB, _ := setupTestRouter(t)
+	engineerID := int64(3)
+	otherEngineerID := int64(4)
+
+	linkedUser := func() {
+		mockDB.EXPECT().GetUserByUsername("test_user").Return(&models.User{Username: "test_user", EngineerID: &engineerID}, nil)
+	}
+
+	t.Run("Start work", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, &models.IssueWork{
+			Status:    models.StatusInProgress,
+			UpdatedBy: "test_user",
+		}).Return(nil)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		note := "Filled and sealed"
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusInProgress, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, &models.IssueWork{
+			Status:         models.StatusResolved,
+			ResolutionNote: &note,
+			UpdatedBy:      "test_user",
+		}).Return(nil)
+
+		body, contentType := createResolveForm(note)
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/resolve", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Resolve without note", func(t *testing.T) {
+		body, contentType := createResolveForm("")
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/resolve", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Not assigned to me", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned, AssignedTo: &otherEngineerID}, nil)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Reassigned meanwhile", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusAssigned, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, gomock.Any()).Return(sql.ErrNoRows)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Invalid transition", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusClosed, AssignedTo: &engineerID}, nil)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Already in progress", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusInProgress, AssignedTo: &engineerID}, nil)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Resolved meanwhile", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusInProgress, AssignedTo: &engineerID}, nil)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, gomock.Any()).
+			Return(&database.TransitionError{From: models.StatusResolved, To: models.StatusResolved})
+
+		body, contentType := createResolveForm("Filled and sealed")
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/resolve", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"current_status":"RESOLVED"`)
+	})
+
+	t.Run("Issue not found", func(t *testing.T) {
+		linkedUser()
+		mockDB.EXPECT().GetIssue(int64(99)).Return(nil, nil)
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/99/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRecordIssueWorkPhotos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engineerID := int64(3)
+
+	setup := func(t *testing.T) (*gin.Engine, *dbMock.MockDatabaseOperations, *storage.MemoryStore) {
+		ctrl := gomock.NewController(t)
+		mockDB := dbMock.NewMockDatabaseOperations(ctrl)
+		store := storage.NewMemoryStore("http://localhost:8080/api/images", nil)
+		handler := &Handler{db: mockDB, images: store}
+
+		router := gin.New()
+		router.PATCH("/api/engineer/me/issues/:id/resolve", func(c *gin.Context) {
+			c.Set("userID", "test_user")
+			c.Next()
+		}, handler.ResolveIssueWork)
+
+		mockDB.EXPECT().GetUserByUsername("test_user").Return(&models.User{Username: "test_user", EngineerID: &engineerID}, nil)
+		mockDB.EXPECT().GetIssue(int64(1)).Return(&models.Issue{ID: 1, Status: models.StatusInProgress, AssignedTo: &engineerID}, nil)
+		return router, mockDB, store
+	}
+
+	resolve := func(t *testing.T, router *gin.Engine) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		_ = writer.WriteField("resolution_note", "Filled and sealed")
+		fileWriter, _ := writer.CreateFormFile("images", "after.jpg")
+		_, _ = fileWriter.Write(testJPEG(t, 64, 48))
+		writer.Close()
+
+		req, _ := http.NewRequest("PATCH", "/api/engineer/me/issues/1/resolve", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Only the original is kept", func(t *testing.T) {
+		router, mockDB, store := setup(t)
+		var afterImages []string
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, gomock.Any()).
+			DoAndReturn(func(_ int64, _ int64, work *models.IssueWork) error {
+				afterImages = work.AfterImages
+				return nil
+			})
+
+		w := resolve(t, router)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, afterImages, 1)
+		assert.Equal(t, afterImages, store.Keys())
+	})
+
+	t.Run("Uploads are removed when the work isn't recorded", func(t *testing.T) {
+		router, mockDB, store := setup(t)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, gomock.Any()).Return(errors.New("db error"))
+
+		w := resolve(t, router)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("Uploads are removed when the issue was reassigned", func(t *testing.T) {
+		router, mockDB, store := setup(t)
+		mockDB.EXPECT().RecordIssueWork(int64(1), engineerID, gomock.Any()).Return(sql.ErrNoRows)
+
+		w := resolve(t, router)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, store.Keys())
+	})
+}
@@ -6,13 +6,14 @@ import (
 )
 
 type Config struct {
-	DBHost     string `mapstructure:"DB_HOST"`
-	DBPort     string `mapstructure:"DB_PORT"`
-	DBUser     string `mapstructure:"DB_USER"`
-	DBPassword string `mapstructure:"DB_PASSWORD"`
-	DBName     string `mapstructure:"DB_NAME"`
-	JWTSecret  string `mapstructure:"JWT_SECRET"`
-	Port       string `mapstructure:"PORT"`
+	DBHost         string `mapstructure:"DB_HOST"`
+	DBPort         string `mapstructure:"DB_PORT"`
+	DBUser         string `mapstructure:"DB_USER"`
+	DBPassword     string `mapstructure:"DB_PASSWORD"`
+	DBName         string `mapstructure:"DB_NAME"`
+	JWTSecret      string `mapstructure:"JWT_SECRET"`
+	JWTKeysFile    string `mapstructure:"JWT_KEYS_FILE"`
+	Port           string `mapstructure:"PORT"`
 	AllowedOrigins string `mapstructure:"ALLOWED_ORIGINS"`
 }
 
@@ -38,7 +39,8 @@ func LoadConfig() (Config, error) {
 	fmt.Printf("DB_USER: %s\n", config.DBUser)
 	fmt.Printf("DB_PASSWORD: %s\n", config.DBPassword)
 	fmt.Printf("DB_NAME: %s\n", config.DBName)
-	fmt.Printf("JWT_SECRET: %s\n", config.JWTSecret)
+	fmt.Printf("JWT_SECRET set: %t\n", config.JWTSecret != "")
+	fmt.Printf("JWT_KEYS_FILE: %s\n", config.JWTKeysFile)
 	fmt.Printf("PORT: %s\n", config.Port)
 	fmt.Printf("ALLOWED_ORIGINS: %s\n", config.AllowedOrigins)
 
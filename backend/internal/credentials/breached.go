@@ -0,0 +1,66 @@
+package credentials
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashPrefixLength is the length of the SHA-1 prefix a range file covers
+const hashPrefixLength = 5
+
+// BreachedList reports whether a password is known from a data breach
+type BreachedList interface {
+	Contains(password string) (bool, error)
+}
+
+// RangeDirectory is an offline copy of a k-anonymity breached password list, as served
+// by the Pwned Passwords range API. The SHA-1 hashes are split by their first five hex
+// characters into one file per prefix, named e.g. 5BAA6 or 5BAA6.txt, with one
+// "SUFFIX:COUNT" line per hash. Only the file for the password's prefix is read.
+type RangeDirectory struct {
+	dir string
+}
+
+func NewRangeDirectory(dir string) *RangeDirectory {
+	return &RangeDirectory{dir: dir}
+}
+
+func (d *RangeDirectory) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	f, err := d.openRange(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, count, _ := strings.Cut(line, ":")
+		if strings.EqualFold(candidate, suffix) {
+			// Padding entries added to hide the real number of matches have a count of 0
+			return strings.TrimSpace(count) != "0", nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+func (d *RangeDirectory) openRange(prefix string) (*os.File, error) {
+	f, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		f, err = os.Open(filepath.Join(d.dir, prefix))
+	}
+	return f, err
+}
@@ -0,0 +1,185 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultMinPasswordLength = 10
+	defaultMinCharClasses    = 2
+
+	// bcrypt only uses the first 72 bytes of a password
+	maxPasswordBytes = 72
+
+	minUsernameLength = 3
+	maxUsernameLength = 32
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+	// Names that could be mistaken for the council or the system itself. Deleted
+	// accounts are renamed to deleted-user-<id>.
+	reservedUsernames        = []string{"admin", "administrator", "root", "system", "council", "support"}
+	reservedUsernamePrefixes = []string{"deleted-user-"}
+)
+
+// Violation is a rule that a username or password fails
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy holds the rules for new usernames and passwords
+type Policy struct {
+	MinPasswordLength int
+	// MinCharClasses is how many of lowercase letters, uppercase letters, digits and
+	// symbols a password must mix
+	MinCharClasses int
+	// Breached lists passwords known from data breaches; nil skips the check
+	Breached BreachedList
+}
+
+// LoadPolicy reads PASSWORD_MIN_LENGTH, PASSWORD_MIN_CHAR_CLASSES and
+// BREACHED_PASSWORDS_DIR, falling back to the defaults when they are unset or invalid.
+// Without BREACHED_PASSWORDS_DIR passwords aren't checked against breaches.
+func LoadPolicy() Policy {
+	policy := Policy{
+		MinPasswordLength: defaultMinPasswordLength,
+		MinCharClasses:    defaultMinCharClasses,
+	}
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && n > 0 {
+		policy.MinPasswordLength = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_CHAR_CLASSES")); err == nil && n >= 0 && n <= 4 {
+		policy.MinCharClasses = n
+	}
+	if dir := os.Getenv("BREACHED_PASSWORDS_DIR"); dir != "" {
+		policy.Breached = NewRangeDirectory(dir)
+	}
+	return policy
+}
+
+// ValidateUsername returns every username rule the username fails
+func ValidateUsername(username string) []Violation {
+	violations := []Violation{}
+	if n := utf8.RuneCountInString(username); n < minUsernameLength || n > maxUsernameLength {
+		violations = append(violations, Violation{
+			Field:   "username",
+			Rule:    "length",
+			Message: fmt.Sprintf("Username must be between %d and %d characters long", minUsernameLength, maxUsernameLength),
+		})
+	}
+	if !usernamePattern.MatchString(username) {
+		violations = append(violations, Violation{
+			Field:   "username",
+			Rule:    "characters",
+			Message: "Username may only contain letters, digits, dots, hyphens and underscores, and must start with a letter or digit",
+		})
+	}
+	if isReservedUsername(username) {
+		violations = append(violations, Violation{
+			Field:   "username",
+			Rule:    "reserved",
+			Message: "Username is reserved",
+		})
+	}
+	return violations
+}
+
+func isReservedUsername(username string) bool {
+	username = strings.ToLower(username)
+	for _, name := range reservedUsernames {
+		if username == name {
+			return true
+		}
+	}
+	for _, prefix := range reservedUsernamePrefixes {
+		if strings.HasPrefix(username, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePassword returns every password rule the password fails. The username may
+// be empty if it isn't known.
+func (p Policy) ValidatePassword(password, username string) ([]Violation, error) {
+	violations := []Violation{}
+	if utf8.RuneCountInString(password) < p.MinPasswordLength {
+		violations = append(violations, Violation{
+			Field:   "password",
+			Rule:    "min_length",
+			Message: fmt.Sprintf("Password must be at least %d characters long", p.MinPasswordLength),
+		})
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, Violation{
+			Field:   "password",
+			Rule:    "max_length",
+			Message: fmt.Sprintf("Password must be at most %d bytes long", maxPasswordBytes),
+		})
+	}
+	if charClasses(password) < p.MinCharClasses {
+		violations = append(violations, Violation{
+			Field:   "password",
+			Rule:    "char_classes",
+			Message: fmt.Sprintf("Password must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinCharClasses),
+		})
+	}
+	if utf8.RuneCountInString(username) >= minUsernameLength && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violations = append(violations, Violation{
+			Field:   "password",
+			Rule:    "contains_username",
+			Message: "Password must not contain the username",
+		})
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			violations = append(violations, Violation{
+				Field:   "password",
+				Rule:    "breached",
+				Message: "Password has appeared in a data breach, choose a different one",
+			})
+		}
+	}
+	return violations, nil
+}
+
+// charClasses counts which of lowercase letters, uppercase letters, digits and other
+// characters the password uses
+func charClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	count := 0
+	for _, used := range []bool{lower, upper, digit, other} {
+		if used {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,86 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rules returns the names of the rules that failed
+func rules(violations []Violation) []string {
+	names := []string{}
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestValidateUsername(t *testing.T) {
+	assert.Empty(t, ValidateUsername("jane.doe_99"))
+	assert.Equal(t, []string{"length"}, rules(ValidateUsername("jd")))
+	assert.Equal(t, []string{"characters"}, rules(ValidateUsername("jane doe")))
+	assert.Equal(t, []string{"characters"}, rules(ValidateUsername("-jane")))
+	assert.Equal(t, []string{"reserved"}, rules(ValidateUsername("Admin")))
+	assert.Equal(t, []string{"reserved"}, rules(ValidateUsername("deleted-user-12")))
+
+	// Every failed rule is reported
+	assert.Equal(t, []string{"length", "characters"}, rules(ValidateUsername("")))
+}
+
+func TestValidatePassword(t *testing.T) {
+	policy := Policy{MinPasswordLength: 10, MinCharClasses: 3}
+
+	violations, err := policy.ValidatePassword("Correct-Horse-7", "jane")
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = policy.ValidatePassword("short", "jane")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"min_length", "char_classes"}, rules(violations))
+
+	violations, err = policy.ValidatePassword("Jane-Doe-2024", "JaneDoe")
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+	violations, err = policy.ValidatePassword("Janedoe-2024", "JaneDoe")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"contains_username"}, rules(violations))
+
+	violations, err = policy.ValidatePassword(string(make([]byte, 73)), "")
+	assert.NoError(t, err)
+	assert.Contains(t, rules(violations), "max_length")
+
+	// Unicode letters count towards their case
+	assert.Equal(t, 3, charClasses("Ünïcödé1"))
+}
+
+func TestRangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(
+		"003D68EB55068C33ACE09247EE4C639306B:3\r\n"+
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0600))
+	// SHA-1 of "letmein" is B7A875FC1EA228B9061041B7CEC4BD3C52AB3CE3, listed as padding only
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "B7A87"), []byte(
+		"5FC1EA228B9061041B7CEC4BD3C52AB3CE3:0\n"), 0600))
+
+	list := NewRangeDirectory(dir)
+
+	breached, err := list.Contains("password")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = list.Contains("letmein")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+
+	// No range file for the prefix
+	breached, err = list.Contains("Correct-Horse-7")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+
+	violations, err := Policy{MinPasswordLength: 8, Breached: list}.ValidatePassword("password", "jane")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"breached"}, rules(violations))
+}
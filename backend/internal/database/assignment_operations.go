@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+
+	"chalkstone.council/internal/models"
+)
+
+// advanceToAssigned records the status changes an issue goes through when an engineer is
+// assigned to it (see models.AssignmentTransitions) and returns its new status
+func advanceToAssigned(tx *sql.Tx, id int64, status models.IssueStatus, actor string) (models.IssueStatus, error) {
+	for _, next := range models.AssignmentTransitions(status) {
+		oldValue, newValue := string(status), string(next)
+		if err := insertIssueEvent(tx, id, models.EventStatusChanged, &oldValue, &newValue, actor); err != nil {
+			return "", err
+		}
+		status = next
+	}
+	return status, nil
+}
+
+// AssignIssue assigns an engineer chosen by the assignment engine and stores the reason
+// for the choice, moving the issue to ASSIGNED. Returns sql.ErrNoRows if the issue doesn't exist.
+func (db *DB) AssignIssue(id, engineerID int64, reason, actor string) error {
+	if actor == "" {
+		actor = "system"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var oldStatus models.IssueStatus
+	var oldAssignedTo sql.NullInt64
+	err = tx.QueryRow(`SELECT status, assigned_to FROM issues WHERE id = $1 FOR UPDATE`, id).Scan(&oldStatus, &oldAssignedTo)
+	if err != nil {
+		return err
+	}
+
+	status, err := advanceToAssigned(tx, id, oldStatus, actor)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE issues
+        SET assigned_to = $1, assignment_reason = $2, status = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $4`,
+		engineerID, reason, status, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !oldAssignedTo.Valid || oldAssignedTo.Int64 != engineerID {
+		var oldValue *string
+		if oldAssignedTo.Valid {
+			v := strconv.FormatInt(oldAssignedTo.Int64, 10)
+			oldValue = &v
+		}
+		newValue := strconv.FormatInt(engineerID, 10)
+		if err := insertIssueEvent(tx, id, models.EventAssigned, oldValue, &newValue, actor); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
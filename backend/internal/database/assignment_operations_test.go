@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestAssignIssue(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO engineers (id, name, email, phone, specialization, join_date)
+		VALUES (1, 'Road Engineer', 'roads@example.com', '555-1234', 'Roads and Infrastructure', NOW()),
+		       (2, 'Drain Engineer', 'drains@example.com', '555-5678', 'Drainage Systems', NOW())
+	`)
+	assert.NoError(t, err)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status)
+		VALUES (1, 'POTHOLE', 'Pothole', 50.7184, -3.5339, 'resident', 'TRIAGED')
+	`)
+	assert.NoError(t, err)
+
+	reason := "Roads and Infrastructure specialist with the fewest open issues (0) for POTHOLE"
+	err = testDB.AssignIssue(1, 1, reason, "dispatcher")
+	assert.NoError(t, err)
+
+	issue, err := testDB.GetIssue(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *issue.AssignedTo)
+	assert.Equal(t, reason, *issue.AssignmentReason)
+
+	// Assignment moves the issue on to ASSIGNED
+	assert.Equal(t, models.StatusAssigned, issue.Status)
+
+	history, err := testDB.GetIssueHistory(1)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	var events []models.IssueEventType
+	for _, event := range history {
+		events = append(events, event.EventType)
+		assert.Equal(t, "dispatcher", event.Actor)
+		if event.EventType == models.EventStatusChanged {
+			assert.Equal(t, "TRIAGED", *event.OldValue)
+			assert.Equal(t, "ASSIGNED", *event.NewValue)
+		}
+	}
+	assert.ElementsMatch(t, []models.IssueEventType{models.EventStatusChanged, models.EventAssigned}, events)
+
+	// The assignment engine's workload counts the new assignment
+	performance, err := testDB.GetEngineerPerformance(false)
+	assert.NoError(t, err)
+	for _, p := range performance {
+		if p.Engineer.ID == 1 {
+			assert.Equal(t, 1, p.IssuesAssigned)
+		}
+	}
+
+	// A manual reassignment clears the engine's reason
+	engineerID := int64(2)
+	err = testDB.UpdateIssue(1, &models.IssueUpdate{AssignedTo: &engineerID, UpdatedBy: "dispatcher"})
+	assert.NoError(t, err)
+
+	issue, err = testDB.GetIssue(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *issue.AssignedTo)
+	assert.Nil(t, issue.AssignmentReason)
+
+	// An issue already being worked on keeps its status when reassigned
+	inProgress := models.StatusInProgress
+	err = testDB.UpdateIssue(1, &models.IssueUpdate{Status: &inProgress, UpdatedBy: "dispatcher"})
+	assert.NoError(t, err)
+	err = testDB.AssignIssue(1, 1, reason, "dispatcher")
+	assert.NoError(t, err)
+	issue, err = testDB.GetIssue(1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusInProgress, issue.Status)
+
+	// A NEW issue is triaged and assigned in one go
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status)
+		VALUES (2, 'POTHOLE', 'Another pothole', 50.7184, -3.5339, 'resident', 'NEW')
+	`)
+	assert.NoError(t, err)
+	err = testDB.UpdateIssue(2, &models.IssueUpdate{AssignedTo: &engineerID, UpdatedBy: "dispatcher"})
+	assert.NoError(t, err)
+	issue, err = testDB.GetIssue(2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusAssigned, issue.Status)
+
+	history, err = testDB.GetIssueHistory(2)
+	assert.NoError(t, err)
+	var statuses []string
+	for _, event := range history {
+		if event.EventType == models.EventStatusChanged {
+			statuses = append(statuses, *event.OldValue+"->"+*event.NewValue)
+		}
+	}
+	assert.ElementsMatch(t, []string{"NEW->TRIAGED", "TRIAGED->ASSIGNED"}, statuses)
+
+	assert.ErrorIs(t, testDB.AssignIssue(999, 1, reason, "dispatcher"), sql.ErrNoRows)
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+
+	"chalkstone.council/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreateComment adds a comment to an issue and records it in the issue history
+func (db *DB) CreateComment(comment *models.Comment) (int64, error) {
+	if comment == nil {
+		return 0, fmt.Errorf("comment cannot be nil")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(tx)
+
+	var id int64
+	err = tx.QueryRow(`
+        INSERT INTO issue_comments (issue_id, author, body, internal, images)
+        VALUES ($1, $2, $3, $4, $5::text[])
+        RETURNING id`,
+		comment.IssueID,
+		comment.Author,
+		comment.Body,
+		comment.Internal,
+		pq.Array(comment.Images),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	commentID := strconv.FormatInt(id, 10)
+	if err := insertIssueEvent(tx, comment.IssueID, models.EventCommented, nil, &commentID, comment.Author); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetComment returns a comment by ID, or nil if it does not exist
+func (db *DB) GetComment(id int64) (*models.Comment, error) {
+	var comment models.Comment
+	err := db.QueryRow(`
+        SELECT id, issue_id, author, body, internal, images::text[], created_at, updated_at
+        FROM issue_comments WHERE id = $1`,
+		id,
+	).Scan(
+		&comment.ID,
+		&comment.IssueID,
+		&comment.Author,
+		&comment.Body,
+		&comment.Internal,
+		pq.Array(&comment.Images),
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments returns the comments on an issue, oldest first. Internal
+// comments are only included when includeInternal is set.
+func (db *DB) ListComments(issueID int64, includeInternal bool) ([]*models.Comment, error) {
+	rows, err := db.Query(`
+        SELECT id, issue_id, author, body, internal, images::text[], created_at, updated_at
+        FROM issue_comments
+        WHERE issue_id = $1
+        AND ($2 OR NOT internal)
+        ORDER BY created_at ASC, id ASC`,
+		issueID, includeInternal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	comments := []*models.Comment{}
+	for rows.Next() {
+		var comment models.Comment
+		err := rows.Scan(
+			&comment.ID,
+			&comment.IssueID,
+			&comment.Author,
+			&comment.Body,
+			&comment.Internal,
+			pq.Array(&comment.Images),
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, &comment)
+	}
+	return comments, rows.Err()
+}
+
+// UpdateComment replaces the body of a comment
+func (db *DB) UpdateComment(id int64, body string) error {
+	result, err := db.Exec(`
+        UPDATE issue_comments SET body = $1 WHERE id = $2`,
+		body, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteComment removes a comment and returns the keys of its images, which the caller
+// deletes from storage. Returns sql.ErrNoRows if the comment does not exist.
+func (db *DB) DeleteComment(id int64) ([]string, error) {
+	var images []string
+	err := db.QueryRow(`
+        DELETE FROM issue_comments WHERE id = $1
+        RETURNING images::text[]`,
+		id,
+	).Scan(pq.Array(&images))
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+// TestCommentOperations covers the comment lifecycle and internal note visibility
+func TestCommentOperations(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, created_at)
+		VALUES (1, 'POTHOLE', 'Test pothole', 51.5074, -0.1278, 'resident', 'NEW', NOW())
+	`)
+	assert.NoError(t, err, "Failed to create test issue")
+
+	publicID, err := testDB.CreateComment(&models.Comment{IssueID: 1, Author: "resident", Body: "It is getting bigger", Images: []string{"http://localhost:9000/bucket/a.jpg"}})
+	assert.NoError(t, err)
+
+	internalID, err := testDB.CreateComment(&models.Comment{IssueID: 1, Author: "staff_user", Body: "Crew booked", Internal: true})
+	assert.NoError(t, err)
+
+	// Staff see everything, residents only see public comments
+	comments, err := testDB.ListComments(1, true)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+
+	comments, err = testDB.ListComments(1, false)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, publicID, comments[0].ID)
+	assert.Equal(t, []string{"http://localhost:9000/bucket/a.jpg"}, comments[0].Images)
+
+	// Comments are recorded in the issue history
+	events, err := testDB.GetIssueHistory(1)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, models.EventCommented, events[0].EventType)
+
+	// Edit
+	err = testDB.UpdateComment(publicID, "It is much bigger now")
+	assert.NoError(t, err)
+
+	comment, err := testDB.GetComment(publicID)
+	assert.NoError(t, err)
+	assert.Equal(t, "It is much bigger now", comment.Body)
+
+	// Delete, handing back the images to remove from storage
+	images, err := testDB.DeleteComment(internalID)
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+
+	images, err = testDB.DeleteComment(publicID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:9000/bucket/a.jpg"}, images)
+
+	comment, err = testDB.GetComment(internalID)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+
+	// Missing comments
+	assert.Equal(t, sql.ErrNoRows, testDB.UpdateComment(999, "x"))
+	_, err = testDB.DeleteComment(999)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	_, err = testDB.CreateComment(nil)
+	assert.Error(t, err)
+}
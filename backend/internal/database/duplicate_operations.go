@@ -0,0 +1,196 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+// FindDuplicateCandidates returns open issues of the same type reported since the given
+// time within radiusMeters of the location, nearest first.
+func (db *DB) FindDuplicateCandidates(issueType models.IssueType, latitude, longitude, radiusMeters float64, since time.Time) ([]*models.DuplicateCandidate, error) {
+	box := models.BoundingBoxAround(latitude, longitude, radiusMeters)
+
+	rows, err := db.Query(`
+        SELECT id, type, status, description, latitude, longitude,
+               images, reported_by, assigned_to, priority, due_at, created_at, updated_at,
+               supporter_count, distance_meters
+        FROM (
+            SELECT *, `+issueImagesSQL+` AS images, `+supporterCountSQL+` AS supporter_count,
+                   `+distanceSQL("$1::float8", "$2::float8")+` AS distance_meters
+            FROM issues
+            WHERE type = $3
+            AND status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE')
+            AND created_at >= $4
+            AND point(longitude, latitude) <@ box(point($5::float8, $6::float8), point($7::float8, $8::float8))
+        ) nearby
+        WHERE distance_meters <= $9
+        ORDER BY distance_meters, created_at`,
+		latitude, longitude, issueType, since,
+		box.MinLon, box.MinLat, box.MaxLon, box.MaxLat, radiusMeters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	candidates := []*models.DuplicateCandidate{}
+	for rows.Next() {
+		var candidate models.DuplicateCandidate
+		err := rows.Scan(
+			&candidate.ID,
+			&candidate.Type,
+			&candidate.Status,
+			&candidate.Description,
+			&candidate.Location.Latitude,
+			&candidate.Location.Longitude,
+			&candidate.Images,
+			&candidate.ReportedBy,
+			&candidate.AssignedTo,
+			&candidate.Priority,
+			&candidate.DueAt,
+			&candidate.CreatedAt,
+			&candidate.UpdatedAt,
+			&candidate.SupporterCount,
+			&candidate.DistanceMeters,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// IsIssueReporter reports whether the user reported the issue, either directly or
+// through a duplicate that was merged into it
+func (db *DB) IsIssueReporter(issueID int64, username string) (bool, error) {
+	var reporter bool
+	err := db.QueryRow(`
+        SELECT EXISTS (SELECT 1 FROM issue_reporters WHERE issue_id = $1 AND reported_by = $2)`,
+		issueID, username,
+	).Scan(&reporter)
+	return reporter, err
+}
+
+var (
+	// ErrMergeIntoSelf is returned when an issue is listed as a duplicate of itself
+	ErrMergeIntoSelf = errors.New("cannot merge an issue into itself")
+	// ErrMergePrimaryClosed is returned when merging into an issue that is no longer open
+	ErrMergePrimaryClosed = errors.New("cannot merge into an issue that is no longer open")
+	// ErrCannotMarkDuplicate is returned when an issue's status doesn't allow it to become a duplicate
+	ErrCannotMarkDuplicate = errors.New("issue cannot be marked as a duplicate")
+)
+
+// MergeIssues marks each duplicate as a DUPLICATE of the primary issue, moving its
+// images, reporters and supporters onto the primary. Only as many images are moved as
+// keep the primary within maxImages; the rest stay with their duplicate. The issues are
+// locked while their statuses are checked, so the merge can't race another status
+// change. Returns sql.ErrNoRows if any issue is missing, ErrMergeIntoSelf,
+// ErrMergePrimaryClosed, or ErrCannotMarkDuplicate naming the first duplicate that
+// can't be merged.
+func (db *DB) MergeIssues(primaryID int64, duplicateIDs []int64, actor string, maxImages int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var primaryStatus models.IssueStatus
+	if err := tx.QueryRow(`SELECT status FROM issues WHERE id = $1 FOR UPDATE`, primaryID).Scan(&primaryStatus); err != nil {
+		return err
+	}
+	if !models.IsOpenStatus(primaryStatus) {
+		return ErrMergePrimaryClosed
+	}
+
+	seen := make(map[int64]bool, len(duplicateIDs))
+	for _, duplicateID := range duplicateIDs {
+		if duplicateID == primaryID {
+			return ErrMergeIntoSelf
+		}
+		if seen[duplicateID] {
+			continue
+		}
+		seen[duplicateID] = true
+
+		var status models.IssueStatus
+		err := tx.QueryRow(`
+            SELECT status FROM issues WHERE id = $1 FOR UPDATE`,
+			duplicateID,
+		).Scan(&status)
+		if err != nil {
+			return err
+		}
+		if !models.CanTransition(status, models.StatusDuplicate) {
+			return fmt.Errorf("issue %d is %s: %w", duplicateID, status, ErrCannotMarkDuplicate)
+		}
+
+		// The duplicate's photos go after the primary's, in the same order, up to the limit
+		var count, next int
+		if err := tx.QueryRow(`
+            SELECT COUNT(*), COALESCE(MAX(position) + 1, 0) FROM issue_images WHERE issue_id = $1`,
+			primaryID,
+		).Scan(&count, &next); err != nil {
+			return err
+		}
+		if count < maxImages {
+			if _, err := tx.Exec(`
+                UPDATE issue_images im SET issue_id = $1, position = $3 + o.n - 1
+                FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position) AS n
+                      FROM issue_images WHERE issue_id = $2
+                      ORDER BY position LIMIT $4) o
+                WHERE im.id = o.id`,
+				primaryID, duplicateID, next, maxImages-count); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(`
+            INSERT INTO issue_reporters (issue_id, reported_by, created_at)
+            SELECT $1, reported_by, created_at FROM issue_reporters WHERE issue_id = $2
+            ON CONFLICT DO NOTHING`,
+			primaryID, duplicateID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+            INSERT INTO issue_supporters (issue_id, user_id, created_at)
+            SELECT $1, user_id, created_at FROM issue_supporters WHERE issue_id = $2
+            ON CONFLICT DO NOTHING`,
+			primaryID, duplicateID); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+            UPDATE issues SET status = $2, duplicate_of = $3
+            WHERE id = $1`,
+			duplicateID, models.StatusDuplicate, primaryID); err != nil {
+			return err
+		}
+
+		if status != models.StatusDuplicate {
+			oldStatus, newStatus := string(status), string(models.StatusDuplicate)
+			if err := insertIssueEvent(tx, duplicateID, models.EventStatusChanged, &oldStatus, &newStatus, actor); err != nil {
+				return err
+			}
+		}
+
+		merged := strconv.FormatInt(duplicateID, 10)
+		if err := insertIssueEvent(tx, primaryID, models.EventMerged, nil, &merged, actor); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestFindDuplicateCandidates(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, created_at) VALUES
+		(1, 'POTHOLE', 'Nearby pothole', 51.50745, -0.12785, 'resident1', 'NEW', NOW()),
+		(2, 'POTHOLE', 'Far away pothole', 51.5200, -0.1278, 'resident2', 'NEW', NOW()),
+		(3, 'POTHOLE', 'Fixed pothole', 51.5074, -0.1278, 'resident3', 'RESOLVED', NOW()),
+		(4, 'GRAFFITI', 'Graffiti on the same spot', 51.5074, -0.1278, 'resident4', 'NEW', NOW()),
+		(5, 'POTHOLE', 'Old pothole', 51.5074, -0.1278, 'resident5', 'NEW', NOW() - interval '60 days')
+	`)
+	assert.NoError(t, err, "Failed to create test issues")
+
+	candidates, err := testDB.FindDuplicateCandidates(models.TypePothole, 51.5074, -0.1278, 50, time.Now().Add(-30*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, int64(1), candidates[0].ID)
+	assert.Less(t, candidates[0].DistanceMeters, 50.0)
+}
+
+func TestMergeIssues(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	primaryID, err := testDB.CreateIssue(&models.IssueCreate{
+		Type: models.TypePothole, Description: "Pothole", ReportedBy: "resident1",
+		Images: models.IssueImages{{Original: "a.jpg", Medium: "a-medium.jpg", Thumb: "a-thumb.jpg", ContentType: "image/jpeg"}},
+	})
+	assert.NoError(t, err)
+
+	duplicateID, err := testDB.CreateIssue(&models.IssueCreate{
+		Type: models.TypePothole, Description: "Same pothole", ReportedBy: "resident2",
+		Images: models.IssueImages{{Original: "b.jpg", Medium: "b.jpg", Thumb: "b.jpg", ContentType: "image/jpeg"}},
+	})
+	assert.NoError(t, err)
+
+	err = testDB.MergeIssues(primaryID, []int64{duplicateID}, "staff_user", 10)
+	assert.NoError(t, err)
+
+	primary, err := testDB.GetIssue(primaryID)
+	assert.NoError(t, err)
+	if assert.Len(t, primary.Images, 2) {
+		assert.Equal(t, "a.jpg", primary.Images[0].Original)
+		assert.Equal(t, "a-thumb.jpg", primary.Images[0].Thumb)
+		// The duplicate's photo follows, still credited to its uploader
+		assert.Equal(t, "b.jpg", primary.Images[1].Original)
+		assert.Equal(t, "resident2", primary.Images[1].UploadedBy)
+	}
+
+	duplicate, err := testDB.GetIssue(duplicateID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusDuplicate, duplicate.Status)
+	assert.Equal(t, primaryID, *duplicate.DuplicateOf)
+	assert.Empty(t, duplicate.Images)
+
+	var reporters int
+	err = testDB.DB.QueryRow(`SELECT COUNT(*) FROM issue_reporters WHERE issue_id = $1`, primaryID).Scan(&reporters)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, reporters)
+
+	// The duplicate's reporter now follows the primary as one of its reporters
+	reported, err := testDB.ListIssuesByReporter("resident2", 1, 10)
+	assert.NoError(t, err)
+	reportedIDs := []int64{}
+	for _, issue := range reported {
+		reportedIDs = append(reportedIDs, issue.ID)
+	}
+	assert.ElementsMatch(t, []int64{primaryID, duplicateID}, reportedIDs)
+
+	reporter, err := testDB.IsIssueReporter(primaryID, "resident2")
+	assert.NoError(t, err)
+	assert.True(t, reporter)
+	reporter, err = testDB.IsIssueReporter(primaryID, "resident3")
+	assert.NoError(t, err)
+	assert.False(t, reporter)
+
+	events, err := testDB.GetIssueHistory(primaryID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EventMerged, events[len(events)-1].EventType)
+
+	// Missing issues roll back the merge
+	err = testDB.MergeIssues(primaryID, []int64{9999}, "staff_user", 10)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The primary can't be listed as its own duplicate
+	err = testDB.MergeIssues(primaryID, []int64{primaryID}, "staff_user", 10)
+	assert.ErrorIs(t, err, ErrMergeIntoSelf)
+
+	// Duplicates listed twice are merged once
+	thirdID, err := testDB.CreateIssue(&models.IssueCreate{Type: models.TypePothole, Description: "Pothole again", ReportedBy: "resident3"})
+	assert.NoError(t, err)
+	err = testDB.MergeIssues(primaryID, []int64{thirdID, thirdID}, "staff_user", 10)
+	assert.NoError(t, err)
+	events, err = testDB.GetIssueHistory(thirdID)
+	assert.NoError(t, err)
+	var statusChanges int
+	for _, event := range events {
+		if event.EventType == models.EventStatusChanged {
+			statusChanges++
+		}
+	}
+	assert.Equal(t, 1, statusChanges)
+
+	// Statuses are checked inside the merge
+	inProgressID, err := testDB.CreateIssue(&models.IssueCreate{Type: models.TypePothole, Description: "Being fixed", ReportedBy: "resident4"})
+	assert.NoError(t, err)
+	_, err = testDB.DB.Exec(`UPDATE issues SET status = 'IN_PROGRESS' WHERE id = $1`, inProgressID)
+	assert.NoError(t, err)
+	err = testDB.MergeIssues(primaryID, []int64{inProgressID}, "staff_user", 10)
+	assert.ErrorIs(t, err, ErrCannotMarkDuplicate)
+
+	// Nothing can be merged into a closed issue
+	_, err = testDB.DB.Exec(`UPDATE issues SET status = 'CLOSED' WHERE id = $1`, primaryID)
+	assert.NoError(t, err)
+	err = testDB.MergeIssues(primaryID, []int64{inProgressID}, "staff_user", 10)
+	assert.ErrorIs(t, err, ErrMergePrimaryClosed)
+}
+
+// TestMergeIssuesImageLimit checks that a merge moves only the photos that fit on the primary
+func TestMergeIssuesImageLimit(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	primaryID, err := testDB.CreateIssue(&models.IssueCreate{
+		Type: models.TypePothole, Description: "Pothole", ReportedBy: "resident1",
+		Images: models.IssueImages{{Original: "a.jpg", Medium: "a.jpg", Thumb: "a.jpg", ContentType: "image/jpeg"}},
+	})
+	assert.NoError(t, err)
+
+	duplicateID, err := testDB.CreateIssue(&models.IssueCreate{
+		Type: models.TypePothole, Description: "Same pothole", ReportedBy: "resident2",
+		Images: models.IssueImages{
+			{Original: "b.jpg", Medium: "b.jpg", Thumb: "b.jpg", ContentType: "image/jpeg"},
+			{Original: "c.jpg", Medium: "c.jpg", Thumb: "c.jpg", ContentType: "image/jpeg"},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, testDB.MergeIssues(primaryID, []int64{duplicateID}, "staff_user", 2))
+
+	primary, err := testDB.GetIssue(primaryID)
+	assert.NoError(t, err)
+	if assert.Len(t, primary.Images, 2) {
+		assert.Equal(t, "b.jpg", primary.Images[1].Original)
+	}
+
+	// The photo that didn't fit stays with the duplicate
+	duplicate, err := testDB.GetIssue(duplicateID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusDuplicate, duplicate.Status)
+	if assert.Len(t, duplicate.Images, 1) {
+		assert.Equal(t, "c.jpg", duplicate.Images[0].Original)
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"chalkstone.council/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrEngineerEmailTaken is returned when another engineer already uses the email address
+var ErrEngineerEmailTaken = errors.New("engineer email already in use")
+
+// engineerWriteError maps unique violations on engineers.email to ErrEngineerEmailTaken
+func engineerWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEngineerEmailTaken
+	}
+	return err
+}
+
+// CreateEngineer onboards a new, active engineer and returns their ID
+func (db *DB) CreateEngineer(engineer *models.EngineerCreate) (int64, error) {
+	if engineer == nil {
+		return 0, fmt.Errorf("engineer cannot be nil")
+	}
+
+	var id int64
+	err := db.QueryRow(`
+        INSERT INTO engineers (name, email, phone, specialization)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id`,
+		engineer.Name, engineer.Email, engineer.Phone, engineer.Specialization,
+	).Scan(&id)
+	if err != nil {
+		return 0, engineerWriteError(err)
+	}
+	return id, nil
+}
+
+// UpdateEngineer changes the given engineer fields. Returns sql.ErrNoRows if the engineer doesn't exist.
+func (db *DB) UpdateEngineer(id int64, update *models.EngineerUpdate) error {
+	if update == nil {
+		return fmt.Errorf("update cannot be nil")
+	}
+
+	result, err := db.Exec(`
+        UPDATE engineers
+        SET name = COALESCE($1, name),
+            email = COALESCE($2, email),
+            phone = COALESCE($3, phone),
+            specialization = COALESCE($4, specialization),
+            active = COALESCE($5, active)
+        WHERE id = $6`,
+		update.Name, update.Email, update.Phone, update.Specialization, update.Active, id,
+	)
+	if err != nil {
+		return engineerWriteError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeactivateEngineer retires an engineer. Their past work is kept, but they can no longer
+// be assigned issues. Returns sql.ErrNoRows if the engineer doesn't exist.
+func (db *DB) DeactivateEngineer(id int64) error {
+	result, err := db.Exec(`UPDATE engineers SET active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
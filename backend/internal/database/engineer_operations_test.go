@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestEngineerManagement(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	id, err := testDB.CreateEngineer(&models.EngineerCreate{
+		Name:           "Priya Patel",
+		Email:          "priya.patel@chalkstone.gov.uk",
+		Phone:          "+44 1234 567890",
+		Specialization: "Roads and Infrastructure",
+	})
+	assert.NoError(t, err)
+
+	engineer, err := testDB.GetEngineerByID(id)
+	assert.NoError(t, err)
+	assert.True(t, engineer.Active)
+	assert.Equal(t, "Roads and Infrastructure", engineer.Specialization)
+
+	// Email addresses are unique
+	_, err = testDB.CreateEngineer(&models.EngineerCreate{Name: "Someone Else", Email: "priya.patel@chalkstone.gov.uk"})
+	assert.ErrorIs(t, err, ErrEngineerEmailTaken)
+
+	specialization := "Drainage Systems"
+	err = testDB.UpdateEngineer(id, &models.EngineerUpdate{Specialization: &specialization})
+	assert.NoError(t, err)
+
+	engineer, err = testDB.GetEngineerByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Drainage Systems", engineer.Specialization)
+	assert.Equal(t, "Priya Patel", engineer.Name)
+
+	// Retired engineers are hidden from listings and performance unless requested
+	err = testDB.DeactivateEngineer(id)
+	assert.NoError(t, err)
+
+	engineers, err := testDB.ListEngineers(false)
+	assert.NoError(t, err)
+	assert.Empty(t, engineers)
+
+	engineers, err = testDB.ListEngineers(true)
+	assert.NoError(t, err)
+	assert.Len(t, engineers, 1)
+	assert.False(t, engineers[0].Active)
+
+	performance, err := testDB.GetEngineerPerformance(false)
+	assert.NoError(t, err)
+	assert.Empty(t, performance)
+
+	performance, err = testDB.GetEngineerPerformance(true)
+	assert.NoError(t, err)
+	assert.Len(t, performance, 1)
+
+	// Missing engineers
+	assert.ErrorIs(t, testDB.DeactivateEngineer(9999), sql.ErrNoRows)
+	assert.ErrorIs(t, testDB.UpdateEngineer(9999, &models.EngineerUpdate{Specialization: &specialization}), sql.ErrNoRows)
+}
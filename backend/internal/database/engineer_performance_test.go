@@ -73,7 +73,7 @@ func TestGetEngineerPerformanceEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to insert new unassigned issue")
 
 	// Test getting engineer performance
-	performance, err := testDB.GetEngineerPerformance()
+	performance, err := testDB.GetEngineerPerformance(false)
 	assert.NoError(t, err, "GetEngineerPerformance should not fail")
 	assert.NotNil(t, performance, "Performance should not be nil")
 	assert.Equal(t, 2, len(performance), "Should return performance for both engineers")
@@ -113,7 +113,7 @@ func TestGetEngineerPerformanceEdgeCases(t *testing.T) {
 
 	// Test with no engineers in the database
 	ClearTestData(t, testDB)
-	emptyPerformance, err := testDB.GetEngineerPerformance()
+	emptyPerformance, err := testDB.GetEngineerPerformance(false)
 	assert.NoError(t, err, "GetEngineerPerformance should not fail with empty database")
 	assert.Empty(t, emptyPerformance, "Performance should be empty with no engineers")
 
@@ -124,7 +124,7 @@ func TestGetEngineerPerformanceEdgeCases(t *testing.T) {
 	`)
 	assert.NoError(t, err, "Failed to insert test engineer")
 
-	noIssuesPerformance, err := testDB.GetEngineerPerformance()
+	noIssuesPerformance, err := testDB.GetEngineerPerformance(false)
 	assert.NoError(t, err, "GetEngineerPerformance should not fail with no issues")
 	assert.Equal(t, 1, len(noIssuesPerformance), "Should return performance for the engineer")
 	assert.Equal(t, 0, noIssuesPerformance[0].IssuesResolved, "Engineer should have 0 resolved issues")
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"chalkstone.council/internal/models"
+)
+
+// insertIssueEvent records an issue event as part of an existing transaction
+func insertIssueEvent(tx *sql.Tx, issueID int64, eventType models.IssueEventType, oldValue, newValue *string, actor string) error {
+	_, err := tx.Exec(`
+        INSERT INTO issue_events (issue_id, event_type, old_value, new_value, actor)
+        VALUES ($1, $2, $3, $4, $5)`,
+		issueID, eventType, oldValue, newValue, actor,
+	)
+	return err
+}
+
+// RecordIssueEvent appends an event to an issue's history
+func (db *DB) RecordIssueEvent(event *models.IssueEvent) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	return db.QueryRow(`
+        INSERT INTO issue_events (issue_id, event_type, old_value, new_value, actor)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`,
+		event.IssueID,
+		event.EventType,
+		event.OldValue,
+		event.NewValue,
+		event.Actor,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// GetIssueHistory returns the events for an issue, oldest first
+func (db *DB) GetIssueHistory(issueID int64) ([]*models.IssueEvent, error) {
+	rows, err := db.Query(`
+        SELECT id, issue_id, event_type, old_value, new_value, actor, created_at
+        FROM issue_events
+        WHERE issue_id = $1
+        ORDER BY created_at ASC, id ASC`,
+		issueID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	events := []*models.IssueEvent{}
+	for rows.Next() {
+		var event models.IssueEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.IssueID,
+			&event.EventType,
+			&event.OldValue,
+			&event.NewValue,
+			&event.Actor,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// GetAverageTimeInStatus computes how long issues spend in each status on average,
+// from the status changes recorded in issue_events. Each stint runs from the event
+// that entered the status to the next one, so only finished stints are counted and
+// an issue's current status doesn't skew the result.
+func (db *DB) GetAverageTimeInStatus() (map[string]string, error) {
+	rows, err := db.Query(`
+		WITH stints AS (
+			SELECT new_value AS status,
+			       created_at AS entered_at,
+			       LEAD(created_at) OVER (PARTITION BY issue_id ORDER BY created_at, id) AS left_at
+			FROM issue_events
+			WHERE event_type IN ('CREATED', 'STATUS_CHANGED')
+		)
+		SELECT status, AVG(EXTRACT(EPOCH FROM (left_at - entered_at))) AS avg_time
+		FROM stints
+		WHERE left_at IS NOT NULL
+		GROUP BY status;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timeInStatus := make(map[string]string)
+	for rows.Next() {
+		var status string
+		var avgTime float64
+		if err := rows.Scan(&status, &avgTime); err != nil {
+			return nil, err
+		}
+		timeInStatus[status] = formatDuration(avgTime)
+	}
+	return timeInStatus, rows.Err()
+}
+
+// formatDuration renders a number of seconds as "Xd Yh"
+func formatDuration(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	days := int(seconds) / 86400
+	hours := (int(seconds) % 86400) / 3600
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+// TestIssueHistory checks that creating and updating issues records events
+func TestIssueHistory(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO engineers (id, name, email, phone, specialization, join_date)
+		VALUES (1, 'Test Engineer', 'test@example.com', '555-1234', 'Pothole Repair', NOW())
+	`)
+	assert.NoError(t, err, "Failed to create test engineer")
+
+	issue := &models.IssueCreate{
+		Type:        models.TypePothole,
+		Description: "Pothole on the high street",
+		ReportedBy:  "resident",
+	}
+	id, err := testDB.CreateIssue(issue)
+	assert.NoError(t, err)
+
+	triaged := models.StatusTriaged
+	engineerID := int64(1)
+	err = testDB.UpdateIssue(id, &models.IssueUpdate{Status: &triaged, AssignedTo: &engineerID, UpdatedBy: "staff_user"})
+	assert.NoError(t, err)
+
+	// Re-sending the same values must not create new events
+	err = testDB.UpdateIssue(id, &models.IssueUpdate{Status: &triaged, AssignedTo: &engineerID, UpdatedBy: "staff_user"})
+	assert.NoError(t, err)
+
+	comment := "Called the resident back"
+	err = testDB.RecordIssueEvent(&models.IssueEvent{IssueID: id, EventType: models.EventCommented, NewValue: &comment, Actor: "staff_user"})
+	assert.NoError(t, err)
+
+	events, err := testDB.GetIssueHistory(id)
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	assert.Equal(t, models.EventCreated, events[0].EventType)
+	assert.Equal(t, "resident", events[0].Actor)
+
+	assert.Equal(t, models.EventStatusChanged, events[1].EventType)
+	assert.Equal(t, "NEW", *events[1].OldValue)
+	assert.Equal(t, "TRIAGED", *events[1].NewValue)
+	assert.Equal(t, "staff_user", events[1].Actor)
+
+	assert.Equal(t, models.EventAssigned, events[2].EventType)
+	assert.Nil(t, events[2].OldValue)
+	assert.Equal(t, "1", *events[2].NewValue)
+
+	assert.Equal(t, models.EventCommented, events[3].EventType)
+
+	// An issue with no events returns an empty slice
+	events, err = testDB.GetIssueHistory(999)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Nil events are rejected
+	err = testDB.RecordIssueEvent(nil)
+	assert.Error(t, err)
+}
+
+// TestGetAverageResolutionTimeFromEvents checks resolution times come from the recorded
+// resolution, not from when the issue was last touched
+func TestGetAverageResolutionTimeFromEvents(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, created_at, resolved_at)
+		VALUES (1, 'POTHOLE', 'Test pothole', 51.5074, -0.1278, 'test@example.com', 'CLOSED', '2025-01-01T00:00:00Z', '2025-01-20T00:00:00Z')
+	`)
+	assert.NoError(t, err, "Failed to create test issue")
+
+	// Reopened and resolved again, so the latest resolution counts
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issue_events (issue_id, event_type, old_value, new_value, actor, created_at) VALUES
+		(1, 'CREATED', NULL, 'NEW', 'test@example.com', '2025-01-01T00:00:00Z'),
+		(1, 'STATUS_CHANGED', 'IN_PROGRESS', 'RESOLVED', 'staff', '2025-01-02T06:00:00Z'),
+		(1, 'STATUS_CHANGED', 'RESOLVED', 'REOPENED', 'staff', '2025-01-03T00:00:00Z'),
+		(1, 'STATUS_CHANGED', 'IN_PROGRESS', 'RESOLVED', 'staff', '2025-01-04T06:00:00Z'),
+		(1, 'STATUS_CHANGED', 'RESOLVED', 'CLOSED', 'staff', '2025-01-20T00:00:00Z')
+	`)
+	assert.NoError(t, err, "Failed to create test events")
+
+	results, err := testDB.GetAverageResolutionTime()
+	assert.NoError(t, err)
+	assert.Equal(t, "3d 6h", results["POTHOLE"])
+	assert.Equal(t, "3d 6h", results["OVERALL"])
+}
+
+// TestGetAverageTimeInStatus checks time-in-state is computed from recorded events
+func TestGetAverageTimeInStatus(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, created_at)
+		VALUES (1, 'POTHOLE', 'Test pothole', 51.5074, -0.1278, 'test@example.com', 'RESOLVED', '2025-01-01T00:00:00Z'),
+		       (2, 'POTHOLE', 'Another pothole', 51.5074, -0.1278, 'test@example.com', 'IN_PROGRESS', '2025-01-01T00:00:00Z')
+	`)
+	assert.NoError(t, err, "Failed to create test issues")
+
+	// Assignments don't end a stint, and issue 2 is still in progress
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issue_events (issue_id, event_type, old_value, new_value, actor, created_at) VALUES
+		(1, 'CREATED', NULL, 'NEW', 'test@example.com', '2025-01-01T00:00:00Z'),
+		(1, 'STATUS_CHANGED', 'NEW', 'IN_PROGRESS', 'staff', '2025-01-02T06:00:00Z'),
+		(1, 'ASSIGNED', NULL, '1', 'staff', '2025-01-02T07:00:00Z'),
+		(1, 'STATUS_CHANGED', 'IN_PROGRESS', 'RESOLVED', 'staff', '2025-01-05T06:00:00Z'),
+		(2, 'CREATED', NULL, 'NEW', 'test@example.com', '2025-01-01T00:00:00Z'),
+		(2, 'STATUS_CHANGED', 'NEW', 'IN_PROGRESS', 'staff', '2025-01-01T06:00:00Z')
+	`)
+	assert.NoError(t, err, "Failed to create test events")
+
+	results, err := testDB.GetAverageTimeInStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, "0d 18h", results["NEW"])
+	assert.Equal(t, "3d 0h", results["IN_PROGRESS"])
+
+	// The current status has no finished stint yet
+	_, exists := results["RESOLVED"]
+	assert.False(t, exists)
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"chalkstone.council/internal/models"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrTooManyImages is returned when adding photos would take an issue over its limit
+	ErrTooManyImages = errors.New("issue has too many images")
+	// ErrImageOrderMismatch is returned when a new order doesn't list each photo of the issue exactly once
+	ErrImageOrderMismatch = errors.New("image order must list every image of the issue once")
+)
+
+// lockIssue holds the issue's row until the transaction ends, so that its photos can be
+// counted and ordered without racing another change. Returns sql.ErrNoRows if the issue
+// does not exist.
+func lockIssue(tx *sql.Tx, issueID int64) error {
+	var id int64
+	return tx.QueryRow(`SELECT id FROM issues WHERE id = $1 FOR UPDATE`, issueID).Scan(&id)
+}
+
+// insertIssueImages adds photos after the issue's existing ones and returns them with
+// their IDs. Callers adding to an existing issue must hold its row lock.
+func insertIssueImages(tx *sql.Tx, issueID int64, images models.IssueImages, uploadedBy string) (models.IssueImages, error) {
+	var next int
+	if err := tx.QueryRow(`
+        SELECT COALESCE(MAX(position) + 1, 0) FROM issue_images WHERE issue_id = $1`,
+		issueID,
+	).Scan(&next); err != nil {
+		return nil, err
+	}
+
+	added := make(models.IssueImages, len(images))
+	for i, image := range images {
+		image.UploadedBy = uploadedBy
+		err := tx.QueryRow(`
+            INSERT INTO issue_images (issue_id, position, original_key, medium_key, thumb_key,
+                                      content_type, size_bytes, checksum, captured_at, uploaded_by)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+            RETURNING id`,
+			issueID,
+			next+i,
+			image.Original,
+			image.Medium,
+			image.Thumb,
+			image.ContentType,
+			sql.NullInt64{Int64: image.Size, Valid: image.Size > 0},
+			sql.NullString{String: image.Checksum, Valid: image.Checksum != ""},
+			image.CapturedAt,
+			uploadedBy,
+		).Scan(&image.ID)
+		if err != nil {
+			return nil, err
+		}
+		added[i] = image
+	}
+	return added, nil
+}
+
+// AddIssueImages appends photos uploaded by a user to an issue and records them in the
+// issue history. A non-nil locationReview flags the issue's location for review, as
+// one of the photos was taken elsewhere. Returns the photos with their IDs,
+// sql.ErrNoRows if the issue does not exist, or ErrTooManyImages if the issue would
+// have more than maxImages photos.
+func (db *DB) AddIssueImages(issueID int64, images models.IssueImages, uploadedBy string, maxImages int, locationReview *string) (models.IssueImages, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx)
+
+	if err := lockIssue(tx, issueID); err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM issue_images WHERE issue_id = $1`, issueID).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count+len(images) > maxImages {
+		return nil, ErrTooManyImages
+	}
+
+	added, err := insertIssueImages(tx, issueID, images, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range added {
+		imageID := strconv.FormatInt(image.ID, 10)
+		if err := insertIssueEvent(tx, issueID, models.EventImageAdded, nil, &imageID, uploadedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	if locationReview != nil {
+		if _, err := tx.Exec(`
+            UPDATE issues SET location_review = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`,
+			issueID, *locationReview); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// DeleteIssueImage removes a photo from an issue and records it in the issue history.
+// Returns the removed photo so its objects can be deleted from the store, or
+// sql.ErrNoRows if the issue has no such photo.
+func (db *DB) DeleteIssueImage(issueID, imageID int64, actor string) (*models.IssueImage, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx)
+
+	image := models.IssueImage{ID: imageID}
+	err = tx.QueryRow(`
+        DELETE FROM issue_images WHERE id = $1 AND issue_id = $2
+        RETURNING original_key, medium_key, thumb_key`,
+		imageID, issueID,
+	).Scan(&image.Original, &image.Medium, &image.Thumb)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := strconv.FormatInt(imageID, 10)
+	if err := insertIssueEvent(tx, issueID, models.EventImageRemoved, &removed, nil, actor); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// ReorderIssueImages puts the photos of an issue in the given order of their IDs.
+// Returns sql.ErrNoRows if the issue does not exist, or ErrImageOrderMismatch unless
+// the order lists each of the issue's photos exactly once.
+func (db *DB) ReorderIssueImages(issueID int64, imageIDs []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	if err := lockIssue(tx, issueID); err != nil {
+		return err
+	}
+
+	var current []int64
+	if err := tx.QueryRow(`
+        SELECT COALESCE(array_agg(id), '{}') FROM issue_images WHERE issue_id = $1`,
+		issueID,
+	).Scan(pq.Array(&current)); err != nil {
+		return err
+	}
+
+	listed := make(map[int64]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		listed[id] = true
+	}
+	if len(imageIDs) != len(current) || len(listed) != len(current) {
+		return ErrImageOrderMismatch
+	}
+	for _, id := range current {
+		if !listed[id] {
+			return ErrImageOrderMismatch
+		}
+	}
+
+	// Positions are only checked for uniqueness at commit, so photos can swap places
+	if _, err := tx.Exec(`
+        UPDATE issue_images im SET position = o.n - 1
+        FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, n)
+        WHERE im.id = o.id AND im.issue_id = $1`,
+		issueID, pq.Array(imageIDs)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
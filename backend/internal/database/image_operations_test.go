@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+// TestIssueImageOperations covers adding, reordering and removing the photos of an issue
+func TestIssueImageOperations(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	id, err := testDB.CreateIssue(&models.IssueCreate{
+		Type: models.TypePothole, Description: "Pothole", ReportedBy: "resident",
+		Images: models.IssueImages{{Original: "a.jpg", Medium: "a-medium.jpg", Thumb: "a-thumb.jpg", ContentType: "image/jpeg"}},
+	})
+	assert.NoError(t, err)
+
+	added, err := testDB.AddIssueImages(id, models.IssueImages{
+		{Original: "b.png", Medium: "b.png", Thumb: "b.png", ContentType: "image/png", Size: 1234, Checksum: "ab12"},
+		{Original: "c.jpg", Medium: "c.jpg", Thumb: "c.jpg", ContentType: "image/jpeg"},
+	}, "staff_user", 3, nil)
+	assert.NoError(t, err)
+	assert.Len(t, added, 2)
+
+	issue, err := testDB.GetIssue(id)
+	assert.NoError(t, err)
+	assert.Nil(t, issue.LocationReview)
+	if assert.Len(t, issue.Images, 3) {
+		assert.Equal(t, "resident", issue.Images[0].UploadedBy)
+		assert.Equal(t, added[0].ID, issue.Images[1].ID)
+		assert.Equal(t, "staff_user", issue.Images[1].UploadedBy)
+		assert.Equal(t, "image/png", issue.Images[1].ContentType)
+		assert.Equal(t, int64(1234), issue.Images[1].Size)
+		assert.Equal(t, "ab12", issue.Images[1].Checksum)
+	}
+
+	// The limit counts the photos already on the issue
+	_, err = testDB.AddIssueImages(id, models.IssueImages{{Original: "d.jpg", Medium: "d.jpg", Thumb: "d.jpg", ContentType: "image/jpeg"}}, "resident", 3, nil)
+	assert.ErrorIs(t, err, ErrTooManyImages)
+
+	_, err = testDB.AddIssueImages(99999, added, "resident", 3, nil)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A photo taken elsewhere flags the issue's location for review
+	review := "Photo 4 was taken 1.2 km from the reported location"
+	_, err = testDB.AddIssueImages(id, models.IssueImages{{Original: "d.jpg", Medium: "d.jpg", Thumb: "d.jpg", ContentType: "image/jpeg"}}, "resident", 4, &review)
+	assert.NoError(t, err)
+	issue, err = testDB.GetIssue(id)
+	assert.NoError(t, err)
+	if assert.NotNil(t, issue.LocationReview) {
+		assert.Equal(t, review, *issue.LocationReview)
+	}
+	_, err = testDB.DeleteIssueImage(id, issue.Images[3].ID, "resident")
+	assert.NoError(t, err)
+
+	// Reorder, swapping the first and last photos
+	first, second, third := issue.Images[0].ID, issue.Images[1].ID, issue.Images[2].ID
+	assert.NoError(t, testDB.ReorderIssueImages(id, []int64{third, second, first}))
+
+	issue, err = testDB.GetIssue(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c.jpg", "b.png", "a.jpg"}, []string{issue.Images[0].Original, issue.Images[1].Original, issue.Images[2].Original})
+
+	// Every photo must be listed once
+	assert.ErrorIs(t, testDB.ReorderIssueImages(id, []int64{third, second}), ErrImageOrderMismatch)
+	assert.ErrorIs(t, testDB.ReorderIssueImages(id, []int64{third, third, first}), ErrImageOrderMismatch)
+
+	// Removing a photo returns its keys for deletion from the store
+	removed, err := testDB.DeleteIssueImage(id, first, "staff_user")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.jpg", "a-medium.jpg", "a-thumb.jpg"}, removed.Keys())
+
+	_, err = testDB.DeleteIssueImage(id, first, "staff_user")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	issue, err = testDB.GetIssue(id)
+	assert.NoError(t, err)
+	assert.Len(t, issue.Images, 2)
+
+	history, err := testDB.GetIssueHistory(id)
+	assert.NoError(t, err)
+	var addedEvents, removedEvents int
+	for _, event := range history {
+		switch event.EventType {
+		case models.EventImageAdded:
+			addedEvents++
+		case models.EventImageRemoved:
+			removedEvents++
+		}
+	}
+	assert.Equal(t, 3, addedEvents)
+	assert.Equal(t, 2, removedEvents)
+}
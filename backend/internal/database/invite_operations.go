@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+// ErrInviteInvalid is returned when an invite code is unknown, expired, already used
+// or was issued to a different email address
+var ErrInviteInvalid = errors.New("invite is invalid")
+
+// CreateStaffInvite stores a new invite
+func (db *DB) CreateStaffInvite(invite *models.StaffInvite) error {
+	if invite == nil {
+		return fmt.Errorf("invite cannot be nil")
+	}
+
+	return db.QueryRow(`
+        INSERT INTO staff_invites (code_hash, email, role, engineer_id, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at`,
+		invite.CodeHash, invite.Email, invite.Role, invite.EngineerID, invite.CreatedBy, invite.ExpiresAt,
+	).Scan(&invite.ID, &invite.CreatedAt)
+}
+
+// ListStaffInvites returns all invites, newest first
+func (db *DB) ListStaffInvites() ([]*models.StaffInvite, error) {
+	rows, err := db.Query(`
+        SELECT id, email, role, engineer_id, created_by, expires_at, redeemed_at, redeemed_by, created_at
+        FROM staff_invites
+        ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	invites := []*models.StaffInvite{}
+	for rows.Next() {
+		var invite models.StaffInvite
+		err := rows.Scan(
+			&invite.ID,
+			&invite.Email,
+			&invite.Role,
+			&invite.EngineerID,
+			&invite.CreatedBy,
+			&invite.ExpiresAt,
+			&invite.RedeemedAt,
+			&invite.RedeemedBy,
+			&invite.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, rows.Err()
+}
+
+// DeleteStaffInvite withdraws an invite that hasn't been redeemed yet.
+// Returns sql.ErrNoRows if there is no such unredeemed invite.
+func (db *DB) DeleteStaffInvite(id int64) error {
+	result, err := db.Exec(`DELETE FROM staff_invites WHERE id = $1 AND redeemed_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RedeemStaffInvite creates the user with the role granted by the invite and marks the
+// invite as used, in one transaction. The account gets the invited email, and engineer
+// invites link it to their engineer. Returns the granted role.
+func (db *DB) RedeemStaffInvite(codeHash, email, username, passwordHash string) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer rollback(tx)
+
+	var id int64
+	var invitedEmail, role string
+	var engineerID sql.NullInt64
+	var expiresAt time.Time
+	var redeemedAt sql.NullTime
+	err = tx.QueryRow(`
+        SELECT id, email, role, engineer_id, expires_at, redeemed_at
+        FROM staff_invites WHERE code_hash = $1
+        FOR UPDATE`,
+		codeHash,
+	).Scan(&id, &invitedEmail, &role, &engineerID, &expiresAt, &redeemedAt)
+	if err == sql.ErrNoRows {
+		return "", ErrInviteInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if redeemedAt.Valid || !expiresAt.After(time.Now()) || !strings.EqualFold(invitedEmail, strings.TrimSpace(email)) {
+		return "", ErrInviteInvalid
+	}
+
+	if _, err := tx.Exec(`
+        INSERT INTO users (username, password_hash, user_type, engineer_id, email)
+        VALUES ($1, $2, $3, $4, $5)`,
+		username, passwordHash, role, engineerID, invitedEmail); err != nil {
+		return "", userWriteError(err)
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE staff_invites SET redeemed_at = NOW(), redeemed_by = $2 WHERE id = $1`,
+		id, username); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return role, nil
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestStaffInvites(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	invite := &models.StaffInvite{
+		CodeHash:  "valid-hash",
+		Email:     "New.Starter@chalkstone.gov.uk",
+		Role:      "dispatcher",
+		CreatedBy: "admin",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, testDB.CreateStaffInvite(invite))
+	assert.NotZero(t, invite.ID)
+
+	expired := &models.StaffInvite{
+		CodeHash:  "expired-hash",
+		Email:     "late@chalkstone.gov.uk",
+		Role:      "dispatcher",
+		CreatedBy: "admin",
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}
+	assert.NoError(t, testDB.CreateStaffInvite(expired))
+
+	// The invite is bound to its email address
+	_, err = testDB.RedeemStaffInvite("valid-hash", "someone.else@example.com", "intruder", "hash")
+	assert.ErrorIs(t, err, ErrInviteInvalid)
+
+	role, err := testDB.RedeemStaffInvite("valid-hash", "new.starter@chalkstone.gov.uk", "new_starter", "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "dispatcher", role)
+
+	user, err := testDB.GetUserByUsername("new_starter")
+	assert.NoError(t, err)
+	assert.Equal(t, "dispatcher", user.UserType)
+	assert.Nil(t, user.EngineerID)
+
+	// Invites are single use
+	_, err = testDB.RedeemStaffInvite("valid-hash", "new.starter@chalkstone.gov.uk", "second_account", "hash")
+	assert.ErrorIs(t, err, ErrInviteInvalid)
+
+	_, err = testDB.RedeemStaffInvite("expired-hash", "late@chalkstone.gov.uk", "late_starter", "hash")
+	assert.ErrorIs(t, err, ErrInviteInvalid)
+	_, err = testDB.RedeemStaffInvite("unknown-hash", "late@chalkstone.gov.uk", "late_starter", "hash")
+	assert.ErrorIs(t, err, ErrInviteInvalid)
+
+	invites, err := testDB.ListStaffInvites()
+	assert.NoError(t, err)
+	assert.Len(t, invites, 2)
+	for _, i := range invites {
+		if i.ID == invite.ID {
+			assert.NotNil(t, i.RedeemedAt)
+			assert.Equal(t, "new_starter", *i.RedeemedBy)
+		}
+	}
+
+	// Only unredeemed invites can be withdrawn
+	assert.ErrorIs(t, testDB.DeleteStaffInvite(invite.ID), sql.ErrNoRows)
+	assert.NoError(t, testDB.DeleteStaffInvite(expired.ID))
+
+	// Engineer invites link the new account to the engineer
+	engineerID, err := testDB.CreateEngineer(&models.EngineerCreate{Name: "Field Engineer", Email: "field@chalkstone.gov.uk"})
+	assert.NoError(t, err)
+	for _, hash := range []string{"engineer-hash", "second-engineer-hash"} {
+		assert.NoError(t, testDB.CreateStaffInvite(&models.StaffInvite{
+			CodeHash:   hash,
+			Email:      "field@chalkstone.gov.uk",
+			Role:       "engineer",
+			EngineerID: &engineerID,
+			CreatedBy:  "admin",
+			ExpiresAt:  time.Now().Add(time.Hour),
+		}))
+	}
+
+	role, err = testDB.RedeemStaffInvite("engineer-hash", "field@chalkstone.gov.uk", "field_engineer", "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "engineer", role)
+
+	user, err = testDB.GetUserByUsername("field_engineer")
+	assert.NoError(t, err)
+	assert.Equal(t, engineerID, *user.EngineerID)
+
+	// Only one account per engineer
+	_, err = testDB.RedeemStaffInvite("second-engineer-hash", "field@chalkstone.gov.uk", "field_engineer_2", "hash")
+	assert.ErrorIs(t, err, ErrEngineerAlreadyLinked)
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"chalkstone.council/internal/models"
@@ -108,7 +110,7 @@ func TestGetIssuesForMapErrors(t *testing.T) {
 	ClearTestData(t, testDB)
 
 	// Test with empty database
-	issues, err := testDB.GetIssuesForMap()
+	issues, err := testDB.GetIssuesForMap(models.MapFilter{})
 	assert.NoError(t, err, "GetIssuesForMap should not fail with empty database")
 	assert.Empty(t, issues, "Issues should be empty for empty database")
 
@@ -117,7 +119,7 @@ func TestGetIssuesForMapErrors(t *testing.T) {
 	assert.NoError(t, err, "Failed to rename issues table")
 
 	// This should fail since the issues table doesn't exist anymore
-	_, err = testDB.GetIssuesForMap()
+	_, err = testDB.GetIssuesForMap(models.MapFilter{})
 	assert.Error(t, err, "GetIssuesForMap should fail when issues table doesn't exist")
 
 	// Restore the table for cleanup
@@ -137,7 +139,7 @@ func TestListIssuesEdgeCases(t *testing.T) {
 	ClearTestData(t, testDB)
 
 	// Test with empty database
-	issues, err := testDB.ListIssues(1, 10)
+	issues, err := testDB.ListIssues(1, 10, models.SortNewest, false)
 	assert.NoError(t, err, "ListIssues should not fail with empty database")
 	assert.Empty(t, issues, "Issues should be empty for empty database")
 
@@ -159,17 +161,17 @@ func TestListIssuesEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to create test issues")
 
 	// Test with issues in the database
-	issuesPage1, err := testDB.ListIssues(1, 10)
+	issuesPage1, err := testDB.ListIssues(1, 10, models.SortNewest, false)
 	assert.NoError(t, err, "ListIssues should not fail")
 	assert.Equal(t, 3, len(issuesPage1), "Should find 3 issues")
 
 	// Test pagination
-	issuesPage2, err := testDB.ListIssues(2, 1)
+	issuesPage2, err := testDB.ListIssues(2, 1, models.SortNewest, false)
 	assert.NoError(t, err, "ListIssues should not fail with pagination")
 	assert.Equal(t, 1, len(issuesPage2), "Should return 1 issue on page 2 with pageSize 1")
 
 	// Test with invalid page and pageSize
-	emptyPage, err := testDB.ListIssues(100, 10) // Page that doesn't exist
+	emptyPage, err := testDB.ListIssues(100, 10, models.SortNewest, false) // Page that doesn't exist
 	assert.NoError(t, err, "ListIssues should not fail with invalid page")
 	assert.Empty(t, emptyPage, "Should return empty result for non-existent page")
 
@@ -180,7 +182,7 @@ func TestListIssuesEdgeCases(t *testing.T) {
 	testIssue := &models.IssueCreate{
 		Type:        "POTHOLE",
 		Description: "Test pothole",
-		Images:      []string{"test-image.jpg"},
+		Images:      models.IssueImages{{Original: "test-image.jpg", Medium: "test-image.jpg", Thumb: "test-image.jpg", ContentType: "image/jpeg"}},
 		ReportedBy:  "test@example.com",
 	}
 	// Set location
@@ -190,7 +192,7 @@ func TestListIssuesEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to create test issue")
 
 	// Test with pageSize of 0 (should use default)
-	allIssues, err := testDB.ListIssues(1, 0)
+	allIssues, err := testDB.ListIssues(1, 0, models.SortNewest, false)
 	assert.NoError(t, err, "ListIssues should not fail with pageSize 0")
 	assert.NotEmpty(t, allIssues, "Should return issues with default pageSize")
 
@@ -199,7 +201,7 @@ func TestListIssuesEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to rename issues table")
 
 	// This should fail since the issues table doesn't exist anymore
-	_, err = testDB.ListIssues(1, 10)
+	_, err = testDB.ListIssues(1, 10, models.SortNewest, false)
 	assert.Error(t, err, "ListIssues should fail when issues table doesn't exist")
 
 	// Restore the table for cleanup
@@ -273,7 +275,7 @@ func TestListEngineersEdgeCases(t *testing.T) {
 	ClearTestData(t, testDB)
 
 	// Test with empty database
-	emptyEngineers, err := testDB.ListEngineers()
+	emptyEngineers, err := testDB.ListEngineers(false)
 	assert.NoError(t, err, "ListEngineers should not fail with empty database")
 	assert.Empty(t, emptyEngineers, "Engineers list should be empty for empty database")
 
@@ -287,7 +289,7 @@ func TestListEngineersEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to create test engineers")
 
 	// Test with engineers in database
-	engineers, err := testDB.ListEngineers()
+	engineers, err := testDB.ListEngineers(false)
 	assert.NoError(t, err, "ListEngineers should not fail with engineers in database")
 	assert.Equal(t, 2, len(engineers), "Should find 2 engineers")
 	assert.Equal(t, "Test Engineer 1", engineers[0].Name, "First engineer name should match")
@@ -298,10 +300,178 @@ func TestListEngineersEdgeCases(t *testing.T) {
 	assert.NoError(t, err, "Failed to rename engineers table")
 
 	// This should fail since the engineers table doesn't exist anymore
-	_, err = testDB.ListEngineers()
+	_, err = testDB.ListEngineers(false)
 	assert.Error(t, err, "ListEngineers should fail when engineers table doesn't exist")
 
 	// Restore the table for cleanup
 	_, err = testDB.DB.Exec(`ALTER TABLE engineers_temp RENAME TO engineers`)
 	assert.NoError(t, err, "Failed to restore engineers table")
 }
+
+// TestListIssuesByReporter checks residents only see their own reports with the engineer name
+func TestListIssuesByReporter(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO engineers (id, name, email, phone, specialization, join_date)
+		VALUES (1, 'Test Engineer', 'test@example.com', '555-1234', 'Pothole Repair', NOW())
+	`)
+	assert.NoError(t, err, "Failed to create test engineer")
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, assigned_to, created_at, updated_at)
+		VALUES
+		(1, 'POTHOLE', 'Mine, assigned', 51.5074, -0.1278, 'resident', 'IN_PROGRESS', 1, NOW(), NOW()),
+		(2, 'GRAFFITI', 'Mine, unassigned', 51.5074, -0.1278, 'resident', 'NEW', NULL, NOW(), NOW() - INTERVAL '1 day'),
+		(3, 'POTHOLE', 'Someone else', 51.5074, -0.1278, 'other', 'NEW', NULL, NOW(), NOW())
+	`)
+	assert.NoError(t, err, "Failed to create test issues")
+
+	issues, err := testDB.ListIssuesByReporter("resident", 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, int64(1), issues[0].ID)
+	assert.Equal(t, "Test Engineer", *issues[0].AssignedEngineerName)
+	assert.Nil(t, issues[1].AssignedEngineerName)
+
+	// Pagination
+	issues, err = testDB.ListIssuesByReporter("resident", 2, 1)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, int64(2), issues[0].ID)
+
+	// Unknown reporter
+	issues, err = testDB.ListIssuesByReporter("nobody", 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestGetIssuesForMapFilters(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	setupTestData(t, testDB)
+
+	// Bounding box around the first two issues only
+	issues, err := testDB.GetIssuesForMap(models.MapFilter{
+		BBox: &models.BoundingBox{MinLon: -0.1285, MinLat: 51.507, MaxLon: -0.1275, MaxLat: 51.5085},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+
+	// Type filter within the same box
+	issues, err = testDB.GetIssuesForMap(models.MapFilter{
+		BBox: &models.BoundingBox{MinLon: -0.1285, MinLat: 51.507, MaxLon: -0.1275, MaxLat: 51.5085},
+		Type: models.TypeGraffiti,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, models.TypeGraffiti, issues[0].Type)
+
+	// The blocked drain is roughly 200m from the pothole
+	lat, lon := 51.5074, -0.1278
+	issues, err = testDB.GetIssuesForMap(models.MapFilter{NearLat: &lat, NearLon: &lon, RadiusMeters: 100})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+
+	issues, err = testDB.GetIssuesForMap(models.MapFilter{NearLat: &lat, NearLon: &lon, RadiusMeters: 500})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 3)
+
+	// Status filter
+	issues, err = testDB.GetIssuesForMap(models.MapFilter{Status: models.StatusClosed})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, models.TypePothole, issues[0].Type)
+}
+
+// TestCreateIssueLocationReview checks that a flagged location and photo capture times
+// are stored with the issue
+func TestCreateIssueLocationReview(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	// Clear any existing data
+	ClearTestData(t, testDB)
+
+	capturedAt := time.Date(2024, 6, 1, 13, 30, 5, 0, time.UTC)
+	review := "Photo 1 was taken 1.2 km from the reported location"
+	issue := &models.IssueCreate{
+		Type:        models.TypePothole,
+		Description: "Pothole",
+		Location: struct {
+			Latitude  float64 `json:"latitude" binding:"required"`
+			Longitude float64 `json:"longitude" binding:"required"`
+		}{50.7184, -3.5339},
+		Images:         models.IssueImages{{Original: "a.jpg", Medium: "a.jpg", Thumb: "a.jpg", ContentType: "image/jpeg", CapturedAt: &capturedAt}},
+		ReportedBy:     "resident",
+		LocationReview: &review,
+	}
+	id, err := testDB.CreateIssue(issue)
+	assert.NoError(t, err)
+
+	stored, err := testDB.GetIssue(id)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored.LocationReview) {
+		assert.Equal(t, review, *stored.LocationReview)
+	}
+	if assert.Len(t, stored.Images, 1) && assert.NotNil(t, stored.Images[0].CapturedAt) {
+		assert.True(t, capturedAt.Equal(*stored.Images[0].CapturedAt))
+	}
+
+	issues, err := testDB.ListIssues(1, 10, models.SortNewest, false)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, review, *issues[0].LocationReview)
+	}
+
+	// Issues without photos taken elsewhere have nothing to review
+	issue.LocationReview = nil
+	unflagged, err := testDB.CreateIssue(issue)
+	assert.NoError(t, err)
+	stored, err = testDB.GetIssue(unflagged)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.LocationReview)
+
+	// Staff can list just the issues to review
+	issues, err = testDB.ListIssues(1, 10, models.SortNewest, true)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, id, issues[0].ID)
+	}
+
+	// and resolve them once the location is checked
+	assert.NoError(t, testDB.ResolveLocationReview(id, "dispatcher_user"))
+	stored, err = testDB.GetIssue(id)
+	assert.NoError(t, err)
+	assert.Nil(t, stored.LocationReview)
+	assert.ErrorIs(t, testDB.ResolveLocationReview(id, "dispatcher_user"), ErrNoLocationReview)
+	assert.ErrorIs(t, testDB.ResolveLocationReview(unflagged, "dispatcher_user"), ErrNoLocationReview)
+	assert.ErrorIs(t, testDB.ResolveLocationReview(99999, "dispatcher_user"), sql.ErrNoRows)
+
+	history, err := testDB.GetIssueHistory(id)
+	assert.NoError(t, err)
+	last := history[len(history)-1]
+	assert.Equal(t, models.EventLocationReviewed, last.EventType)
+	if assert.NotNil(t, last.OldValue) {
+		assert.Equal(t, review, *last.OldValue)
+	}
+	assert.Equal(t, "dispatcher_user", last.Actor)
+
+	issues, err = testDB.ListIssues(1, 10, models.SortNewest, true)
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+
+	"chalkstone.council/internal/models"
+)
+
+// ErrNoLocationReview is returned when resolving the location review of an issue that
+// isn't flagged for one
+var ErrNoLocationReview = errors.New("issue has no location review")
+
+// ResolveLocationReview clears an issue's location review once staff have checked the
+// reported location, recording the reason in the issue history. Returns sql.ErrNoRows
+// if the issue does not exist, or ErrNoLocationReview if it isn't flagged.
+func (db *DB) ResolveLocationReview(issueID int64, actor string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var review sql.NullString
+	if err := tx.QueryRow(`
+        SELECT location_review FROM issues WHERE id = $1 FOR UPDATE`,
+		issueID,
+	).Scan(&review); err != nil {
+		return err
+	}
+	if !review.Valid {
+		return ErrNoLocationReview
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE issues SET location_review = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`,
+		issueID); err != nil {
+		return err
+	}
+
+	if err := insertIssueEvent(tx, issueID, models.EventLocationReviewed, &review.String, nil, actor); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+// GetLoginAttempts returns the failed login tracking of a username, or nil if it has
+// no recent failures
+func (db *DB) GetLoginAttempts(username string) (*models.LoginAttempts, error) {
+	var attempts models.LoginAttempts
+	err := db.QueryRow(`
+        SELECT username, failed_count, last_failed_at, locked_until
+        FROM login_attempts WHERE username = $1`,
+		username,
+	).Scan(&attempts.Username, &attempts.FailedCount, &attempts.LastFailedAt, &attempts.LockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+// RecordFailedLogin counts a failed login for a username. Failures older than the
+// lockout duration, or from before an expired lockout, no longer count. Reaching the
+// policy's maximum locks the username and adds an entry to the lockout audit log.
+// Returns the updated tracking and whether this failure locked the username.
+func (db *DB) RecordFailedLogin(username string, policy models.LoginPolicy) (*models.LoginAttempts, bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer rollback(tx)
+
+	attempts := models.LoginAttempts{Username: username}
+	err = tx.QueryRow(`
+        INSERT INTO login_attempts (username, failed_count, last_failed_at)
+        VALUES ($1, 1, NOW())
+        ON CONFLICT (username) DO UPDATE SET
+            failed_count = CASE
+                WHEN login_attempts.locked_until <= NOW()
+                  OR login_attempts.last_failed_at < NOW() - $2::float8 * INTERVAL '1 second'
+                THEN 1
+                ELSE login_attempts.failed_count + 1
+            END,
+            locked_until = CASE
+                WHEN login_attempts.locked_until <= NOW() THEN NULL
+                ELSE login_attempts.locked_until
+            END,
+            last_failed_at = NOW()
+        RETURNING failed_count, last_failed_at, locked_until`,
+		username, policy.LockoutDuration.Seconds(),
+	).Scan(&attempts.FailedCount, &attempts.LastFailedAt, &attempts.LockedUntil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	locked := false
+	if policy.MaxAttempts > 0 && attempts.LockedUntil == nil && attempts.FailedCount >= policy.MaxAttempts {
+		lockedUntil := attempts.LastFailedAt.Add(policy.LockoutDuration)
+		if _, err := tx.Exec(`
+            UPDATE login_attempts SET locked_until = $2 WHERE username = $1`,
+			username, lockedUntil); err != nil {
+			return nil, false, err
+		}
+		if _, err := tx.Exec(`
+            INSERT INTO account_lockouts (username, failed_count, locked_until)
+            VALUES ($1, $2, $3)`,
+			username, attempts.FailedCount, lockedUntil); err != nil {
+			return nil, false, err
+		}
+		attempts.LockedUntil = &lockedUntil
+		locked = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return &attempts, locked, nil
+}
+
+// ClearLoginAttempts forgets the failed logins of a username after a successful login
+func (db *DB) ClearLoginAttempts(username string) error {
+	_, err := db.Exec(`DELETE FROM login_attempts WHERE username = $1`, username)
+	return err
+}
+
+// UnlockAccount lifts the lockout of a username before it expires and records who
+// lifted it. Returns sql.ErrNoRows if the username isn't locked.
+func (db *DB) UnlockAccount(username, actor string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	result, err := tx.Exec(`
+        DELETE FROM login_attempts
+        WHERE username = $1 AND locked_until > NOW()`,
+		username)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE account_lockouts SET unlocked_by = $2, unlocked_at = NOW()
+        WHERE username = $1 AND locked_until > NOW() AND unlocked_at IS NULL`,
+		username, actor); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListAccountLockouts returns the lockout audit log since the given time, newest first
+func (db *DB) ListAccountLockouts(since time.Time) ([]*models.AccountLockout, error) {
+	rows, err := db.Query(`
+        SELECT id, username, failed_count, locked_until, unlocked_by, unlocked_at, created_at
+        FROM account_lockouts
+        WHERE created_at >= $1
+        ORDER BY created_at DESC, id DESC`,
+		since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lockouts := []*models.AccountLockout{}
+	for rows.Next() {
+		var l models.AccountLockout
+		if err := rows.Scan(&l.ID, &l.Username, &l.FailedCount, &l.LockedUntil, &l.UnlockedBy, &l.UnlockedAt, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, &l)
+	}
+	return lockouts, rows.Err()
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestLoginAttempts(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	policy := models.LoginPolicy{FreeAttempts: 1, BaseDelay: time.Second, MaxAttempts: 3, LockoutDuration: time.Hour}
+
+	attempts, err := testDB.GetLoginAttempts("target")
+	assert.NoError(t, err)
+	assert.Nil(t, attempts)
+
+	for i := 1; i <= 2; i++ {
+		attempts, locked, err := testDB.RecordFailedLogin("target", policy)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.Equal(t, i, attempts.FailedCount)
+		assert.Nil(t, attempts.LockedUntil)
+	}
+
+	// Reaching the maximum locks the username once and records it in the audit log
+	attempts, locked, err := testDB.RecordFailedLogin("target", policy)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.NotNil(t, attempts.LockedUntil)
+
+	stored, err := testDB.GetLoginAttempts("target")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stored.FailedCount)
+	assert.WithinDuration(t, *attempts.LockedUntil, *stored.LockedUntil, time.Second)
+
+	lockouts, err := testDB.ListAccountLockouts(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, lockouts, 1)
+	assert.Equal(t, "target", lockouts[0].Username)
+	assert.Equal(t, 3, lockouts[0].FailedCount)
+
+	// Admins can lift the lockout early
+	assert.NoError(t, testDB.UnlockAccount("target", "admin"))
+	assert.ErrorIs(t, testDB.UnlockAccount("target", "admin"), sql.ErrNoRows)
+
+	stored, err = testDB.GetLoginAttempts("target")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	lockouts, err = testDB.ListAccountLockouts(time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", *lockouts[0].UnlockedBy)
+
+	// An expired lockout starts the count again
+	_, err = testDB.DB.Exec(`
+		INSERT INTO login_attempts (username, failed_count, last_failed_at, locked_until)
+		VALUES ('expired', 3, NOW() - interval '2 hours', NOW() - interval '1 hour')`)
+	assert.NoError(t, err)
+	attempts, locked, err = testDB.RecordFailedLogin("expired", policy)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, 1, attempts.FailedCount)
+	assert.Nil(t, attempts.LockedUntil)
+
+	// A successful login clears the failures
+	assert.NoError(t, testDB.ClearLoginAttempts("expired"))
+	stored, err = testDB.GetLoginAttempts("expired")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"chalkstone.council/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -93,7 +94,7 @@ func (m *mockDB) UpdateIssue(id int64, issue *models.IssueUpdate) error {
 	return nil
 }
 
-func (m *mockDB) ListIssues(page, pageSize int) ([]*models.Issue, error) {
+func (m *mockDB) ListIssues(page, pageSize int, sort models.IssueSort, locationReview bool) ([]*models.Issue, error) {
 	return nil, nil
 }
 
@@ -101,14 +102,90 @@ func (m *mockDB) SearchIssues(issueType, status string) ([]*models.Issue, error)
 	return nil, nil
 }
 
-func (m *mockDB) GetIssuesForMap() ([]*models.Issue, error) {
+func (m *mockDB) GetIssuesForMap(filter models.MapFilter) ([]*models.Issue, error) {
 	return nil, nil
 }
 
-func (m *mockDB) CreateUser(username, passwordHash, userType string) error {
+func (m *mockDB) CreateUser(username, passwordHash, userType string, email *string) error {
 	return nil
 }
 
+func (m *mockDB) UpdatePassword(username, passwordHash string) error {
+	return nil
+}
+
+func (m *mockDB) DeleteUser(username string) error {
+	return nil
+}
+
+func (m *mockDB) CreatePasswordReset(email, tokenHash string, expiresAt time.Time) (*models.User, error) {
+	return nil, nil
+}
+
+func (m *mockDB) GetPasswordResetUsername(tokenHash string) (string, error) {
+	return "", nil
+}
+
+func (m *mockDB) ResetPassword(tokenHash, passwordHash string) (string, error) {
+	return "", nil
+}
+
+func (m *mockDB) GetLoginAttempts(username string) (*models.LoginAttempts, error) {
+	return nil, nil
+}
+
+func (m *mockDB) RecordFailedLogin(username string, policy models.LoginPolicy) (*models.LoginAttempts, bool, error) {
+	return nil, false, nil
+}
+
+func (m *mockDB) ClearLoginAttempts(username string) error {
+	return nil
+}
+
+func (m *mockDB) UnlockAccount(username, actor string) error {
+	return nil
+}
+
+func (m *mockDB) ListAccountLockouts(since time.Time) ([]*models.AccountLockout, error) {
+	return nil, nil
+}
+
+func (m *mockDB) GetTOTPEnrollment(username string) (*models.TOTPEnrollment, error) {
+	return nil, nil
+}
+
+func (m *mockDB) StartTOTPEnrollment(username, secret string) error {
+	return nil
+}
+
+func (m *mockDB) ConfirmTOTPEnrollment(username string, step int64, recoveryCodeHashes []string) error {
+	return nil
+}
+
+func (m *mockDB) UseTOTPStep(username string, step int64) error {
+	return nil
+}
+
+func (m *mockDB) UseRecoveryCode(username, codeHash string) error {
+	return nil
+}
+
+func (m *mockDB) DisableTOTP(username string) error {
+	return nil
+}
+
+func (m *mockDB) CreateOIDCLogin(login *models.OIDCLogin) error {
+	return nil
+}
+
+func (m *mockDB) ConsumeOIDCLogin(stateHash string) (*models.OIDCLogin, error) {
+	return nil, nil
+}
+
+func (m *mockDB) ProvisionOIDCUser(identity *models.OIDCIdentity) (*models.User, error) {
+	return nil, nil
+}
+
 func (m *mockDB) GetUserByUsername(username string) (*models.User, error) {
 	return nil, nil
 }
@@ -117,7 +194,7 @@ func (m *mockDB) GetAverageResolutionTime() (map[string]string, error) {
 	return nil, nil
 }
 
-func (m *mockDB) ListEngineers() ([]*models.Engineer, error) {
+func (m *mockDB) ListEngineers(includeInactive bool) ([]*models.Engineer, error) {
 	return nil, nil
 }
 
@@ -125,7 +202,7 @@ func (m *mockDB) GetEngineerByID(id int64) (*models.Engineer, error) {
 	return nil, nil
 }
 
-func (m *mockDB) GetEngineerPerformance() ([]*models.EngineerPerformance, error) {
+func (m *mockDB) GetEngineerPerformance(includeInactive bool) ([]*models.EngineerPerformance, error) {
 	return nil, nil
 }
 
@@ -133,6 +210,150 @@ func (m *mockDB) GetIssueAnalytics(startDate, endDate string) (map[string]interf
 	return nil, nil
 }
 
+func (m *mockDB) RecordIssueEvent(event *models.IssueEvent) error {
+	return nil
+}
+
+func (m *mockDB) GetIssueHistory(issueID int64) ([]*models.IssueEvent, error) {
+	return nil, nil
+}
+
+func (m *mockDB) GetAverageTimeInStatus() (map[string]string, error) {
+	return nil, nil
+}
+
+func (m *mockDB) CreateComment(comment *models.Comment) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDB) GetComment(id int64) (*models.Comment, error) {
+	return nil, nil
+}
+
+func (m *mockDB) ListComments(issueID int64, includeInternal bool) ([]*models.Comment, error) {
+	return nil, nil
+}
+
+func (m *mockDB) UpdateComment(id int64, body string) error {
+	return nil
+}
+
+func (m *mockDB) DeleteComment(id int64) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockDB) FindDuplicateCandidates(issueType models.IssueType, latitude, longitude, radiusMeters float64, since time.Time) ([]*models.DuplicateCandidate, error) {
+	return nil, nil
+}
+
+func (m *mockDB) MergeIssues(primaryID int64, duplicateIDs []int64, actor string, maxImages int) error {
+	return nil
+}
+
+func (m *mockDB) AddIssueSupporter(issueID int64, userID string) (int, error) {
+	return 0, nil
+}
+
+func (m *mockDB) RemoveIssueSupporter(issueID int64, userID string) (int, error) {
+	return 0, nil
+}
+
+func (m *mockDB) ListOverdueIssues() ([]*models.Issue, error) {
+	return nil, nil
+}
+
+func (m *mockDB) AssignIssue(id, engineerID int64, reason, actor string) error {
+	return nil
+}
+
+func (m *mockDB) CreateEngineer(engineer *models.EngineerCreate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockDB) UpdateEngineer(id int64, update *models.EngineerUpdate) error {
+	return nil
+}
+
+func (m *mockDB) DeactivateEngineer(id int64) error {
+	return nil
+}
+
+func (m *mockDB) CreateRefreshToken(token *models.RefreshToken) error {
+	return nil
+}
+
+func (m *mockDB) RotateRefreshToken(tokenHash string, next *models.RefreshToken) (*models.User, error) {
+	return nil, nil
+}
+
+func (m *mockDB) RevokeRefreshToken(username, tokenHash string) error {
+	return nil
+}
+
+func (m *mockDB) RevokeToken(tokenID string, expiresAt time.Time) error {
+	return nil
+}
+
+func (m *mockDB) RevokeAllSessions(username string) (int, error) {
+	return 0, nil
+}
+
+func (m *mockDB) IsTokenRevoked(tokenID string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockDB) CreateStaffInvite(invite *models.StaffInvite) error {
+	return nil
+}
+
+func (m *mockDB) ListStaffInvites() ([]*models.StaffInvite, error) {
+	return nil, nil
+}
+
+func (m *mockDB) DeleteStaffInvite(id int64) error {
+	return nil
+}
+
+func (m *mockDB) RedeemStaffInvite(codeHash, email, username, passwordHash string) (string, error) {
+	return "", nil
+}
+
+func (m *mockDB) UpdateUserRole(username, role string, engineerID *int64) error {
+	return nil
+}
+
+func (m *mockDB) ListEngineerIssues(engineerID int64, latitude, longitude *float64) ([]*models.WorkItem, error) {
+	return nil, nil
+}
+
+func (m *mockDB) RecordIssueWork(id, engineerID int64, work *models.IssueWork) error {
+	return nil
+}
+
+func (m *mockDB) ListIssuesByReporter(reportedBy string, page, pageSize int) ([]*models.ReportedIssue, error) {
+	return nil, nil
+}
+
+func (m *mockDB) IsIssueReporter(issueID int64, username string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockDB) AddIssueImages(issueID int64, images models.IssueImages, uploadedBy string, maxImages int, locationReview *string) (models.IssueImages, error) {
+	return nil, nil
+}
+
+func (m *mockDB) DeleteIssueImage(issueID, imageID int64, actor string) (*models.IssueImage, error) {
+	return nil, nil
+}
+
+func (m *mockDB) ReorderIssueImages(issueID int64, imageIDs []int64) error {
+	return nil
+}
+
+func (m *mockDB) ResolveLocationReview(issueID int64, actor string) error {
+	return nil
+}
+
 func TestRunMigrations(t *testing.T) {
 	// Test with invalid database type
 	mockDb := &mockDB{nil}
@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=internal/database/operations.go -destination=internal/database/mocks/mock_operations.go -package=mocks
+//	mockgen -source=internal/database/operations.go -destination=/tmp/m.go -package=mocks
 //
 
 // Package mocks is a generated GoMock package.
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	models "chalkstone.council/internal/models"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,123 @@ func (m *MockDatabaseOperations) EXPECT() *MockDatabaseOperationsMockRecorder {
 	return m.recorder
 }
 
+// AddIssueImages mocks base method.
+func (m *MockDatabaseOperations) AddIssueImages(issueID int64, images models.IssueImages, uploadedBy string, maxImages int, locationReview *string) (models.IssueImages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIssueImages", issueID, images, uploadedBy, maxImages, locationReview)
+	ret0, _ := ret[0].(models.IssueImages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddIssueImages indicates an expected call of AddIssueImages.
+func (mr *MockDatabaseOperationsMockRecorder) AddIssueImages(issueID, images, uploadedBy, maxImages, locationReview any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIssueImages", reflect.TypeOf((*MockDatabaseOperations)(nil).AddIssueImages), issueID, images, uploadedBy, maxImages, locationReview)
+}
+
+// AddIssueSupporter mocks base method.
+func (m *MockDatabaseOperations) AddIssueSupporter(issueID int64, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIssueSupporter", issueID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddIssueSupporter indicates an expected call of AddIssueSupporter.
+func (mr *MockDatabaseOperationsMockRecorder) AddIssueSupporter(issueID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIssueSupporter", reflect.TypeOf((*MockDatabaseOperations)(nil).AddIssueSupporter), issueID, userID)
+}
+
+// AssignIssue mocks base method.
+func (m *MockDatabaseOperations) AssignIssue(id, engineerID int64, reason, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignIssue", id, engineerID, reason, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignIssue indicates an expected call of AssignIssue.
+func (mr *MockDatabaseOperationsMockRecorder) AssignIssue(id, engineerID, reason, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignIssue", reflect.TypeOf((*MockDatabaseOperations)(nil).AssignIssue), id, engineerID, reason, actor)
+}
+
+// ClearLoginAttempts mocks base method.
+func (m *MockDatabaseOperations) ClearLoginAttempts(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginAttempts", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginAttempts indicates an expected call of ClearLoginAttempts.
+func (mr *MockDatabaseOperationsMockRecorder) ClearLoginAttempts(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginAttempts", reflect.TypeOf((*MockDatabaseOperations)(nil).ClearLoginAttempts), username)
+}
+
+// ConfirmTOTPEnrollment mocks base method.
+func (m *MockDatabaseOperations) ConfirmTOTPEnrollment(username string, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTPEnrollment", username, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTPEnrollment indicates an expected call of ConfirmTOTPEnrollment.
+func (mr *MockDatabaseOperationsMockRecorder) ConfirmTOTPEnrollment(username, step, recoveryCodeHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTPEnrollment", reflect.TypeOf((*MockDatabaseOperations)(nil).ConfirmTOTPEnrollment), username, step, recoveryCodeHashes)
+}
+
+// ConsumeOIDCLogin mocks base method.
+func (m *MockDatabaseOperations) ConsumeOIDCLogin(stateHash string) (*models.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeOIDCLogin", stateHash)
+	ret0, _ := ret[0].(*models.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeOIDCLogin indicates an expected call of ConsumeOIDCLogin.
+func (mr *MockDatabaseOperationsMockRecorder) ConsumeOIDCLogin(stateHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeOIDCLogin", reflect.TypeOf((*MockDatabaseOperations)(nil).ConsumeOIDCLogin), stateHash)
+}
+
+// CreateComment mocks base method.
+func (m *MockDatabaseOperations) CreateComment(comment *models.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", comment)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockDatabaseOperationsMockRecorder) CreateComment(comment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateComment), comment)
+}
+
+// CreateEngineer mocks base method.
+func (m *MockDatabaseOperations) CreateEngineer(engineer *models.EngineerCreate) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEngineer", engineer)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEngineer indicates an expected call of CreateEngineer.
+func (mr *MockDatabaseOperationsMockRecorder) CreateEngineer(engineer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEngineer", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateEngineer), engineer)
+}
+
 // CreateIssue mocks base method.
 func (m *MockDatabaseOperations) CreateIssue(issue *models.IssueCreate) (int64, error) {
 	m.ctrl.T.Helper()
@@ -55,18 +173,176 @@ func (mr *MockDatabaseOperationsMockRecorder) CreateIssue(issue any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssue", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateIssue), issue)
 }
 
+// CreateOIDCLogin mocks base method.
+func (m *MockDatabaseOperations) CreateOIDCLogin(login *models.OIDCLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLogin", login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOIDCLogin indicates an expected call of CreateOIDCLogin.
+func (mr *MockDatabaseOperationsMockRecorder) CreateOIDCLogin(login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLogin", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateOIDCLogin), login)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockDatabaseOperations) CreatePasswordReset(email, tokenHash string, expiresAt time.Time) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", email, tokenHash, expiresAt)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockDatabaseOperationsMockRecorder) CreatePasswordReset(email, tokenHash, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockDatabaseOperations)(nil).CreatePasswordReset), email, tokenHash, expiresAt)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockDatabaseOperations) CreateRefreshToken(token *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockDatabaseOperationsMockRecorder) CreateRefreshToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateRefreshToken), token)
+}
+
+// CreateStaffInvite mocks base method.
+func (m *MockDatabaseOperations) CreateStaffInvite(invite *models.StaffInvite) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStaffInvite", invite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateStaffInvite indicates an expected call of CreateStaffInvite.
+func (mr *MockDatabaseOperationsMockRecorder) CreateStaffInvite(invite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStaffInvite", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateStaffInvite), invite)
+}
+
 // CreateUser mocks base method.
-func (m *MockDatabaseOperations) CreateUser(username, passwordHash, userType string) error {
+func (m *MockDatabaseOperations) CreateUser(username, passwordHash, userType string, email *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", username, passwordHash, userType)
+	ret := m.ctrl.Call(m, "CreateUser", username, passwordHash, userType, email)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockDatabaseOperationsMockRecorder) CreateUser(username, passwordHash, userType any) *gomock.Call {
+func (mr *MockDatabaseOperationsMockRecorder) CreateUser(username, passwordHash, userType, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateUser), username, passwordHash, userType, email)
+}
+
+// DeactivateEngineer mocks base method.
+func (m *MockDatabaseOperations) DeactivateEngineer(id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateEngineer", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateEngineer indicates an expected call of DeactivateEngineer.
+func (mr *MockDatabaseOperationsMockRecorder) DeactivateEngineer(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDatabaseOperations)(nil).CreateUser), username, passwordHash, userType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateEngineer", reflect.TypeOf((*MockDatabaseOperations)(nil).DeactivateEngineer), id)
+}
+
+// DeleteComment mocks base method.
+func (m *MockDatabaseOperations) DeleteComment(id int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockDatabaseOperationsMockRecorder) DeleteComment(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockDatabaseOperations)(nil).DeleteComment), id)
+}
+
+// DeleteIssueImage mocks base method.
+func (m *MockDatabaseOperations) DeleteIssueImage(issueID, imageID int64, actor string) (*models.IssueImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIssueImage", issueID, imageID, actor)
+	ret0, _ := ret[0].(*models.IssueImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIssueImage indicates an expected call of DeleteIssueImage.
+func (mr *MockDatabaseOperationsMockRecorder) DeleteIssueImage(issueID, imageID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIssueImage", reflect.TypeOf((*MockDatabaseOperations)(nil).DeleteIssueImage), issueID, imageID, actor)
+}
+
+// DeleteStaffInvite mocks base method.
+func (m *MockDatabaseOperations) DeleteStaffInvite(id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaffInvite", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStaffInvite indicates an expected call of DeleteStaffInvite.
+func (mr *MockDatabaseOperationsMockRecorder) DeleteStaffInvite(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaffInvite", reflect.TypeOf((*MockDatabaseOperations)(nil).DeleteStaffInvite), id)
+}
+
+// DeleteUser mocks base method.
+func (m *MockDatabaseOperations) DeleteUser(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockDatabaseOperationsMockRecorder) DeleteUser(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockDatabaseOperations)(nil).DeleteUser), username)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockDatabaseOperations) DisableTOTP(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockDatabaseOperationsMockRecorder) DisableTOTP(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockDatabaseOperations)(nil).DisableTOTP), username)
+}
+
+// FindDuplicateCandidates mocks base method.
+func (m *MockDatabaseOperations) FindDuplicateCandidates(issueType models.IssueType, latitude, longitude, radiusMeters float64, since time.Time) ([]*models.DuplicateCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDuplicateCandidates", issueType, latitude, longitude, radiusMeters, since)
+	ret0, _ := ret[0].([]*models.DuplicateCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDuplicateCandidates indicates an expected call of FindDuplicateCandidates.
+func (mr *MockDatabaseOperationsMockRecorder) FindDuplicateCandidates(issueType, latitude, longitude, radiusMeters, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDuplicateCandidates", reflect.TypeOf((*MockDatabaseOperations)(nil).FindDuplicateCandidates), issueType, latitude, longitude, radiusMeters, since)
 }
 
 // GetAverageResolutionTime mocks base method.
@@ -84,6 +360,36 @@ func (mr *MockDatabaseOperationsMockRecorder) GetAverageResolutionTime() *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAverageResolutionTime", reflect.TypeOf((*MockDatabaseOperations)(nil).GetAverageResolutionTime))
 }
 
+// GetAverageTimeInStatus mocks base method.
+func (m *MockDatabaseOperations) GetAverageTimeInStatus() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAverageTimeInStatus")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAverageTimeInStatus indicates an expected call of GetAverageTimeInStatus.
+func (mr *MockDatabaseOperationsMockRecorder) GetAverageTimeInStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAverageTimeInStatus", reflect.TypeOf((*MockDatabaseOperations)(nil).GetAverageTimeInStatus))
+}
+
+// GetComment mocks base method.
+func (m *MockDatabaseOperations) GetComment(id int64) (*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComment", id)
+	ret0, _ := ret[0].(*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComment indicates an expected call of GetComment.
+func (mr *MockDatabaseOperationsMockRecorder) GetComment(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComment", reflect.TypeOf((*MockDatabaseOperations)(nil).GetComment), id)
+}
+
 // GetEngineerByID mocks base method.
 func (m *MockDatabaseOperations) GetEngineerByID(id int64) (*models.Engineer, error) {
 	m.ctrl.T.Helper()
@@ -100,18 +406,18 @@ func (mr *MockDatabaseOperationsMockRecorder) GetEngineerByID(id any) *gomock.Ca
 }
 
 // GetEngineerPerformance mocks base method.
-func (m *MockDatabaseOperations) GetEngineerPerformance() ([]*models.EngineerPerformance, error) {
+func (m *MockDatabaseOperations) GetEngineerPerformance(includeInactive bool) ([]*models.EngineerPerformance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEngineerPerformance")
+	ret := m.ctrl.Call(m, "GetEngineerPerformance", includeInactive)
 	ret0, _ := ret[0].([]*models.EngineerPerformance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetEngineerPerformance indicates an expected call of GetEngineerPerformance.
-func (mr *MockDatabaseOperationsMockRecorder) GetEngineerPerformance() *gomock.Call {
+func (mr *MockDatabaseOperationsMockRecorder) GetEngineerPerformance(includeInactive any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEngineerPerformance", reflect.TypeOf((*MockDatabaseOperations)(nil).GetEngineerPerformance))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEngineerPerformance", reflect.TypeOf((*MockDatabaseOperations)(nil).GetEngineerPerformance), includeInactive)
 }
 
 // GetIssue mocks base method.
@@ -144,19 +450,79 @@ func (mr *MockDatabaseOperationsMockRecorder) GetIssueAnalytics(startDate, endDa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssueAnalytics", reflect.TypeOf((*MockDatabaseOperations)(nil).GetIssueAnalytics), startDate, endDate)
 }
 
+// GetIssueHistory mocks base method.
+func (m *MockDatabaseOperations) GetIssueHistory(issueID int64) ([]*models.IssueEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIssueHistory", issueID)
+	ret0, _ := ret[0].([]*models.IssueEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIssueHistory indicates an expected call of GetIssueHistory.
+func (mr *MockDatabaseOperationsMockRecorder) GetIssueHistory(issueID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssueHistory", reflect.TypeOf((*MockDatabaseOperations)(nil).GetIssueHistory), issueID)
+}
+
 // GetIssuesForMap mocks base method.
-func (m *MockDatabaseOperations) GetIssuesForMap() ([]*models.Issue, error) {
+func (m *MockDatabaseOperations) GetIssuesForMap(filter models.MapFilter) ([]*models.Issue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIssuesForMap")
+	ret := m.ctrl.Call(m, "GetIssuesForMap", filter)
 	ret0, _ := ret[0].([]*models.Issue)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetIssuesForMap indicates an expected call of GetIssuesForMap.
-func (mr *MockDatabaseOperationsMockRecorder) GetIssuesForMap() *gomock.Call {
+func (mr *MockDatabaseOperationsMockRecorder) GetIssuesForMap(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssuesForMap", reflect.TypeOf((*MockDatabaseOperations)(nil).GetIssuesForMap), filter)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockDatabaseOperations) GetLoginAttempts(username string) (*models.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", username)
+	ret0, _ := ret[0].(*models.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockDatabaseOperationsMockRecorder) GetLoginAttempts(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockDatabaseOperations)(nil).GetLoginAttempts), username)
+}
+
+// GetPasswordResetUsername mocks base method.
+func (m *MockDatabaseOperations) GetPasswordResetUsername(tokenHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetUsername", tokenHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetUsername indicates an expected call of GetPasswordResetUsername.
+func (mr *MockDatabaseOperationsMockRecorder) GetPasswordResetUsername(tokenHash any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIssuesForMap", reflect.TypeOf((*MockDatabaseOperations)(nil).GetIssuesForMap))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetUsername", reflect.TypeOf((*MockDatabaseOperations)(nil).GetPasswordResetUsername), tokenHash)
+}
+
+// GetTOTPEnrollment mocks base method.
+func (m *MockDatabaseOperations) GetTOTPEnrollment(username string) (*models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTPEnrollment", username)
+	ret0, _ := ret[0].(*models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTPEnrollment indicates an expected call of GetTOTPEnrollment.
+func (mr *MockDatabaseOperationsMockRecorder) GetTOTPEnrollment(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTPEnrollment", reflect.TypeOf((*MockDatabaseOperations)(nil).GetTOTPEnrollment), username)
 }
 
 // GetUserByUsername mocks base method.
@@ -174,34 +540,358 @@ func (mr *MockDatabaseOperationsMockRecorder) GetUserByUsername(username any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockDatabaseOperations)(nil).GetUserByUsername), username)
 }
 
+// IsIssueReporter mocks base method.
+func (m *MockDatabaseOperations) IsIssueReporter(issueID int64, username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsIssueReporter", issueID, username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsIssueReporter indicates an expected call of IsIssueReporter.
+func (mr *MockDatabaseOperationsMockRecorder) IsIssueReporter(issueID, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIssueReporter", reflect.TypeOf((*MockDatabaseOperations)(nil).IsIssueReporter), issueID, username)
+}
+
+// IsTokenRevoked mocks base method.
+func (m *MockDatabaseOperations) IsTokenRevoked(tokenID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenRevoked", tokenID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTokenRevoked indicates an expected call of IsTokenRevoked.
+func (mr *MockDatabaseOperationsMockRecorder) IsTokenRevoked(tokenID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenRevoked", reflect.TypeOf((*MockDatabaseOperations)(nil).IsTokenRevoked), tokenID)
+}
+
+// ListAccountLockouts mocks base method.
+func (m *MockDatabaseOperations) ListAccountLockouts(since time.Time) ([]*models.AccountLockout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountLockouts", since)
+	ret0, _ := ret[0].([]*models.AccountLockout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountLockouts indicates an expected call of ListAccountLockouts.
+func (mr *MockDatabaseOperationsMockRecorder) ListAccountLockouts(since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountLockouts", reflect.TypeOf((*MockDatabaseOperations)(nil).ListAccountLockouts), since)
+}
+
+// ListComments mocks base method.
+func (m *MockDatabaseOperations) ListComments(issueID int64, includeInternal bool) ([]*models.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListComments", issueID, includeInternal)
+	ret0, _ := ret[0].([]*models.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListComments indicates an expected call of ListComments.
+func (mr *MockDatabaseOperationsMockRecorder) ListComments(issueID, includeInternal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockDatabaseOperations)(nil).ListComments), issueID, includeInternal)
+}
+
+// ListEngineerIssues mocks base method.
+func (m *MockDatabaseOperations) ListEngineerIssues(engineerID int64, latitude, longitude *float64) ([]*models.WorkItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEngineerIssues", engineerID, latitude, longitude)
+	ret0, _ := ret[0].([]*models.WorkItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEngineerIssues indicates an expected call of ListEngineerIssues.
+func (mr *MockDatabaseOperationsMockRecorder) ListEngineerIssues(engineerID, latitude, longitude any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEngineerIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).ListEngineerIssues), engineerID, latitude, longitude)
+}
+
 // ListEngineers mocks base method.
-func (m *MockDatabaseOperations) ListEngineers() ([]*models.Engineer, error) {
+func (m *MockDatabaseOperations) ListEngineers(includeInactive bool) ([]*models.Engineer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEngineers")
+	ret := m.ctrl.Call(m, "ListEngineers", includeInactive)
 	ret0, _ := ret[0].([]*models.Engineer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListEngineers indicates an expected call of ListEngineers.
-func (mr *MockDatabaseOperationsMockRecorder) ListEngineers() *gomock.Call {
+func (mr *MockDatabaseOperationsMockRecorder) ListEngineers(includeInactive any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEngineers", reflect.TypeOf((*MockDatabaseOperations)(nil).ListEngineers))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEngineers", reflect.TypeOf((*MockDatabaseOperations)(nil).ListEngineers), includeInactive)
 }
 
 // ListIssues mocks base method.
-func (m *MockDatabaseOperations) ListIssues(page, pageSize int) ([]*models.Issue, error) {
+func (m *MockDatabaseOperations) ListIssues(page, pageSize int, sort models.IssueSort, locationReview bool) ([]*models.Issue, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListIssues", page, pageSize)
+	ret := m.ctrl.Call(m, "ListIssues", page, pageSize, sort, locationReview)
 	ret0, _ := ret[0].([]*models.Issue)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListIssues indicates an expected call of ListIssues.
-func (mr *MockDatabaseOperationsMockRecorder) ListIssues(page, pageSize any) *gomock.Call {
+func (mr *MockDatabaseOperationsMockRecorder) ListIssues(page, pageSize, sort, locationReview any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).ListIssues), page, pageSize, sort, locationReview)
+}
+
+// ListIssuesByReporter mocks base method.
+func (m *MockDatabaseOperations) ListIssuesByReporter(reportedBy string, page, pageSize int) ([]*models.ReportedIssue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIssuesByReporter", reportedBy, page, pageSize)
+	ret0, _ := ret[0].([]*models.ReportedIssue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIssuesByReporter indicates an expected call of ListIssuesByReporter.
+func (mr *MockDatabaseOperationsMockRecorder) ListIssuesByReporter(reportedBy, page, pageSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssuesByReporter", reflect.TypeOf((*MockDatabaseOperations)(nil).ListIssuesByReporter), reportedBy, page, pageSize)
+}
+
+// ListOverdueIssues mocks base method.
+func (m *MockDatabaseOperations) ListOverdueIssues() ([]*models.Issue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOverdueIssues")
+	ret0, _ := ret[0].([]*models.Issue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOverdueIssues indicates an expected call of ListOverdueIssues.
+func (mr *MockDatabaseOperationsMockRecorder) ListOverdueIssues() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOverdueIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).ListOverdueIssues))
+}
+
+// ListStaffInvites mocks base method.
+func (m *MockDatabaseOperations) ListStaffInvites() ([]*models.StaffInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStaffInvites")
+	ret0, _ := ret[0].([]*models.StaffInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStaffInvites indicates an expected call of ListStaffInvites.
+func (mr *MockDatabaseOperationsMockRecorder) ListStaffInvites() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStaffInvites", reflect.TypeOf((*MockDatabaseOperations)(nil).ListStaffInvites))
+}
+
+// MergeIssues mocks base method.
+func (m *MockDatabaseOperations) MergeIssues(primaryID int64, duplicateIDs []int64, actor string, maxImages int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeIssues", primaryID, duplicateIDs, actor, maxImages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeIssues indicates an expected call of MergeIssues.
+func (mr *MockDatabaseOperationsMockRecorder) MergeIssues(primaryID, duplicateIDs, actor, maxImages any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).MergeIssues), primaryID, duplicateIDs, actor, maxImages)
+}
+
+// ProvisionOIDCUser mocks base method.
+func (m *MockDatabaseOperations) ProvisionOIDCUser(identity *models.OIDCIdentity) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisionOIDCUser", identity)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProvisionOIDCUser indicates an expected call of ProvisionOIDCUser.
+func (mr *MockDatabaseOperationsMockRecorder) ProvisionOIDCUser(identity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisionOIDCUser", reflect.TypeOf((*MockDatabaseOperations)(nil).ProvisionOIDCUser), identity)
+}
+
+// RecordFailedLogin mocks base method.
+func (m *MockDatabaseOperations) RecordFailedLogin(username string, policy models.LoginPolicy) (*models.LoginAttempts, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedLogin", username, policy)
+	ret0, _ := ret[0].(*models.LoginAttempts)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordFailedLogin indicates an expected call of RecordFailedLogin.
+func (mr *MockDatabaseOperationsMockRecorder) RecordFailedLogin(username, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedLogin", reflect.TypeOf((*MockDatabaseOperations)(nil).RecordFailedLogin), username, policy)
+}
+
+// RecordIssueEvent mocks base method.
+func (m *MockDatabaseOperations) RecordIssueEvent(event *models.IssueEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordIssueEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordIssueEvent indicates an expected call of RecordIssueEvent.
+func (mr *MockDatabaseOperationsMockRecorder) RecordIssueEvent(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordIssueEvent", reflect.TypeOf((*MockDatabaseOperations)(nil).RecordIssueEvent), event)
+}
+
+// RecordIssueWork mocks base method.
+func (m *MockDatabaseOperations) RecordIssueWork(id, engineerID int64, work *models.IssueWork) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordIssueWork", id, engineerID, work)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordIssueWork indicates an expected call of RecordIssueWork.
+func (mr *MockDatabaseOperationsMockRecorder) RecordIssueWork(id, engineerID, work any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordIssueWork", reflect.TypeOf((*MockDatabaseOperations)(nil).RecordIssueWork), id, engineerID, work)
+}
+
+// RedeemStaffInvite mocks base method.
+func (m *MockDatabaseOperations) RedeemStaffInvite(codeHash, email, username, passwordHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemStaffInvite", codeHash, email, username, passwordHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemStaffInvite indicates an expected call of RedeemStaffInvite.
+func (mr *MockDatabaseOperationsMockRecorder) RedeemStaffInvite(codeHash, email, username, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemStaffInvite", reflect.TypeOf((*MockDatabaseOperations)(nil).RedeemStaffInvite), codeHash, email, username, passwordHash)
+}
+
+// RemoveIssueSupporter mocks base method.
+func (m *MockDatabaseOperations) RemoveIssueSupporter(issueID int64, userID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveIssueSupporter", issueID, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveIssueSupporter indicates an expected call of RemoveIssueSupporter.
+func (mr *MockDatabaseOperationsMockRecorder) RemoveIssueSupporter(issueID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveIssueSupporter", reflect.TypeOf((*MockDatabaseOperations)(nil).RemoveIssueSupporter), issueID, userID)
+}
+
+// ReorderIssueImages mocks base method.
+func (m *MockDatabaseOperations) ReorderIssueImages(issueID int64, imageIDs []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderIssueImages", issueID, imageIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderIssueImages indicates an expected call of ReorderIssueImages.
+func (mr *MockDatabaseOperationsMockRecorder) ReorderIssueImages(issueID, imageIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderIssueImages", reflect.TypeOf((*MockDatabaseOperations)(nil).ReorderIssueImages), issueID, imageIDs)
+}
+
+// ResetPassword mocks base method.
+func (m *MockDatabaseOperations) ResetPassword(tokenHash, passwordHash string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", tokenHash, passwordHash)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockDatabaseOperationsMockRecorder) ResetPassword(tokenHash, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockDatabaseOperations)(nil).ResetPassword), tokenHash, passwordHash)
+}
+
+// ResolveLocationReview mocks base method.
+func (m *MockDatabaseOperations) ResolveLocationReview(issueID int64, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveLocationReview", issueID, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveLocationReview indicates an expected call of ResolveLocationReview.
+func (mr *MockDatabaseOperationsMockRecorder) ResolveLocationReview(issueID, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveLocationReview", reflect.TypeOf((*MockDatabaseOperations)(nil).ResolveLocationReview), issueID, actor)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockDatabaseOperations) RevokeAllSessions(username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockDatabaseOperationsMockRecorder) RevokeAllSessions(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockDatabaseOperations)(nil).RevokeAllSessions), username)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockDatabaseOperations) RevokeRefreshToken(username, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", username, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockDatabaseOperationsMockRecorder) RevokeRefreshToken(username, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockDatabaseOperations)(nil).RevokeRefreshToken), username, tokenHash)
+}
+
+// RevokeToken mocks base method.
+func (m *MockDatabaseOperations) RevokeToken(tokenID string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", tokenID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockDatabaseOperationsMockRecorder) RevokeToken(tokenID, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockDatabaseOperations)(nil).RevokeToken), tokenID, expiresAt)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockDatabaseOperations) RotateRefreshToken(tokenHash string, next *models.RefreshToken) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", tokenHash, next)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockDatabaseOperationsMockRecorder) RotateRefreshToken(tokenHash, next any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).ListIssues), page, pageSize)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockDatabaseOperations)(nil).RotateRefreshToken), tokenHash, next)
 }
 
 // SearchIssues mocks base method.
@@ -219,6 +909,62 @@ func (mr *MockDatabaseOperationsMockRecorder) SearchIssues(issueType, status any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchIssues", reflect.TypeOf((*MockDatabaseOperations)(nil).SearchIssues), issueType, status)
 }
 
+// StartTOTPEnrollment mocks base method.
+func (m *MockDatabaseOperations) StartTOTPEnrollment(username, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTOTPEnrollment", username, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartTOTPEnrollment indicates an expected call of StartTOTPEnrollment.
+func (mr *MockDatabaseOperationsMockRecorder) StartTOTPEnrollment(username, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTOTPEnrollment", reflect.TypeOf((*MockDatabaseOperations)(nil).StartTOTPEnrollment), username, secret)
+}
+
+// UnlockAccount mocks base method.
+func (m *MockDatabaseOperations) UnlockAccount(username, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockAccount", username, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockAccount indicates an expected call of UnlockAccount.
+func (mr *MockDatabaseOperationsMockRecorder) UnlockAccount(username, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockAccount", reflect.TypeOf((*MockDatabaseOperations)(nil).UnlockAccount), username, actor)
+}
+
+// UpdateComment mocks base method.
+func (m *MockDatabaseOperations) UpdateComment(id int64, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", id, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockDatabaseOperationsMockRecorder) UpdateComment(id, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockDatabaseOperations)(nil).UpdateComment), id, body)
+}
+
+// UpdateEngineer mocks base method.
+func (m *MockDatabaseOperations) UpdateEngineer(id int64, update *models.EngineerUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEngineer", id, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEngineer indicates an expected call of UpdateEngineer.
+func (mr *MockDatabaseOperationsMockRecorder) UpdateEngineer(id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEngineer", reflect.TypeOf((*MockDatabaseOperations)(nil).UpdateEngineer), id, update)
+}
+
 // UpdateIssue mocks base method.
 func (m *MockDatabaseOperations) UpdateIssue(id int64, update *models.IssueUpdate) error {
 	m.ctrl.T.Helper()
@@ -232,3 +978,59 @@ func (mr *MockDatabaseOperationsMockRecorder) UpdateIssue(id, update any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIssue", reflect.TypeOf((*MockDatabaseOperations)(nil).UpdateIssue), id, update)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockDatabaseOperations) UpdatePassword(username, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", username, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockDatabaseOperationsMockRecorder) UpdatePassword(username, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockDatabaseOperations)(nil).UpdatePassword), username, passwordHash)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockDatabaseOperations) UpdateUserRole(username, role string, engineerID *int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", username, role, engineerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockDatabaseOperationsMockRecorder) UpdateUserRole(username, role, engineerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockDatabaseOperations)(nil).UpdateUserRole), username, role, engineerID)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockDatabaseOperations) UseRecoveryCode(username, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", username, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockDatabaseOperationsMockRecorder) UseRecoveryCode(username, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockDatabaseOperations)(nil).UseRecoveryCode), username, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockDatabaseOperations) UseTOTPStep(username string, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", username, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockDatabaseOperationsMockRecorder) UseTOTPStep(username, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockDatabaseOperations)(nil).UseTOTPStep), username, step)
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+var (
+	// ErrOIDCLoginInvalid is returned when the state of an OIDC callback is unknown,
+	// already used or expired
+	ErrOIDCLoginInvalid = errors.New("OIDC login is invalid or has expired")
+	// ErrNoEngineerForEmail is returned when provisioning an engineer whose email doesn't
+	// belong to any engineer record
+	ErrNoEngineerForEmail = errors.New("no engineer has this email address")
+	// ErrEmailNotVerified is returned when provisioning an engineer whose email the
+	// identity provider hasn't verified
+	ErrEmailNotVerified = errors.New("email address is not verified")
+)
+
+// CreateOIDCLogin stores a login sent to the identity provider, and clears out logins
+// that expired without coming back
+func (db *DB) CreateOIDCLogin(login *models.OIDCLogin) error {
+	if _, err := db.Exec(`DELETE FROM oidc_login_states WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+        INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at)
+        VALUES ($1, $2, $3, $4)`,
+		login.StateHash, login.Nonce, login.CodeVerifier, login.ExpiresAt)
+	return err
+}
+
+// ConsumeOIDCLogin removes and returns the login with the state hash, so a callback can
+// only be used once. Returns ErrOIDCLoginInvalid if it is unknown or expired.
+func (db *DB) ConsumeOIDCLogin(stateHash string) (*models.OIDCLogin, error) {
+	var login models.OIDCLogin
+	err := db.QueryRow(`
+        DELETE FROM oidc_login_states WHERE state_hash = $1
+        RETURNING state_hash, nonce, code_verifier, expires_at`,
+		stateHash,
+	).Scan(&login.StateHash, &login.Nonce, &login.CodeVerifier, &login.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOIDCLoginInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !login.ExpiresAt.After(time.Now()) {
+		return nil, ErrOIDCLoginInvalid
+	}
+	return &login, nil
+}
+
+// ProvisionOIDCUser returns the user linked to the identity, creating it on first login.
+// Users get no password and can only sign in through the identity provider. The role is
+// taken from the identity on every login; when it changes the user's sessions are
+// revoked. Engineers are linked to the engineer with their email, or
+// ErrNoEngineerForEmail is returned; ErrEmailNotVerified if the identity provider
+// hasn't verified the email. A new user whose username is taken by another
+// account gets ErrUsernameTaken.
+func (db *DB) ProvisionOIDCUser(identity *models.OIDCIdentity) (*models.User, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx)
+
+	var engineerID *int64
+	if identity.Role == models.RoleEngineer {
+		if !identity.EmailVerified {
+			return nil, ErrEmailNotVerified
+		}
+		var id int64
+		err := tx.QueryRow(`SELECT id FROM engineers WHERE LOWER(email) = LOWER($1)`, identity.Email).Scan(&id)
+		if err == sql.ErrNoRows {
+			return nil, ErrNoEngineerForEmail
+		}
+		if err != nil {
+			return nil, err
+		}
+		engineerID = &id
+	}
+
+	var user models.User
+	err = tx.QueryRow(`
+        SELECT u.id, u.username, u.password_hash, u.user_type, u.engineer_id, u.email
+        FROM user_identities i JOIN users u ON u.username = i.username
+        WHERE i.issuer = $1 AND i.subject = $2
+        FOR UPDATE OF u`,
+		identity.Issuer, identity.Subject,
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.UserType, &user.EngineerID, &user.Email)
+
+	switch {
+	case err == sql.ErrNoRows:
+		user = models.User{Username: identity.Username, UserType: identity.Role, EngineerID: engineerID}
+		if err := tx.QueryRow(`
+            INSERT INTO users (username, password_hash, user_type, engineer_id)
+            VALUES ($1, '', $2, $3)
+            RETURNING id`,
+			user.Username, user.UserType, user.EngineerID,
+		).Scan(&user.ID); err != nil {
+			return nil, userWriteError(err)
+		}
+		if _, err := tx.Exec(`
+            INSERT INTO user_identities (issuer, subject, username)
+            VALUES ($1, $2, $3)`,
+			identity.Issuer, identity.Subject, user.Username); err != nil {
+			return nil, err
+		}
+
+	case err != nil:
+		return nil, err
+
+	default:
+		if user.UserType != identity.Role || !sameEngineer(user.EngineerID, engineerID) {
+			if _, err := tx.Exec(`
+                UPDATE users SET user_type = $2, engineer_id = $3 WHERE id = $1`,
+				user.ID, identity.Role, engineerID); err != nil {
+				return nil, userWriteError(err)
+			}
+			if _, err := revokeAllSessions(tx, user.Username); err != nil {
+				return nil, err
+			}
+			user.UserType, user.EngineerID = identity.Role, engineerID
+		}
+		if _, err := tx.Exec(`
+            UPDATE user_identities SET last_login_at = NOW()
+            WHERE issuer = $1 AND subject = $2`,
+			identity.Issuer, identity.Subject); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func sameEngineer(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	assert.NoError(t, testDB.CreateOIDCLogin(&models.OIDCLogin{
+		StateHash: "live-state", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: time.Now().Add(10 * time.Minute),
+	}))
+	assert.NoError(t, testDB.CreateOIDCLogin(&models.OIDCLogin{
+		StateHash: "stale-state", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	login, err := testDB.ConsumeOIDCLogin("live-state")
+	assert.NoError(t, err)
+	assert.Equal(t, "nonce", login.Nonce)
+	assert.Equal(t, "verifier", login.CodeVerifier)
+
+	// Each state works once, and not after it expired
+	_, err = testDB.ConsumeOIDCLogin("live-state")
+	assert.ErrorIs(t, err, ErrOIDCLoginInvalid)
+	_, err = testDB.ConsumeOIDCLogin("stale-state")
+	assert.ErrorIs(t, err, ErrOIDCLoginInvalid)
+}
+
+func TestProvisionOIDCUser(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	engineerID, err := testDB.CreateEngineer(&models.EngineerCreate{
+		Name: "Sam Field", Email: "s.field@chalkstone.gov.uk", Phone: "01234 567890", Specialization: "Roads",
+	})
+	assert.NoError(t, err)
+
+	identity := &models.OIDCIdentity{
+		Issuer: "https://login.example", Subject: "sub-1", Username: "sfield",
+		Email: "S.Field@chalkstone.gov.uk", EmailVerified: true, Role: models.RoleDispatcher,
+	}
+
+	// The first login creates a user without a password
+	user, err := testDB.ProvisionOIDCUser(identity)
+	assert.NoError(t, err)
+	assert.Equal(t, "sfield", user.Username)
+	assert.Equal(t, models.RoleDispatcher, user.UserType)
+	assert.Empty(t, user.PasswordHash)
+	assert.Nil(t, user.EngineerID)
+
+	// Later logins find the user by subject even if the username changed on the provider
+	assert.NoError(t, testDB.CreateRefreshToken(&models.RefreshToken{
+		TokenHash: "refresh-hash", Username: "sfield", AccessJTI: "jti-sfield",
+		AccessExpiresAt: time.Now().Add(15 * time.Minute), ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	identity.Username = "sam.field"
+	identity.Role = models.RoleEngineer
+	user, err = testDB.ProvisionOIDCUser(identity)
+	assert.NoError(t, err)
+	assert.Equal(t, "sfield", user.Username)
+	assert.Equal(t, models.RoleEngineer, user.UserType)
+	assert.Equal(t, engineerID, *user.EngineerID)
+
+	// The role change revoked the old sessions
+	_, err = testDB.RotateRefreshToken("refresh-hash", &models.RefreshToken{
+		TokenHash: "next-hash", Username: "sfield", AccessJTI: "jti-next",
+		AccessExpiresAt: time.Now().Add(15 * time.Minute), ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+
+	// Engineers need an engineer record with their email
+	_, err = testDB.ProvisionOIDCUser(&models.OIDCIdentity{
+		Issuer: "https://login.example", Subject: "sub-2", Username: "nobody",
+		Email: "nobody@chalkstone.gov.uk", EmailVerified: true, Role: models.RoleEngineer,
+	})
+	assert.ErrorIs(t, err, ErrNoEngineerForEmail)
+
+	// and the provider must have verified it, or anyone could claim an engineer's email
+	_, err = testDB.ProvisionOIDCUser(&models.OIDCIdentity{
+		Issuer: "https://login.example", Subject: "sub-4", Username: "sfield2",
+		Email: "s.field@chalkstone.gov.uk", Role: models.RoleEngineer,
+	})
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	// A new identity can't take over an existing local account
+	assert.NoError(t, testDB.CreateUser("local_admin", "hash", "admin", nil))
+	_, err = testDB.ProvisionOIDCUser(&models.OIDCIdentity{
+		Issuer: "https://login.example", Subject: "sub-3", Username: "local_admin", Role: models.RoleAdmin,
+	})
+	assert.ErrorIs(t, err, ErrUsernameTaken)
+}
@@ -3,9 +3,12 @@ package database
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"strconv"
+	"time"
 
 	"chalkstone.council/internal/models"
 	"github.com/lib/pq"
@@ -17,16 +20,71 @@ type DatabaseOperations interface {
 	CreateIssue(issue *models.IssueCreate) (int64, error)
 	UpdateIssue(id int64, update *models.IssueUpdate) error
 	GetIssue(id int64) (*models.Issue, error)
-	ListIssues(page, pageSize int) ([]*models.Issue, error)
-	GetIssuesForMap() ([]*models.Issue, error)
+	ListIssues(page, pageSize int, sort models.IssueSort, locationReview bool) ([]*models.Issue, error)
+	GetIssuesForMap(filter models.MapFilter) ([]*models.Issue, error)
 	SearchIssues(issueType, status string) ([]*models.Issue, error)
 	GetIssueAnalytics(startDate, endDate string) (map[string]interface{}, error)
 	GetAverageResolutionTime() (map[string]string, error)
-	GetEngineerPerformance() ([]*models.EngineerPerformance, error)
+	GetEngineerPerformance(includeInactive bool) ([]*models.EngineerPerformance, error)
 	GetUserByUsername(username string) (*models.User, error)
-	CreateUser(username, passwordHash, userType string) error
-	ListEngineers() ([]*models.Engineer, error)
+	CreateUser(username, passwordHash, userType string, email *string) error
+	UpdateUserRole(username, role string, engineerID *int64) error
+	UpdatePassword(username, passwordHash string) error
+	DeleteUser(username string) error
+	CreatePasswordReset(email, tokenHash string, expiresAt time.Time) (*models.User, error)
+	GetPasswordResetUsername(tokenHash string) (string, error)
+	ResetPassword(tokenHash, passwordHash string) (string, error)
+	GetLoginAttempts(username string) (*models.LoginAttempts, error)
+	RecordFailedLogin(username string, policy models.LoginPolicy) (*models.LoginAttempts, bool, error)
+	ClearLoginAttempts(username string) error
+	UnlockAccount(username, actor string) error
+	ListAccountLockouts(since time.Time) ([]*models.AccountLockout, error)
+	GetTOTPEnrollment(username string) (*models.TOTPEnrollment, error)
+	StartTOTPEnrollment(username, secret string) error
+	ConfirmTOTPEnrollment(username string, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(username string, step int64) error
+	UseRecoveryCode(username, codeHash string) error
+	DisableTOTP(username string) error
+	CreateOIDCLogin(login *models.OIDCLogin) error
+	ConsumeOIDCLogin(stateHash string) (*models.OIDCLogin, error)
+	ProvisionOIDCUser(identity *models.OIDCIdentity) (*models.User, error)
+	CreateRefreshToken(token *models.RefreshToken) error
+	RotateRefreshToken(tokenHash string, next *models.RefreshToken) (*models.User, error)
+	RevokeRefreshToken(username, tokenHash string) error
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	RevokeAllSessions(username string) (int, error)
+	IsTokenRevoked(tokenID string) (bool, error)
+	CreateStaffInvite(invite *models.StaffInvite) error
+	ListStaffInvites() ([]*models.StaffInvite, error)
+	DeleteStaffInvite(id int64) error
+	RedeemStaffInvite(codeHash, email, username, passwordHash string) (string, error)
+	ListEngineers(includeInactive bool) ([]*models.Engineer, error)
 	GetEngineerByID(id int64) (*models.Engineer, error)
+	CreateEngineer(engineer *models.EngineerCreate) (int64, error)
+	UpdateEngineer(id int64, update *models.EngineerUpdate) error
+	DeactivateEngineer(id int64) error
+	RecordIssueEvent(event *models.IssueEvent) error
+	GetIssueHistory(issueID int64) ([]*models.IssueEvent, error)
+	GetAverageTimeInStatus() (map[string]string, error)
+	CreateComment(comment *models.Comment) (int64, error)
+	GetComment(id int64) (*models.Comment, error)
+	ListComments(issueID int64, includeInternal bool) ([]*models.Comment, error)
+	UpdateComment(id int64, body string) error
+	DeleteComment(id int64) ([]string, error)
+	FindDuplicateCandidates(issueType models.IssueType, latitude, longitude, radiusMeters float64, since time.Time) ([]*models.DuplicateCandidate, error)
+	MergeIssues(primaryID int64, duplicateIDs []int64, actor string, maxImages int) error
+	AddIssueSupporter(issueID int64, userID string) (int, error)
+	RemoveIssueSupporter(issueID int64, userID string) (int, error)
+	ListOverdueIssues() ([]*models.Issue, error)
+	AssignIssue(id, engineerID int64, reason, actor string) error
+	ListIssuesByReporter(reportedBy string, page, pageSize int) ([]*models.ReportedIssue, error)
+	IsIssueReporter(issueID int64, username string) (bool, error)
+	ListEngineerIssues(engineerID int64, latitude, longitude *float64) ([]*models.WorkItem, error)
+	RecordIssueWork(id, engineerID int64, work *models.IssueWork) error
+	AddIssueImages(issueID int64, images models.IssueImages, uploadedBy string, maxImages int, locationReview *string) (models.IssueImages, error)
+	DeleteIssueImage(issueID, imageID int64, actor string) (*models.IssueImage, error)
+	ReorderIssueImages(issueID int64, imageIDs []int64) error
+	ResolveLocationReview(issueID int64, actor string) error
 }
 
 var _ DatabaseOperations = (*DB)(nil)
@@ -36,30 +94,57 @@ func (db *DB) CreateIssue(issue *models.IssueCreate) (int64, error) {
 		return 0, fmt.Errorf("issue cannot be nil")
 	}
 	
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(tx)
+
 	var id int64
-	err := db.QueryRow(`
-        INSERT INTO issues (type, description, latitude, longitude, images, reported_by, status)
-        VALUES ($1, $2, $3, $4, $5::text[], $6, $7)
+	err = tx.QueryRow(`
+        INSERT INTO issues (type, description, latitude, longitude, reported_by, status, location_review)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING id`,
 		issue.Type,
 		issue.Description,
 		issue.Location.Latitude,
 		issue.Location.Longitude,
-		pq.Array(issue.Images),
 		issue.ReportedBy,
 		models.StatusNew,
+		issue.LocationReview,
 	).Scan(&id)
 
 	if err != nil {
 		return 0, err
 	}
+
+	if _, err := insertIssueImages(tx, id, issue.Images, issue.ReportedBy); err != nil {
+		return 0, err
+	}
+
+	newStatus := string(models.StatusNew)
+	if err := insertIssueEvent(tx, id, models.EventCreated, nil, &newStatus, issue.ReportedBy); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+        INSERT INTO issue_reporters (issue_id, reported_by) VALUES ($1, $2)`,
+		id, issue.ReportedBy); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 func (db *DB) GetIssue(id int64) (*models.Issue, error) {
 	var issue models.Issue
 	err := db.QueryRow(`
         SELECT id, type, status, description, latitude, longitude,
-               images::text[], reported_by, assigned_to, created_at, updated_at
+               `+issueImagesSQL+`, reported_by, assigned_to, priority, due_at, duplicate_of,
+               assignment_reason, resolution_note, after_images::text[], location_review,
+               created_at, updated_at, `+supporterCountSQL+`
         FROM issues WHERE id = $1`,
 		id,
 	).Scan(
@@ -69,11 +154,19 @@ func (db *DB) GetIssue(id int64) (*models.Issue, error) {
 		&issue.Description,
 		&issue.Location.Latitude,
 		&issue.Location.Longitude,
-		pq.Array(&issue.Images),
+		&issue.Images,
 		&issue.ReportedBy,
 		&issue.AssignedTo,
+		&issue.Priority,
+		&issue.DueAt,
+		&issue.DuplicateOf,
+		&issue.AssignmentReason,
+		&issue.ResolutionNote,
+		pq.Array(&issue.AfterImages),
+		&issue.LocationReview,
 		&issue.CreatedAt,
 		&issue.UpdatedAt,
+		&issue.SupporterCount,
 	)
 
 	if err == sql.ErrNoRows {
@@ -85,10 +178,69 @@ func (db *DB) GetIssue(id int64) (*models.Issue, error) {
 	return &issue, nil
 }
 
-func (db *DB) GetIssuesForMap() ([]*models.Issue, error) {
+// supporterCountSQL counts the users who have said they are affected by an issue
+const supporterCountSQL = `(SELECT COUNT(*) FROM issue_supporters s WHERE s.issue_id = issues.id)`
+
+// issueImagesSQL collects the photos of an issue, in display order, as a JSON array
+// of models.IssueImage
+const issueImagesSQL = `(SELECT COALESCE(jsonb_agg(jsonb_build_object(
+				'id', im.id, 'original', im.original_key, 'medium', im.medium_key, 'thumb', im.thumb_key,
+				'captured_at', im.captured_at, 'content_type', im.content_type, 'size', im.size_bytes,
+				'checksum', im.checksum, 'uploaded_by', im.uploaded_by
+			) ORDER BY im.position), '[]'::jsonb)
+			FROM issue_images im WHERE im.issue_id = issues.id)`
+
+// distanceSQL returns an SQL expression for the haversine distance in meters
+// between an issue and the point given by the two placeholders.
+func distanceSQL(lat, lon string) string {
+	return fmt.Sprintf(`(2 * 6371000 * asin(sqrt(
+				power(sin(radians(latitude - %[1]s) / 2), 2) +
+				cos(radians(%[1]s)) * cos(radians(latitude)) *
+				power(sin(radians(longitude - %[2]s) / 2), 2)
+			)))`, lat, lon)
+}
+
+// GetIssuesForMap returns issue locations matching the filter. The bounding box
+// check uses the spatial index on issues; radius searches are narrowed to the
+// enclosing box first and then checked with the haversine distance.
+func (db *DB) GetIssuesForMap(filter models.MapFilter) ([]*models.Issue, error) {
+	box := filter.BBox
+	var nearLat, nearLon *float64
+	if filter.NearLat != nil && filter.NearLon != nil {
+		nearLat, nearLon = filter.NearLat, filter.NearLon
+		around := models.BoundingBoxAround(*nearLat, *nearLon, filter.RadiusMeters)
+		if box != nil {
+			around = models.BoundingBox{
+				MinLon: math.Max(around.MinLon, box.MinLon),
+				MinLat: math.Max(around.MinLat, box.MinLat),
+				MaxLon: math.Min(around.MaxLon, box.MaxLon),
+				MaxLat: math.Min(around.MaxLat, box.MaxLat),
+			}
+			if !around.Valid() {
+				// The radius and the box don't overlap
+				return []*models.Issue{}, nil
+			}
+		}
+		box = &around
+	}
+
+	var minLon, minLat, maxLon, maxLat *float64
+	if box != nil {
+		minLon, minLat, maxLon, maxLat = &box.MinLon, &box.MinLat, &box.MaxLon, &box.MaxLat
+	}
+
 	rows, err := db.Query(`
-		SELECT id, type, latitude, longitude, status
-		FROM issues`)
+		SELECT id, type, latitude, longitude, status, `+supporterCountSQL+`
+		FROM issues
+		WHERE ($1 = '' OR type = $1::issue_type)
+		AND ($2 = '' OR status = $2::issue_status)
+		AND ($3::float8 IS NULL OR
+			point(longitude, latitude) <@ box(point($3::float8, $4::float8), point($5::float8, $6::float8)))
+		AND ($7::float8 IS NULL OR `+distanceSQL("$7::float8", "$8::float8")+` <= $9)`,
+		string(filter.Type), string(filter.Status),
+		minLon, minLat, maxLon, maxLat,
+		nearLat, nearLon, filter.RadiusMeters,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -108,46 +260,131 @@ func (db *DB) GetIssuesForMap() ([]*models.Issue, error) {
 			&issue.Location.Latitude,
 			&issue.Location.Longitude,
 			&issue.Status,
+			&issue.SupporterCount,
 		)
 		if err != nil {
 			return nil, err
 		}
 		issues = append(issues, &issue)
 	}
-	return issues, nil
+	return issues, rows.Err()
+}
+
+// TransitionError is returned when an issue's current status doesn't allow the change
+type TransitionError struct {
+	From models.IssueStatus
+	To   models.IssueStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("issue is %s and cannot move to %s", e.From, e.To)
 }
 
+// ErrNotAssigned is returned when an engineer updates an issue that isn't assigned to them
+var ErrNotAssigned = errors.New("issue is not assigned to the engineer")
+
+// UpdateIssue changes an issue's status, assignee and priority and records each change
+// in its history. The status change and, for engineers, the assignment are checked
+// against the locked row. Returns sql.ErrNoRows if the issue does not exist,
+// ErrNotAssigned, or a *TransitionError if its status doesn't allow the change.
 func (db *DB) UpdateIssue(id int64, update *models.IssueUpdate) error {
 	if update == nil {
 		return fmt.Errorf("update cannot be nil")
 	}
 	
-	result, err := db.Exec(`
+	actor := update.UpdatedBy
+	if actor == "" {
+		actor = "system"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	// Lock the row so the recorded history matches the change actually made
+	var oldStatus models.IssueStatus
+	var oldAssignedTo sql.NullInt64
+	var oldPriority models.IssuePriority
+	err = tx.QueryRow(`
+        SELECT status, assigned_to, priority FROM issues WHERE id = $1 FOR UPDATE`,
+		id,
+	).Scan(&oldStatus, &oldAssignedTo, &oldPriority)
+	if err != nil {
+		return err
+	}
+	if update.EngineerID != nil && (!oldAssignedTo.Valid || oldAssignedTo.Int64 != *update.EngineerID) {
+		return ErrNotAssigned
+	}
+	if update.Status != nil && !models.CanTransition(oldStatus, *update.Status) {
+		return &TransitionError{From: oldStatus, To: *update.Status}
+	}
+
+	// Assigning a new engineer without choosing a new status moves the issue to ASSIGNED
+	reassigned := update.AssignedTo != nil && (!oldAssignedTo.Valid || oldAssignedTo.Int64 != *update.AssignedTo)
+	status := update.Status
+	if reassigned && (status == nil || *status == oldStatus) {
+		assigned, err := advanceToAssigned(tx, id, oldStatus, actor)
+		if err != nil {
+			return err
+		}
+		status = &assigned
+	}
+
+	// Changing the priority recalculates due_at from the SLA targets (see trigger_set_due_at).
+	// A manual assignment replaces any reason left by the assignment engine.
+	_, err = tx.Exec(`
         UPDATE issues
         SET status = COALESCE($1, status),
             assigned_to = COALESCE($2, assigned_to),
+            assignment_reason = CASE WHEN $2 IS NULL THEN assignment_reason END,
+            priority = COALESCE($3, priority),
             updated_at = CURRENT_TIMESTAMP
-        WHERE id = $3`,
-		update.Status,
+        WHERE id = $4`,
+		status,
 		update.AssignedTo,
+		update.Priority,
 		id,
 	)
-
 	if err != nil {
 		return err
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return err
+	if update.Status != nil && *update.Status != oldStatus {
+		oldValue := string(oldStatus)
+		newValue := string(*update.Status)
+		if err := insertIssueEvent(tx, id, models.EventStatusChanged, &oldValue, &newValue, actor); err != nil {
+			return err
+		}
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+
+	if reassigned {
+		var oldValue *string
+		if oldAssignedTo.Valid {
+			v := strconv.FormatInt(oldAssignedTo.Int64, 10)
+			oldValue = &v
+		}
+		newValue := strconv.FormatInt(*update.AssignedTo, 10)
+		if err := insertIssueEvent(tx, id, models.EventAssigned, oldValue, &newValue, actor); err != nil {
+			return err
+		}
+	}
+
+	if update.Priority != nil && *update.Priority != oldPriority {
+		oldValue := string(oldPriority)
+		newValue := string(*update.Priority)
+		if err := insertIssueEvent(tx, id, models.EventPriorityChanged, &oldValue, &newValue, actor); err != nil {
+			return err
+		}
 	}
-	return nil
+
+	return tx.Commit()
 }
 
-func (db *DB) ListIssues(page, pageSize int) ([]*models.Issue, error) {
+// ListIssues returns a page of issues, newest first or with the most supported first,
+// optionally only those whose location needs review
+func (db *DB) ListIssues(page, pageSize int, sort models.IssueSort, locationReview bool) ([]*models.Issue, error) {
 	// Use default pageSize if 0 is provided
 	if pageSize == 0 {
 		pageSize = 10 // Default page size
@@ -156,11 +393,14 @@ func (db *DB) ListIssues(page, pageSize int) ([]*models.Issue, error) {
 	offset := (page - 1) * pageSize
 	rows, err := db.Query(`
         SELECT id, type, status, description, latitude, longitude,
-               images::text[], reported_by, assigned_to, created_at, updated_at
+               `+issueImagesSQL+`, reported_by, assigned_to, priority, due_at, location_review,
+               created_at, updated_at, `+supporterCountSQL+`
         FROM issues
-        ORDER BY created_at DESC
+        WHERE NOT $4 OR location_review IS NOT NULL
+        ORDER BY CASE WHEN $3 = 'supporters' THEN `+supporterCountSQL+` END DESC NULLS LAST,
+                 created_at DESC
         LIMIT $1 OFFSET $2`,
-		pageSize, offset,
+		pageSize, offset, string(sort), locationReview,
 	)
 	if err != nil {
 		return nil, err
@@ -182,11 +422,74 @@ func (db *DB) ListIssues(page, pageSize int) ([]*models.Issue, error) {
 			&issue.Description,
 			&issue.Location.Latitude,
 			&issue.Location.Longitude,
-			pq.Array(&issue.Images),
+			&issue.Images,
+			&issue.ReportedBy,
+			&issue.AssignedTo,
+			&issue.Priority,
+			&issue.DueAt,
+			&issue.LocationReview,
+			&issue.CreatedAt,
+			&issue.UpdatedAt,
+			&issue.SupporterCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, &issue)
+	}
+	return issues, rows.Err()
+}
+
+// ListIssuesByReporter returns a page of the issues reported by a user, including
+// issues their reports were merged into, most recently updated first
+func (db *DB) ListIssuesByReporter(reportedBy string, page, pageSize int) ([]*models.ReportedIssue, error) {
+	// Use default pageSize if 0 is provided
+	if pageSize == 0 {
+		pageSize = 10 // Default page size
+	}
+
+	offset := (page - 1) * pageSize
+	rows, err := db.Query(`
+        SELECT issues.id, issues.type, issues.status, issues.description, issues.latitude, issues.longitude,
+               `+issueImagesSQL+`, issues.reported_by, issues.assigned_to, issues.priority, issues.due_at,
+               issues.created_at, issues.updated_at, `+supporterCountSQL+`,
+               e.name
+        FROM issues
+        LEFT JOIN engineers e ON e.id = issues.assigned_to
+        WHERE EXISTS (SELECT 1 FROM issue_reporters r WHERE r.issue_id = issues.id AND r.reported_by = $1)
+        ORDER BY issues.updated_at DESC, issues.id DESC
+        LIMIT $2 OFFSET $3`,
+		reportedBy, pageSize, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	issues := []*models.ReportedIssue{}
+	for rows.Next() {
+		var issue models.ReportedIssue
+		err := rows.Scan(
+			&issue.ID,
+			&issue.Type,
+			&issue.Status,
+			&issue.Description,
+			&issue.Location.Latitude,
+			&issue.Location.Longitude,
+			&issue.Images,
 			&issue.ReportedBy,
 			&issue.AssignedTo,
+			&issue.Priority,
+			&issue.DueAt,
 			&issue.CreatedAt,
 			&issue.UpdatedAt,
+			&issue.SupporterCount,
+			&issue.AssignedEngineerName,
 		)
 		if err != nil {
 			return nil, err
@@ -198,22 +501,21 @@ func (db *DB) ListIssues(page, pageSize int) ([]*models.Issue, error) {
 
 func (db *DB) SearchIssues(issueType, status string) ([]*models.Issue, error) {
 	// Handle invalid issue type
-	if issueType != "" && issueType != "POTHOLE" && issueType != "STREET_LIGHT" && 
-	   issueType != "GRAFFITI" && issueType != "ANTI_SOCIAL" && 
-	   issueType != "FLY_TIPPING" && issueType != "BLOCKED_DRAIN" {
+	if issueType != "" && !models.ValidateIssueType(models.IssueType(issueType)) {
 		// Invalid issue type, just return empty result
 		return []*models.Issue{}, nil
 	}
 
 	// Handle invalid status
-	if status != "" && status != "NEW" && status != "IN_PROGRESS" && status != "RESOLVED" {
+	if status != "" && !models.ValidateIssueStatus(models.IssueStatus(status)) {
 		// Invalid status, just return empty result
 		return []*models.Issue{}, nil
 	}
 
 	rows, err := db.Query(`
         SELECT id, type, status, description, latitude, longitude,
-               images::text[], reported_by, assigned_to, created_at, updated_at
+               `+issueImagesSQL+`, reported_by, assigned_to, priority, due_at, created_at, updated_at,
+               `+supporterCountSQL+`
         FROM issues
         WHERE ($1 = '' OR type = $1::issue_type)
         AND ($2 = '' OR status = $2::issue_status)
@@ -240,11 +542,14 @@ func (db *DB) SearchIssues(issueType, status string) ([]*models.Issue, error) {
 			&issue.Description,
 			&issue.Location.Latitude,
 			&issue.Location.Longitude,
-			pq.Array(&issue.Images),
+			&issue.Images,
 			&issue.ReportedBy,
 			&issue.AssignedTo,
+			&issue.Priority,
+			&issue.DueAt,
 			&issue.CreatedAt,
 			&issue.UpdatedAt,
+			&issue.SupporterCount,
 		)
 		if err != nil {
 			return nil, err
@@ -254,37 +559,47 @@ func (db *DB) SearchIssues(issueType, status string) ([]*models.Issue, error) {
 	return issues, rows.Err()
 }
 
-func (db *DB) CreateUser(username, passwordHash, userType string) error {
+func (db *DB) CreateUser(username, passwordHash, userType string, email *string) error {
 	_, err := db.Exec(`
-        INSERT INTO users (username, password_hash, user_type)
-        VALUES ($1, $2, $3)`,
-		username, passwordHash, userType)
-	return err
+        INSERT INTO users (username, password_hash, user_type, email)
+        VALUES ($1, $2, $3, $4)`,
+		username, passwordHash, userType, email)
+	return userWriteError(err)
 }
 
 func (db *DB) GetUserByUsername(username string) (*models.User, error) {
 	var user models.User
 	err := db.QueryRow(`
-        SELECT id, username, password_hash, user_type
+        SELECT id, username, password_hash, user_type, engineer_id, email
         FROM users WHERE username = $1`,
-		username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.UserType)
+		username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.UserType, &user.EngineerID, &user.Email)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+// resolvedIssuesSQL selects each resolved or closed issue with the time it took to
+// resolve, up to its latest move to RESOLVED in issue_events. Issues resolved before
+// events were recorded fall back to resolved_at.
+const resolvedIssuesSQL = `
+		SELECT i.type,
+		       EXTRACT(EPOCH FROM (COALESCE(
+		           (SELECT MAX(e.created_at) FROM issue_events e
+		            WHERE e.issue_id = i.id AND e.event_type = 'STATUS_CHANGED' AND e.new_value = 'RESOLVED'),
+		           i.resolved_at) - i.created_at)) AS resolution_time
+		FROM issues i
+		WHERE i.status IN ('RESOLVED', 'CLOSED')`
+
+// GetAverageResolutionTime returns the average time to resolve issues of each type, and
+// over all types as "OVERALL", timed from the resolutions recorded in the issue history
 func (db *DB) GetAverageResolutionTime() (map[string]string, error) {
 	// Get per-type resolution times
-	typeQuery := `
-		SELECT type, 
-		       COALESCE(AVG(EXTRACT(EPOCH FROM (resolved_at - created_at))), 0) AS avg_resolution_time
-		FROM issues
-		WHERE status = 'RESOLVED'
+	rows, err := db.Query(`
+		SELECT type, COALESCE(AVG(resolution_time), 0) AS avg_resolution_time
+		FROM (` + resolvedIssuesSQL + `) resolved
 		GROUP BY type;
-	`
-
-	rows, err := db.Query(typeQuery)
+	`)
 	if err != nil {
 		return nil, err
 	}
@@ -297,14 +612,10 @@ func (db *DB) GetAverageResolutionTime() (map[string]string, error) {
 		if err := rows.Scan(&issueType, &avgTime); err != nil {
 			return nil, err
 		}
-
-		if avgTime < 0 {
-			avgTime = 0
-		}
-
-		days := int(avgTime) / 86400
-		hours := (int(avgTime) % 86400) / 3600
-		resolutionTime[issueType] = fmt.Sprintf("%dd %dh", days, hours)
+		resolutionTime[issueType] = formatDuration(avgTime)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	// Check if we've found any resolved issues so far
@@ -314,37 +625,29 @@ func (db *DB) GetAverageResolutionTime() (map[string]string, error) {
 	}
 
 	// Get overall average resolution time
-	overallQuery := `
-		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (resolved_at - created_at))), 0) AS overall_avg_resolution_time
-		FROM issues
-		WHERE status = 'RESOLVED';
-	`
-
 	var overallAvgTime float64
-	err = db.QueryRow(overallQuery).Scan(&overallAvgTime)
+	err = db.QueryRow(`
+		SELECT COALESCE(AVG(resolution_time), 0) AS overall_avg_resolution_time
+		FROM (` + resolvedIssuesSQL + `) resolved;
+	`).Scan(&overallAvgTime)
 	if err != nil {
 		return nil, err
 	}
-
-	if overallAvgTime < 0 {
-		overallAvgTime = 0
-	}
-
-	days := int(overallAvgTime) / 86400
-	hours := (int(overallAvgTime) % 86400) / 3600
-	resolutionTime["OVERALL"] = fmt.Sprintf("%dd %dh", days, hours)
+	resolutionTime["OVERALL"] = formatDuration(overallAvgTime)
 
 	return resolutionTime, nil
 }
 
-func (db *DB) ListEngineers() ([]*models.Engineer, error) {
+// ListEngineers returns engineers ordered by name. Retired engineers are only included when asked for.
+func (db *DB) ListEngineers(includeInactive bool) ([]*models.Engineer, error) {
 	query := `
-		SELECT id, name, email, phone, specialization, join_date, created_at, updated_at
+		SELECT id, name, email, phone, specialization, join_date, active, created_at, updated_at
 		FROM engineers
+		WHERE $1 OR active
 		ORDER BY name ASC;
 	`
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, includeInactive)
 	if err != nil {
 		return nil, err
 	}
@@ -360,6 +663,7 @@ func (db *DB) ListEngineers() ([]*models.Engineer, error) {
 			&engineer.Phone,
 			&engineer.Specialization,
 			&engineer.JoinDate,
+			&engineer.Active,
 			&engineer.CreatedAt,
 			&engineer.UpdatedAt,
 		)
@@ -374,7 +678,7 @@ func (db *DB) ListEngineers() ([]*models.Engineer, error) {
 
 func (db *DB) GetEngineerByID(id int64) (*models.Engineer, error) {
 	query := `
-		SELECT id, name, email, phone, specialization, join_date, created_at, updated_at
+		SELECT id, name, email, phone, specialization, join_date, active, created_at, updated_at
 		FROM engineers
 		WHERE id = $1;
 	`
@@ -387,6 +691,7 @@ func (db *DB) GetEngineerByID(id int64) (*models.Engineer, error) {
 		&engineer.Phone,
 		&engineer.Specialization,
 		&engineer.JoinDate,
+		&engineer.Active,
 		&engineer.CreatedAt,
 		&engineer.UpdatedAt,
 	)
@@ -400,9 +705,11 @@ func (db *DB) GetEngineerByID(id int64) (*models.Engineer, error) {
 	return engineer, nil
 }
 
-func (db *DB) GetEngineerPerformance() ([]*models.EngineerPerformance, error) {
+// GetEngineerPerformance returns workload and resolution metrics per engineer.
+// Retired engineers are only included when asked for.
+func (db *DB) GetEngineerPerformance(includeInactive bool) ([]*models.EngineerPerformance, error) {
 	// First, get all engineers
-	engineers, err := db.ListEngineers()
+	engineers, err := db.ListEngineers(includeInactive)
 	if err != nil {
 		return nil, fmt.Errorf("error getting engineers: %w", err)
 	}
@@ -412,31 +719,32 @@ func (db *DB) GetEngineerPerformance() ([]*models.EngineerPerformance, error) {
 		SELECT 
 			e.id,
 			-- Resolved issues
-			COUNT(CASE WHEN i.status = 'RESOLVED' THEN 1 END) AS issues_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') THEN 1 END) AS issues_resolved,
 			AVG(EXTRACT(EPOCH FROM (i.resolved_at - i.created_at))) AS avg_resolution_time,
 			-- Resolved issues by type
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'POTHOLE' THEN 1 END) AS pothole_resolved,
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'STREET_LIGHT' THEN 1 END) AS streetlight_resolved,
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'GRAFFITI' THEN 1 END) AS graffiti_resolved,
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'ANTI_SOCIAL' THEN 1 END) AS antisocial_resolved,
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'FLY_TIPPING' THEN 1 END) AS flytipping_resolved,
-			COUNT(CASE WHEN i.status = 'RESOLVED' AND i.type = 'BLOCKED_DRAIN' THEN 1 END) AS blockeddrain_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'POTHOLE' THEN 1 END) AS pothole_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'STREET_LIGHT' THEN 1 END) AS streetlight_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'GRAFFITI' THEN 1 END) AS graffiti_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'ANTI_SOCIAL' THEN 1 END) AS antisocial_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'FLY_TIPPING' THEN 1 END) AS flytipping_resolved,
+			COUNT(CASE WHEN i.status IN ('RESOLVED', 'CLOSED') AND i.type = 'BLOCKED_DRAIN' THEN 1 END) AS blockeddrain_resolved,
 			-- Currently assigned issues
-			COUNT(CASE WHEN i.status != 'RESOLVED' THEN 1 END) AS issues_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') THEN 1 END) AS issues_assigned,
 			-- Currently assigned issues by type
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'POTHOLE' THEN 1 END) AS pothole_assigned,
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'STREET_LIGHT' THEN 1 END) AS streetlight_assigned,
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'GRAFFITI' THEN 1 END) AS graffiti_assigned,
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'ANTI_SOCIAL' THEN 1 END) AS antisocial_assigned,
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'FLY_TIPPING' THEN 1 END) AS flytipping_assigned,
-			COUNT(CASE WHEN i.status != 'RESOLVED' AND i.type = 'BLOCKED_DRAIN' THEN 1 END) AS blockeddrain_assigned
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'POTHOLE' THEN 1 END) AS pothole_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'STREET_LIGHT' THEN 1 END) AS streetlight_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'GRAFFITI' THEN 1 END) AS graffiti_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'ANTI_SOCIAL' THEN 1 END) AS antisocial_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'FLY_TIPPING' THEN 1 END) AS flytipping_assigned,
+			COUNT(CASE WHEN i.status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE') AND i.type = 'BLOCKED_DRAIN' THEN 1 END) AS blockeddrain_assigned
 		FROM engineers e
 		LEFT JOIN issues i ON e.id = i.assigned_to
+		WHERE $1 OR e.active
 		GROUP BY e.id
 		ORDER BY issues_resolved DESC;
 	`
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, includeInactive)
 	if err != nil {
 		return nil, err
 	}
@@ -631,7 +939,7 @@ func (db *DB) GetIssueAnalytics(startDate, endDate string) (map[string]interface
 			   LEFT JOIN (
 			     SELECT to_char(resolved_at, 'YYYY-MM') AS month, COUNT(*) AS count
 			     FROM issues
-			     WHERE status = 'RESOLVED'
+			     WHERE status IN ('RESOLVED', 'CLOSED')
 			     AND resolved_at IS NOT NULL
 			     AND ($1::date IS NULL OR resolved_at >= $1::date)
 			     AND ($2::date IS NULL OR resolved_at <= $2::date)
@@ -679,10 +987,17 @@ func (db *DB) GetIssueAnalytics(startDate, endDate string) (map[string]interface
 		}
 	}
 
+	slaByType, slaByMonth, err := db.getSLACompliance(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching SLA compliance: %v", err)
+	}
+
 	return map[string]interface{}{
 		"total":           totalIssues,
 		"issues_by_type":   issuesByType,
 		"issues_by_status": issuesByStatus,
 		"issues_by_month":  issuesByMonth,
+		"sla_compliance_by_type":  slaByType,
+		"sla_compliance_by_month": slaByMonth,
 	}, nil
 }
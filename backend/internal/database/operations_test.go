@@ -148,6 +148,10 @@ func TestUpdateIssue(t *testing.T) {
 		t.Fatalf("Test issue with ID 1 does not exist")
 	}
 
+	// Only an issue being worked on can be resolved
+	_, err = testDB.DB.Exec(`UPDATE issues SET status = 'IN_PROGRESS' WHERE id = 1`)
+	assert.NoError(t, err, "Failed to start work on issue")
+
 	// Prepare update data
 	resolvedStatus := models.StatusResolved
 	var assignedEngineerID int64 = 1 // Use engineer ID 1 instead of string name
@@ -210,7 +214,7 @@ func TestListIssues(t *testing.T) {
 	assert.Equal(t, 3, count, "There should be exactly 3 issues after seeding")
 
 	// ✅ List Issues
-	issues, err := testDB.ListIssues(1, 10)
+	issues, err := testDB.ListIssues(1, 10, models.SortNewest, false)
 	if err != nil {
 		t.Fatalf("ListIssues failed: %v", err)
 	}
@@ -256,7 +260,7 @@ func TestGetIssuesForMap(t *testing.T) {
 	setupTestData(t, testDB)
 
 	// ✅ Retrieve Issues for Map
-	issues, err := testDB.GetIssuesForMap()
+	issues, err := testDB.GetIssuesForMap(models.MapFilter{})
 	assert.NoError(t, err, "GetIssuesForMap should not fail")
 	assert.Len(t, issues, 3, "Should return 2 issues")
 }
@@ -331,7 +335,7 @@ func TestGetEngineerPerformance(t *testing.T) {
 	assert.Equal(t, 2, count, "Should have 2 issues assigned to engineer ID 1")
 
 	// Get engineer performance for all engineers
-	performanceList, err := testDB.GetEngineerPerformance()
+	performanceList, err := testDB.GetEngineerPerformance(false)
 	assert.NoError(t, err, "GetEngineerPerformance should not error")
 
 	// Verify we have exactly one engineer in the results
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+// ErrResetTokenInvalid is returned for unknown, expired or already used password reset tokens
+var ErrResetTokenInvalid = errors.New("password reset token is invalid")
+
+// CreatePasswordReset stores a reset token for the user with the given email, replacing
+// any unused token they were sent before. Returns the user, or sql.ErrNoRows if no
+// account has the email.
+func (db *DB) CreatePasswordReset(email, tokenHash string, expiresAt time.Time) (*models.User, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx)
+
+	var user models.User
+	err = tx.QueryRow(`
+        SELECT id, username, user_type, email
+        FROM users WHERE LOWER(email) = LOWER($1)
+        FOR UPDATE`,
+		email,
+	).Scan(&user.ID, &user.Username, &user.UserType, &user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+        DELETE FROM password_reset_tokens
+        WHERE username = $1 AND (used_at IS NULL OR expires_at < NOW())`,
+		user.Username); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+        INSERT INTO password_reset_tokens (username, token_hash, expires_at)
+        VALUES ($1, $2, $3)`,
+		user.Username, tokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetPasswordResetUsername returns the user a reset token was issued to, or
+// ErrResetTokenInvalid if the token can't be used
+func (db *DB) GetPasswordResetUsername(tokenHash string) (string, error) {
+	var username string
+	err := db.QueryRow(`
+        SELECT username FROM password_reset_tokens
+        WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()`,
+		tokenHash,
+	).Scan(&username)
+	if err == sql.ErrNoRows {
+		return "", ErrResetTokenInvalid
+	}
+	return username, err
+}
+
+// ResetPassword redeems a reset token: it sets the new password, marks the token as
+// used, revokes the user's sessions and clears failed logins. Returns the username.
+func (db *DB) ResetPassword(tokenHash, passwordHash string) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer rollback(tx)
+
+	var id int64
+	var username string
+	var expiresAt time.Time
+	var usedAt sql.NullTime
+	err = tx.QueryRow(`
+        SELECT id, username, expires_at, used_at
+        FROM password_reset_tokens WHERE token_hash = $1
+        FOR UPDATE`,
+		tokenHash,
+	).Scan(&id, &username, &expiresAt, &usedAt)
+	if err == sql.ErrNoRows {
+		return "", ErrResetTokenInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if usedAt.Valid || !expiresAt.After(time.Now()) {
+		return "", ErrResetTokenInvalid
+	}
+
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1`, id); err != nil {
+		return "", err
+	}
+
+	if err := updatePassword(tx, username, passwordHash); err != nil {
+		return "", err
+	}
+
+	// Proving access to the account's email lifts a lockout
+	if _, err := tx.Exec(`DELETE FROM login_attempts WHERE username = $1`, username); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return username, nil
+}
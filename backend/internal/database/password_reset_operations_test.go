@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordReset(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	email := "Forgetful@example.com"
+	assert.NoError(t, testDB.CreateUser("forgetful_user", "old-hash", "resident", &email))
+
+	// Email addresses match case-insensitively
+	user, err := testDB.CreatePasswordReset("forgetful@example.com", "first-hash", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "forgetful_user", user.Username)
+	assert.Equal(t, email, *user.Email)
+
+	_, err = testDB.CreatePasswordReset("nobody@example.com", "unused-hash", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A new request replaces the token sent before
+	_, err = testDB.CreatePasswordReset(email, "second-hash", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = testDB.ResetPassword("first-hash", "new-hash")
+	assert.ErrorIs(t, err, ErrResetTokenInvalid)
+
+	username, err := testDB.GetPasswordResetUsername("second-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "forgetful_user", username)
+
+	username, err = testDB.ResetPassword("second-hash", "new-hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "forgetful_user", username)
+
+	stored, err := testDB.GetUserByUsername("forgetful_user")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-hash", stored.PasswordHash)
+
+	// Tokens are single use
+	_, err = testDB.ResetPassword("second-hash", "another-hash")
+	assert.ErrorIs(t, err, ErrResetTokenInvalid)
+	_, err = testDB.GetPasswordResetUsername("second-hash")
+	assert.ErrorIs(t, err, ErrResetTokenInvalid)
+
+	_, err = testDB.CreatePasswordReset(email, "expired-hash", time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	_, err = testDB.ResetPassword("expired-hash", "another-hash")
+	assert.ErrorIs(t, err, ErrResetTokenInvalid)
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"os"
 	"time"
 )
@@ -40,3 +41,10 @@ func InitDB() (DatabaseOperations, error) {
 }
 
 var _ DatabaseOperations = (*DB)(nil)
+
+// rollback aborts a transaction, ignoring the error if it was already committed
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		log.Printf("Failed to roll back transaction: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"chalkstone.council/internal/models"
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, expired or logged out refresh tokens
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented
+	// again. Every session of the user is revoked, as the token has probably been stolen.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// CreateRefreshToken stores a new refresh token and clears expired entries from the
+// denylist and the refresh tokens. Rotated tokens are kept until they expire, so that
+// presenting one again is still caught as reuse.
+func (db *DB) CreateRefreshToken(token *models.RefreshToken) error {
+	if token == nil {
+		return fmt.Errorf("refresh token cannot be nil")
+	}
+
+	if _, err := db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	// A token expires before the one that replaced it, so replaced_by never dangles
+	if _, err := db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	return db.QueryRow(`
+        INSERT INTO refresh_tokens (username, token_hash, access_jti, access_expires_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`,
+		token.Username, token.TokenHash, token.AccessJTI, token.AccessExpiresAt, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// RotateRefreshToken exchanges a refresh token for the next one. The old token and the
+// access token issued with it are revoked, and the owning user is returned so a new
+// access token can be signed.
+func (db *DB) RotateRefreshToken(tokenHash string, next *models.RefreshToken) (*models.User, error) {
+	if next == nil {
+		return nil, fmt.Errorf("refresh token cannot be nil")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(tx)
+
+	var current models.RefreshToken
+	var replacedBy sql.NullInt64
+	var user models.User
+	err = tx.QueryRow(`
+        SELECT rt.id, rt.username, rt.access_jti, rt.access_expires_at, rt.expires_at,
+               rt.revoked_at, rt.replaced_by, u.id, u.user_type
+        FROM refresh_tokens rt
+        JOIN users u ON u.username = rt.username
+        WHERE rt.token_hash = $1
+        FOR UPDATE OF rt`,
+		tokenHash,
+	).Scan(
+		&current.ID,
+		&current.Username,
+		&current.AccessJTI,
+		&current.AccessExpiresAt,
+		&current.ExpiresAt,
+		&current.RevokedAt,
+		&replacedBy,
+		&user.ID,
+		&user.UserType,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.Username = current.Username
+
+	if replacedBy.Valid {
+		if _, err := revokeAllSessions(tx, current.Username); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	next.Username = current.Username
+	err = tx.QueryRow(`
+        INSERT INTO refresh_tokens (username, token_hash, access_jti, access_expires_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`,
+		next.Username, next.TokenHash, next.AccessJTI, next.AccessExpiresAt, next.ExpiresAt,
+	).Scan(&next.ID, &next.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+        UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $2 WHERE id = $1`,
+		current.ID, next.ID); err != nil {
+		return nil, err
+	}
+
+	if err := revokeToken(tx, current.AccessJTI, current.AccessExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RevokeRefreshToken logs out a single session of the user. Returns sql.ErrNoRows if
+// the user has no active refresh token with that hash.
+func (db *DB) RevokeRefreshToken(username, tokenHash string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var accessJTI string
+	var accessExpiresAt time.Time
+	err = tx.QueryRow(`
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE username = $1 AND token_hash = $2 AND revoked_at IS NULL
+        RETURNING access_jti, access_expires_at`,
+		username, tokenHash,
+	).Scan(&accessJTI, &accessExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if err := revokeToken(tx, accessJTI, accessExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RevokeToken adds an access token to the denylist until it expires
+func (db *DB) RevokeToken(tokenID string, expiresAt time.Time) error {
+	return revokeToken(db, tokenID, expiresAt)
+}
+
+// RevokeAllSessions revokes every active refresh token of the user and the access
+// tokens issued with them. Returns the number of sessions revoked.
+func (db *DB) RevokeAllSessions(username string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(tx)
+
+	count, err := revokeAllSessions(tx, username)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// IsTokenRevoked reports whether an access token is on the denylist
+func (db *DB) IsTokenRevoked(tokenID string) (bool, error) {
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, tokenID).Scan(&revoked)
+	return revoked, err
+}
+
+func revokeToken(e execer, tokenID string, expiresAt time.Time) error {
+	_, err := e.Exec(`
+        INSERT INTO revoked_tokens (jti, expires_at)
+        VALUES ($1, $2)
+        ON CONFLICT (jti) DO NOTHING`,
+		tokenID, expiresAt,
+	)
+	return err
+}
+
+func revokeAllSessions(tx *sql.Tx, username string) (int, error) {
+	if _, err := tx.Exec(`
+        INSERT INTO revoked_tokens (jti, expires_at)
+        SELECT access_jti, access_expires_at FROM refresh_tokens
+        WHERE username = $1 AND access_expires_at > NOW()
+        ON CONFLICT (jti) DO NOTHING`,
+		username); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+        UPDATE refresh_tokens SET revoked_at = NOW()
+        WHERE username = $1 AND revoked_at IS NULL`,
+		username)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func newTestRefreshToken(hash, jti string) *models.RefreshToken {
+	return &models.RefreshToken{
+		Username:        "session_user",
+		TokenHash:       hash,
+		AccessJTI:       jti,
+		AccessExpiresAt: time.Now().Add(15 * time.Minute),
+		ExpiresAt:       time.Now().Add(24 * time.Hour),
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("session_user", "hash", "admin", nil))
+
+	first := newTestRefreshToken("hash-1", "jti-1")
+	assert.NoError(t, testDB.CreateRefreshToken(first))
+	assert.NotZero(t, first.ID)
+
+	// Rotating returns the owner and revokes the old access token
+	user, err := testDB.RotateRefreshToken("hash-1", newTestRefreshToken("hash-2", "jti-2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "session_user", user.Username)
+	assert.Equal(t, "admin", user.UserType)
+
+	revoked, err := testDB.IsTokenRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = testDB.IsTokenRevoked("jti-2")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Presenting the rotated token again revokes every session
+	_, err = testDB.RotateRefreshToken("hash-1", newTestRefreshToken("hash-3", "jti-3"))
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	revoked, err = testDB.IsTokenRevoked("jti-2")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	_, err = testDB.RotateRefreshToken("hash-2", newTestRefreshToken("hash-4", "jti-4"))
+	assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+
+	_, err = testDB.RotateRefreshToken("unknown", newTestRefreshToken("hash-5", "jti-5"))
+	assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+
+	// Expired refresh tokens can't be used
+	expired := newTestRefreshToken("hash-expired", "jti-expired")
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, testDB.CreateRefreshToken(expired))
+	_, err = testDB.RotateRefreshToken("hash-expired", newTestRefreshToken("hash-6", "jti-6"))
+	assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+}
+
+func TestRevokeSessions(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("session_user", "hash", "resident", nil))
+
+	assert.NoError(t, testDB.CreateRefreshToken(newTestRefreshToken("laptop", "jti-laptop")))
+	assert.NoError(t, testDB.CreateRefreshToken(newTestRefreshToken("phone", "jti-phone")))
+	assert.NoError(t, testDB.CreateRefreshToken(newTestRefreshToken("tablet", "jti-tablet")))
+
+	// Logging out one session
+	assert.NoError(t, testDB.RevokeRefreshToken("session_user", "laptop"))
+	assert.ErrorIs(t, testDB.RevokeRefreshToken("session_user", "laptop"), sql.ErrNoRows)
+	assert.ErrorIs(t, testDB.RevokeRefreshToken("someone_else", "phone"), sql.ErrNoRows)
+
+	revoked, err := testDB.IsTokenRevoked("jti-laptop")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	assert.NoError(t, testDB.RevokeToken("jti-other", time.Now().Add(time.Minute)))
+	assert.NoError(t, testDB.RevokeToken("jti-other", time.Now().Add(time.Minute)))
+
+	// Killing every remaining session
+	count, err := testDB.RevokeAllSessions("session_user")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	for _, jti := range []string{"jti-phone", "jti-tablet"} {
+		revoked, err := testDB.IsTokenRevoked(jti)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	}
+	_, err = testDB.RotateRefreshToken("phone", newTestRefreshToken("phone-2", "jti-phone-2"))
+	assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+}
+
+func TestPruneRefreshTokens(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("session_user", "hash", "resident", nil))
+
+	expired := newTestRefreshToken("expired", "jti-expired")
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, testDB.CreateRefreshToken(expired))
+	assert.NoError(t, testDB.CreateRefreshToken(newTestRefreshToken("rotated", "jti-rotated")))
+	_, err = testDB.RotateRefreshToken("rotated", newTestRefreshToken("current", "jti-current"))
+	assert.NoError(t, err)
+
+	// Starting another session drops the expired token but keeps the rotated one
+	assert.NoError(t, testDB.CreateRefreshToken(newTestRefreshToken("another", "jti-another")))
+
+	var hashes []string
+	rows, err := testDB.DB.Query(`SELECT TRIM(token_hash) FROM refresh_tokens ORDER BY id`)
+	assert.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var hash string
+		assert.NoError(t, rows.Scan(&hash))
+		hashes = append(hashes, hash)
+	}
+	assert.Equal(t, []string{"rotated", "current", "another"}, hashes)
+
+	_, err = testDB.RotateRefreshToken("rotated", newTestRefreshToken("stolen", "jti-stolen"))
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+
+	"chalkstone.council/internal/models"
+)
+
+// ListOverdueIssues returns open issues past their due date, most overdue first
+func (db *DB) ListOverdueIssues() ([]*models.Issue, error) {
+	rows, err := db.Query(`
+        SELECT id, type, status, description, latitude, longitude,
+               ` + issueImagesSQL + `, reported_by, assigned_to, priority, due_at, created_at, updated_at,
+               ` + supporterCountSQL + `
+        FROM issues
+        WHERE due_at < NOW()
+        AND status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE')
+        ORDER BY due_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	issues := []*models.Issue{}
+	for rows.Next() {
+		var issue models.Issue
+		err := rows.Scan(
+			&issue.ID,
+			&issue.Type,
+			&issue.Status,
+			&issue.Description,
+			&issue.Location.Latitude,
+			&issue.Location.Longitude,
+			&issue.Images,
+			&issue.ReportedBy,
+			&issue.AssignedTo,
+			&issue.Priority,
+			&issue.DueAt,
+			&issue.CreatedAt,
+			&issue.UpdatedAt,
+			&issue.SupporterCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, &issue)
+	}
+	return issues, rows.Err()
+}
+
+// getSLACompliance returns the percentage of issues that met their SLA, per issue type and
+// per month of the due date. An issue counts once its outcome is known: it was resolved,
+// or it is still open past its due date.
+func (db *DB) getSLACompliance(start, end interface{}) (map[string]float64, map[string]float64, error) {
+	rows, err := db.Query(`
+        WITH decided AS (
+            SELECT type::text AS issue_type,
+                   to_char(due_at, 'YYYY-MM') AS month,
+                   (status IN ('RESOLVED', 'CLOSED') AND resolved_at IS NOT NULL AND resolved_at <= due_at) AS met
+            FROM issues
+            WHERE due_at IS NOT NULL
+            AND status NOT IN ('REJECTED', 'DUPLICATE')
+            AND ((status IN ('RESOLVED', 'CLOSED') AND resolved_at IS NOT NULL) OR due_at < NOW())
+            AND ($1::date IS NULL OR created_at >= $1::date)
+            AND ($2::date IS NULL OR created_at <= $2::date)
+        )
+        SELECT 'type', issue_type, ROUND(100.0 * COUNT(*) FILTER (WHERE met) / COUNT(*), 1)
+        FROM decided GROUP BY issue_type
+        UNION ALL
+        SELECT 'month', month, ROUND(100.0 * COUNT(*) FILTER (WHERE met) / COUNT(*), 1)
+        FROM decided GROUP BY month`,
+		start, end,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	byType := make(map[string]float64)
+	byMonth := make(map[string]float64)
+	for rows.Next() {
+		var group, key string
+		var percent float64
+		if err := rows.Scan(&group, &key, &percent); err != nil {
+			return nil, nil, err
+		}
+		if group == "type" {
+			byType[key] = percent
+		} else {
+			byMonth[key] = percent
+		}
+	}
+	return byType, byMonth, rows.Err()
+}
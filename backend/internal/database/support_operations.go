@@ -0,0 +1,29 @@
+package database
+
+// AddIssueSupporter records that a user is affected by an issue and returns the new
+// supporter count. Supporting an issue twice has no effect.
+func (db *DB) AddIssueSupporter(issueID int64, userID string) (int, error) {
+	if _, err := db.Exec(`
+        INSERT INTO issue_supporters (issue_id, user_id) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING`,
+		issueID, userID); err != nil {
+		return 0, err
+	}
+	return db.countIssueSupporters(issueID)
+}
+
+// RemoveIssueSupporter withdraws a user's support for an issue and returns the new supporter count
+func (db *DB) RemoveIssueSupporter(issueID int64, userID string) (int, error) {
+	if _, err := db.Exec(`
+        DELETE FROM issue_supporters WHERE issue_id = $1 AND user_id = $2`,
+		issueID, userID); err != nil {
+		return 0, err
+	}
+	return db.countIssueSupporters(issueID)
+}
+
+func (db *DB) countIssueSupporters(issueID int64) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM issue_supporters WHERE issue_id = $1`, issueID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestIssueSupporters(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, created_at) VALUES
+		(1, 'POTHOLE', 'Quiet pothole', 51.5074, -0.1278, 'resident1', 'NEW', NOW()),
+		(2, 'POTHOLE', 'Popular pothole', 51.5075, -0.1279, 'resident2', 'NEW', NOW() - interval '1 day')
+	`)
+	assert.NoError(t, err, "Failed to create test issues")
+
+	count, err := testDB.AddIssueSupporter(2, "resident3")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = testDB.AddIssueSupporter(2, "resident4")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Supporting twice is a no-op
+	count, err = testDB.AddIssueSupporter(2, "resident4")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	issue, err := testDB.GetIssue(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, issue.SupporterCount)
+
+	// Newest first by default, most supported first on request
+	issues, err := testDB.ListIssues(1, 10, models.SortNewest, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), issues[0].ID)
+
+	issues, err = testDB.ListIssues(1, 10, models.SortSupporters, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), issues[0].ID)
+	assert.Equal(t, 2, issues[0].SupporterCount)
+
+	mapIssues, err := testDB.GetIssuesForMap(models.MapFilter{})
+	assert.NoError(t, err)
+	for _, mapIssue := range mapIssues {
+		if mapIssue.ID == 2 {
+			assert.Equal(t, 2, mapIssue.SupporterCount)
+		}
+	}
+
+	count, err = testDB.RemoveIssueSupporter(2, "resident3")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+
+	"chalkstone.council/internal/models"
+)
+
+var (
+	// ErrTwoFactorEnabled is returned when enrolling a user whose enrolment is already confirmed
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPCodeReused is returned for a code from a time step that was already used
+	ErrTOTPCodeReused = errors.New("TOTP code has already been used")
+)
+
+// GetTOTPEnrollment returns the TOTP enrolment of a user, or nil if they have none
+func (db *DB) GetTOTPEnrollment(username string) (*models.TOTPEnrollment, error) {
+	var enrollment models.TOTPEnrollment
+	err := db.QueryRow(`
+        SELECT username, secret, confirmed_at, last_used_step, created_at
+        FROM user_totp WHERE username = $1`,
+		username,
+	).Scan(&enrollment.Username, &enrollment.Secret, &enrollment.ConfirmedAt,
+		&enrollment.LastUsedStep, &enrollment.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// StartTOTPEnrollment stores a new pending secret for the user, replacing a pending one
+// from an earlier attempt. Returns ErrTwoFactorEnabled if the user already confirmed one.
+func (db *DB) StartTOTPEnrollment(username, secret string) error {
+	result, err := db.Exec(`
+        INSERT INTO user_totp (username, secret)
+        VALUES ($1, $2)
+        ON CONFLICT (username) DO UPDATE SET
+            secret = EXCLUDED.secret,
+            last_used_step = NULL,
+            created_at = NOW()
+        WHERE user_totp.confirmed_at IS NULL`,
+		username, secret)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+// ConfirmTOTPEnrollment enables the user's pending enrolment with the time step of the
+// code that confirmed it, and replaces their recovery codes. Returns sql.ErrNoRows if
+// there is no pending enrolment.
+func (db *DB) ConfirmTOTPEnrollment(username string, step int64, recoveryCodeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	result, err := tx.Exec(`
+        UPDATE user_totp SET confirmed_at = NOW(), last_used_step = $2
+        WHERE username = $1 AND confirmed_at IS NULL`,
+		username, step)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE username = $1`, username); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(`
+            INSERT INTO user_recovery_codes (username, code_hash)
+            VALUES ($1, $2)`,
+			username, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records that a code from the given time step was accepted. Returns
+// ErrTOTPCodeReused if that or a later step was already used, so every code works once.
+func (db *DB) UseTOTPStep(username string, step int64) error {
+	result, err := db.Exec(`
+        UPDATE user_totp SET last_used_step = $2
+        WHERE username = $1 AND confirmed_at IS NOT NULL
+          AND (last_used_step IS NULL OR last_used_step < $2)`,
+		username, step)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode marks one of the user's recovery codes as used. Returns sql.ErrNoRows
+// if the code is unknown or was used before.
+func (db *DB) UseRecoveryCode(username, codeHash string) error {
+	result, err := db.Exec(`
+        UPDATE user_recovery_codes SET used_at = NOW()
+        WHERE username = $1 AND code_hash = $2 AND used_at IS NULL`,
+		username, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DisableTOTP removes the user's enrolment and recovery codes. Returns sql.ErrNoRows if
+// they have no enrolment.
+func (db *DB) DisableTOTP(username string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	result, err := tx.Exec(`DELETE FROM user_totp WHERE username = $1`, username)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE username = $1`, username); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
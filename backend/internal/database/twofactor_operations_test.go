@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPEnrollment(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("careful_user", "hash", "dispatcher", nil))
+
+	enrollment, err := testDB.GetTOTPEnrollment("careful_user")
+	assert.NoError(t, err)
+	assert.Nil(t, enrollment)
+
+	// A pending secret can be replaced until it is confirmed
+	assert.NoError(t, testDB.StartTOTPEnrollment("careful_user", "FIRSTSECRET"))
+	assert.NoError(t, testDB.StartTOTPEnrollment("careful_user", "SECONDSECRET"))
+
+	enrollment, err = testDB.GetTOTPEnrollment("careful_user")
+	assert.NoError(t, err)
+	assert.Equal(t, "SECONDSECRET", enrollment.Secret)
+	assert.False(t, enrollment.Enabled())
+
+	assert.ErrorIs(t, testDB.UseTOTPStep("careful_user", 100), ErrTOTPCodeReused)
+
+	assert.NoError(t, testDB.ConfirmTOTPEnrollment("careful_user", 100, []string{"code-hash-1", "code-hash-2"}))
+	assert.ErrorIs(t, testDB.ConfirmTOTPEnrollment("careful_user", 101, nil), sql.ErrNoRows)
+	assert.ErrorIs(t, testDB.StartTOTPEnrollment("careful_user", "THIRDSECRET"), ErrTwoFactorEnabled)
+
+	enrollment, err = testDB.GetTOTPEnrollment("careful_user")
+	assert.NoError(t, err)
+	assert.True(t, enrollment.Enabled())
+	assert.Equal(t, int64(100), *enrollment.LastUsedStep)
+
+	// Codes from the confirming step or earlier can't be replayed
+	assert.ErrorIs(t, testDB.UseTOTPStep("careful_user", 100), ErrTOTPCodeReused)
+	assert.ErrorIs(t, testDB.UseTOTPStep("careful_user", 99), ErrTOTPCodeReused)
+	assert.NoError(t, testDB.UseTOTPStep("careful_user", 101))
+
+	// Recovery codes work once
+	assert.NoError(t, testDB.UseRecoveryCode("careful_user", "code-hash-1"))
+	assert.ErrorIs(t, testDB.UseRecoveryCode("careful_user", "code-hash-1"), sql.ErrNoRows)
+	assert.ErrorIs(t, testDB.UseRecoveryCode("careful_user", "unknown-hash"), sql.ErrNoRows)
+
+	assert.NoError(t, testDB.DisableTOTP("careful_user"))
+	assert.ErrorIs(t, testDB.DisableTOTP("careful_user"), sql.ErrNoRows)
+	assert.ErrorIs(t, testDB.UseRecoveryCode("careful_user", "code-hash-2"), sql.ErrNoRows)
+
+	enrollment, err = testDB.GetTOTPEnrollment("careful_user")
+	assert.NoError(t, err)
+	assert.Nil(t, enrollment)
+}
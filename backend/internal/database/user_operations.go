@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrEngineerAlreadyLinked is returned when another user account already belongs to the engineer
+	ErrEngineerAlreadyLinked = errors.New("engineer is already linked to a user")
+	// ErrEmailTaken is returned when another user account already has the email address
+	ErrEmailTaken = errors.New("email is already in use")
+	// ErrUsernameTaken is returned when another user account already has the username
+	ErrUsernameTaken = errors.New("username is already in use")
+)
+
+// userWriteError maps unique violations on users.engineer_id, users.email and
+// users.username to ErrEngineerAlreadyLinked, ErrEmailTaken and ErrUsernameTaken
+func userWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		switch pqErr.Constraint {
+		case "idx_users_engineer_id":
+			return ErrEngineerAlreadyLinked
+		case "idx_users_email":
+			return ErrEmailTaken
+		case "users_username_key":
+			return ErrUsernameTaken
+		}
+	}
+	return err
+}
+
+// UpdateUserRole changes the role of a user and its engineer link, and revokes the
+// user's sessions so tokens carrying the old role stop working. Returns sql.ErrNoRows
+// if there is no such user.
+func (db *DB) UpdateUserRole(username, role string, engineerID *int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var id int64
+	err = tx.QueryRow(`
+        UPDATE users SET user_type = $2, engineer_id = $3
+        WHERE username = $1
+        RETURNING id`,
+		username, role, engineerID,
+	).Scan(&id)
+	if err != nil {
+		return userWriteError(err)
+	}
+
+	if _, err := revokeAllSessions(tx, username); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePassword sets a new password hash and revokes the user's sessions. Returns
+// sql.ErrNoRows if there is no such user.
+func (db *DB) UpdatePassword(username, passwordHash string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	if err := updatePassword(tx, username, passwordHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteUser deletes a user account. Issues, comments, support and history the user
+// created are kept but attributed to a pseudonym instead of the username. Returns
+// sql.ErrNoRows if there is no such user.
+func (db *DB) DeleteUser(username string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM users WHERE username = $1 FOR UPDATE`, username).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := revokeAllSessions(tx, username); err != nil {
+		return err
+	}
+
+	pseudonym := fmt.Sprintf("deleted-user-%d", id)
+	for _, query := range []string{
+		`UPDATE issues SET reported_by = $2 WHERE reported_by = $1`,
+		`UPDATE issue_reporters SET reported_by = $2 WHERE reported_by = $1`,
+		`UPDATE issue_supporters SET user_id = $2 WHERE user_id = $1`,
+		`UPDATE issue_comments SET author = $2 WHERE author = $1`,
+		`UPDATE issue_images SET uploaded_by = $2 WHERE uploaded_by = $1`,
+		`UPDATE issue_events SET actor = $2 WHERE actor = $1`,
+	} {
+		if _, err := tx.Exec(query, username, pseudonym); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func updatePassword(tx *sql.Tx, username, passwordHash string) error {
+	var id int64
+	err := tx.QueryRow(`
+        UPDATE users SET password_hash = $2
+        WHERE username = $1
+        RETURNING id`,
+		username, passwordHash,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	_, err = revokeAllSessions(tx, username)
+	return err
+}
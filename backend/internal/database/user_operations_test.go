@@ -1,9 +1,13 @@
 package database
 
 import (
+	"database/sql"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"chalkstone.council/internal/models"
 	"chalkstone.council/internal/utils"
 )
 
@@ -20,14 +24,14 @@ func TestCreateAndGetUser(t *testing.T) {
 	// Test data
 	username := "testuser"
 	password := "password123"
-	userType := "public"
+	userType := "resident"
 	
 	// Hash the password
 	passwordHash, err := utils.HashPassword(password)
 	assert.NoError(t, err, "Password hashing should not fail")
 	
 	// Create user
-	err = testDB.CreateUser(username, passwordHash, userType)
+	err = testDB.CreateUser(username, passwordHash, userType, nil)
 	assert.NoError(t, err, "CreateUser should not fail")
 	
 	// Get the user
@@ -89,3 +93,120 @@ func TestGetEngineerByID(t *testing.T) {
 	// We don't assert the exact error since it depends on the database type
 	// Just testing that the error path in GetEngineerByID can be triggered
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("promoted_user", "hash", "resident", nil))
+
+	engineerID, err := testDB.CreateEngineer(&models.EngineerCreate{Name: "Road Engineer", Email: "road@chalkstone.gov.uk"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, testDB.UpdateUserRole("promoted_user", "engineer", &engineerID))
+	user, err := testDB.GetUserByUsername("promoted_user")
+	assert.NoError(t, err)
+	assert.Equal(t, "engineer", user.UserType)
+	assert.Equal(t, engineerID, *user.EngineerID)
+
+	// Engineer accounts must be linked, other roles must not be
+	assert.Error(t, testDB.UpdateUserRole("promoted_user", "engineer", nil))
+	assert.Error(t, testDB.UpdateUserRole("promoted_user", "dispatcher", &engineerID))
+
+	// Only one account per engineer
+	assert.NoError(t, testDB.CreateUser("other_user", "hash", "resident", nil))
+	assert.ErrorIs(t, testDB.UpdateUserRole("other_user", "engineer", &engineerID), ErrEngineerAlreadyLinked)
+
+	assert.ErrorIs(t, testDB.UpdateUserRole("missing_user", "admin", nil), sql.ErrNoRows)
+}
+
+func TestUpdatePassword(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	assert.NoError(t, testDB.CreateUser("changing_user", "old-hash", "resident", nil))
+	assert.NoError(t, testDB.CreateRefreshToken(&models.RefreshToken{
+		Username:        "changing_user",
+		TokenHash:       "session-hash",
+		AccessJTI:       "session-jti",
+		AccessExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt:       time.Now().Add(24 * time.Hour),
+	}))
+
+	assert.NoError(t, testDB.UpdatePassword("changing_user", "new-hash"))
+
+	user, err := testDB.GetUserByUsername("changing_user")
+	assert.NoError(t, err)
+	assert.Equal(t, "new-hash", user.PasswordHash)
+
+	// Existing sessions stop working
+	revoked, err := testDB.IsTokenRevoked("session-jti")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	assert.ErrorIs(t, testDB.UpdatePassword("missing_user", "hash"), sql.ErrNoRows)
+}
+
+func TestDeleteUser(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+	email := "leaving@example.com"
+	assert.NoError(t, testDB.CreateUser("leaving_user", "hash", "resident", &email))
+	user, err := testDB.GetUserByUsername("leaving_user")
+	assert.NoError(t, err)
+
+	issueID, err := testDB.CreateIssue(&models.IssueCreate{
+		Type:        models.TypePothole,
+		Description: "Pothole outside the school",
+		ReportedBy:  "leaving_user",
+		Images:      models.IssueImages{{Original: "a.jpg", Medium: "a.jpg", Thumb: "a.jpg", ContentType: "image/jpeg"}},
+	})
+	assert.NoError(t, err)
+	_, err = testDB.CreateComment(&models.Comment{IssueID: issueID, Author: "leaving_user", Body: "Still there"})
+	assert.NoError(t, err)
+	_, err = testDB.AddIssueSupporter(issueID, "leaving_user")
+	assert.NoError(t, err)
+
+	assert.NoError(t, testDB.DeleteUser("leaving_user"))
+
+	_, err = testDB.GetUserByUsername("leaving_user")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The issue and its history are kept under a pseudonym
+	pseudonym := fmt.Sprintf("deleted-user-%d", user.ID)
+	issue, err := testDB.GetIssue(issueID)
+	assert.NoError(t, err)
+	assert.Equal(t, pseudonym, issue.ReportedBy)
+	assert.Equal(t, 1, issue.SupporterCount)
+	if assert.Len(t, issue.Images, 1) {
+		assert.Equal(t, pseudonym, issue.Images[0].UploadedBy)
+	}
+
+	comments, err := testDB.ListComments(issueID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, pseudonym, comments[0].Author)
+
+	history, err := testDB.GetIssueHistory(issueID)
+	assert.NoError(t, err)
+	for _, event := range history {
+		assert.NotEqual(t, "leaving_user", event.Actor)
+	}
+
+	// The email address can be used again
+	assert.NoError(t, testDB.CreateUser("returning_user", "hash", "resident", &email))
+
+	assert.ErrorIs(t, testDB.DeleteUser("leaving_user"), sql.ErrNoRows)
+}
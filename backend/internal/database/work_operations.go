@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"chalkstone.council/internal/models"
+	"github.com/lib/pq"
+)
+
+// ListEngineerIssues returns the open issues assigned to an engineer, most urgent first.
+// Issues of the same priority are ordered by distance from the given point, if any,
+// and then by due date.
+func (db *DB) ListEngineerIssues(engineerID int64, latitude, longitude *float64) ([]*models.WorkItem, error) {
+	distance := "NULL::float8"
+	args := []interface{}{engineerID}
+	if latitude != nil && longitude != nil {
+		distance = distanceSQL("$2::float8", "$3::float8")
+		args = append(args, *latitude, *longitude)
+	}
+
+	rows, err := db.Query(`
+        SELECT id, type, status, description, latitude, longitude,
+               `+issueImagesSQL+`, reported_by, assigned_to, priority, due_at, created_at, updated_at,
+               `+supporterCountSQL+`, `+distance+` AS distance_meters
+        FROM issues
+        WHERE assigned_to = $1
+        AND status NOT IN ('RESOLVED', 'CLOSED', 'REJECTED', 'DUPLICATE')
+        ORDER BY priority DESC, distance_meters ASC NULLS LAST, due_at ASC NULLS LAST, id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("Failed to close rows: %v", err)
+		}
+	}(rows)
+
+	items := []*models.WorkItem{}
+	for rows.Next() {
+		var item models.WorkItem
+		err := rows.Scan(
+			&item.ID,
+			&item.Type,
+			&item.Status,
+			&item.Description,
+			&item.Location.Latitude,
+			&item.Location.Longitude,
+			&item.Images,
+			&item.ReportedBy,
+			&item.AssignedTo,
+			&item.Priority,
+			&item.DueAt,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.SupporterCount,
+			&item.DistanceMeters,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// RecordIssueWork moves an issue assigned to the engineer to the new status, storing
+// the resolution note and adding the after photos. Returns sql.ErrNoRows if the issue
+// doesn't exist or isn't assigned to the engineer, or a *TransitionError unless the
+// locked issue can move to the new status, which it must not already have.
+func (db *DB) RecordIssueWork(id, engineerID int64, work *models.IssueWork) error {
+	if work == nil {
+		return fmt.Errorf("work cannot be nil")
+	}
+
+	actor := work.UpdatedBy
+	if actor == "" {
+		actor = "system"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer rollback(tx)
+
+	var oldStatus models.IssueStatus
+	err = tx.QueryRow(`
+        SELECT status FROM issues WHERE id = $1 AND assigned_to = $2 FOR UPDATE`,
+		id, engineerID,
+	).Scan(&oldStatus)
+	if err != nil {
+		return err
+	}
+	if !models.CanWorkTransition(oldStatus, work.Status) {
+		return &TransitionError{From: oldStatus, To: work.Status}
+	}
+
+	afterImages := work.AfterImages
+	if afterImages == nil {
+		afterImages = []string{}
+	}
+	_, err = tx.Exec(`
+        UPDATE issues
+        SET status = $1,
+            resolution_note = COALESCE($2, resolution_note),
+            after_images = after_images || $3::text[],
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = $4`,
+		work.Status,
+		work.ResolutionNote,
+		pq.Array(afterImages),
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	if work.Status != oldStatus {
+		oldValue := string(oldStatus)
+		newValue := string(work.Status)
+		if err := insertIssueEvent(tx, id, models.EventStatusChanged, &oldValue, &newValue, actor); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"chalkstone.council/internal/models"
+)
+
+func TestEngineerWorkQueue(t *testing.T) {
+	testDB, cleanup, err := StartTestDB()
+	if err != nil {
+		t.Fatalf("Failed to start test DB: %v", err)
+	}
+	defer cleanup()
+
+	ClearTestData(t, testDB)
+
+	_, err = testDB.DB.Exec(`
+		INSERT INTO engineers (id, name, email, phone, specialization, join_date)
+		VALUES (1, 'Road Engineer', 'roads@example.com', '555-1234', 'Roads and Infrastructure', NOW()),
+		       (2, 'Drain Engineer', 'drains@example.com', '555-5678', 'Drainage Systems', NOW())
+	`)
+	assert.NoError(t, err)
+
+	// Issue 2 is as urgent as issue 1 but closer to the engineer; issue 4 is done
+	// and issue 5 belongs to someone else
+	_, err = testDB.DB.Exec(`
+		INSERT INTO issues (id, type, description, latitude, longitude, reported_by, status, assigned_to, priority)
+		VALUES (1, 'POTHOLE', 'Far pothole', 50.7500, -3.5000, 'resident', 'ASSIGNED', 1, 'HIGH'),
+		       (2, 'POTHOLE', 'Near pothole', 50.7185, -3.5340, 'resident', 'IN_PROGRESS', 1, 'HIGH'),
+		       (3, 'POTHOLE', 'Minor pothole', 50.7184, -3.5339, 'resident', 'ASSIGNED', 1, 'LOW'),
+		       (4, 'POTHOLE', 'Fixed pothole', 50.7184, -3.5339, 'resident', 'RESOLVED', 1, 'URGENT'),
+		       (5, 'BLOCKED_DRAIN', 'Drain', 50.7184, -3.5339, 'resident', 'ASSIGNED', 2, 'URGENT')
+	`)
+	assert.NoError(t, err)
+
+	lat, lon := 50.7184, -3.5339
+	items, err := testDB.ListEngineerIssues(1, &lat, &lon)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, []int64{2, 1, 3}, []int64{items[0].ID, items[1].ID, items[2].ID})
+		assert.Less(t, *items[0].DistanceMeters, 50.0)
+	}
+
+	// Without a location the distance is left out
+	items, err = testDB.ListEngineerIssues(1, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Nil(t, items[0].DistanceMeters)
+
+	note := "Filled and sealed"
+	err = testDB.RecordIssueWork(2, 1, &models.IssueWork{
+		Status:         models.StatusResolved,
+		ResolutionNote: &note,
+		AfterImages:    []string{"http://localhost:9000/issues-bucket/after.jpeg"},
+		UpdatedBy:      "road_engineer",
+	})
+	assert.NoError(t, err)
+
+	issue, err := testDB.GetIssue(2)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusResolved, issue.Status)
+	assert.Equal(t, note, *issue.ResolutionNote)
+	assert.Equal(t, []string{"http://localhost:9000/issues-bucket/after.jpeg"}, issue.AfterImages)
+
+	history, err := testDB.GetIssueHistory(2)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, models.EventStatusChanged, history[0].EventType)
+		assert.Equal(t, "road_engineer", history[0].Actor)
+	}
+
+	// Resolving twice, e.g. from a second tab, is rejected
+	err = testDB.RecordIssueWork(2, 1, &models.IssueWork{
+		Status:         models.StatusResolved,
+		ResolutionNote: &note,
+		UpdatedBy:      "road_engineer",
+	})
+	var transitionErr *TransitionError
+	if assert.ErrorAs(t, err, &transitionErr) {
+		assert.Equal(t, models.StatusResolved, transitionErr.From)
+	}
+	history, err = testDB.GetIssueHistory(2)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// Engineers can only work on their own issues
+	err = testDB.RecordIssueWork(5, 1, &models.IssueWork{Status: models.StatusInProgress, UpdatedBy: "road_engineer"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,320 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+)
+
+// EXIF tags read from the primary image directory
+const (
+	tagOrientation = 0x0112
+	tagExifIFD     = 0x8769
+	tagGPSIFD      = 0x8825
+)
+
+// Tags of the Exif sub-directory
+const (
+	tagDateTimeOriginal   = 0x9003
+	tagDateTimeDigitized  = 0x9004
+	tagOffsetTimeOriginal = 0x9011
+)
+
+// Tags of the GPS sub-directory
+const (
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+)
+
+// exifTimeLayout is the format of EXIF date and time values
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// Metadata is what we keep from a photo's EXIF data before it is stripped
+type Metadata struct {
+	// Orientation is the EXIF orientation, 1 to 8, or 0 if the photo has none
+	Orientation int
+	// Location is where the photo was taken, if the camera recorded it
+	Location *Location
+	// CapturedAt is when the photo was taken, if the camera recorded it
+	CapturedAt *time.Time
+}
+
+// Location is a position in decimal degrees
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// tiffReader reads directories of the TIFF structure that EXIF data is stored in
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type ifdEntry struct {
+	tag, typ uint16
+	count    uint32
+	// value holds the value itself if it fits in four bytes, otherwise its offset
+	value []byte
+}
+
+// ReadMetadata extracts the EXIF metadata of a JPEG file. Files without EXIF data, or
+// with data we can't read, give empty metadata.
+func ReadMetadata(jpegData []byte) Metadata {
+	var meta Metadata
+	r, ok := newTIFFReader(jpegExif(jpegData))
+	if !ok {
+		return meta
+	}
+
+	for _, e := range r.ifd(r.firstIFD()) {
+		switch e.tag {
+		case tagOrientation:
+			if o, ok := r.uint(e); ok && o >= 1 && o <= 8 {
+				meta.Orientation = int(o)
+			}
+		case tagExifIFD:
+			if offset, ok := r.uint(e); ok {
+				meta.CapturedAt = r.captureTime(offset)
+			}
+		case tagGPSIFD:
+			if offset, ok := r.uint(e); ok {
+				meta.Location = r.gpsLocation(offset)
+			}
+		}
+	}
+	return meta
+}
+
+// captureTime reads when the photo was taken from the Exif directory. EXIF times
+// are local to the camera; without a recorded offset they are taken to be in the
+// server's time zone.
+func (r *tiffReader) captureTime(offset uint32) *time.Time {
+	var original, digitized, zone string
+	for _, e := range r.ifd(offset) {
+		switch e.tag {
+		case tagDateTimeOriginal:
+			original = r.ascii(e)
+		case tagDateTimeDigitized:
+			digitized = r.ascii(e)
+		case tagOffsetTimeOriginal:
+			zone = r.ascii(e)
+		}
+	}
+	if original == "" {
+		original = digitized
+	}
+
+	loc := time.Local
+	if zone != "" {
+		if t, err := time.Parse("-07:00", zone); err == nil {
+			_, seconds := t.Zone()
+			loc = time.FixedZone(zone, seconds)
+		}
+	}
+	// Cameras that don't know the time write blanks or zeros
+	t, err := time.ParseInLocation(exifTimeLayout, original, loc)
+	if err != nil || t.Year() < 1900 {
+		return nil
+	}
+	return &t
+}
+
+// gpsLocation reads the position from the GPS directory, or returns nil if it is
+// missing or out of range
+func (r *tiffReader) gpsLocation(offset uint32) *Location {
+	var latRef, lonRef string
+	var lat, lon []float64
+	for _, e := range r.ifd(offset) {
+		switch e.tag {
+		case tagGPSLatitudeRef:
+			latRef = r.ascii(e)
+		case tagGPSLatitude:
+			lat = r.rationals(e)
+		case tagGPSLongitudeRef:
+			lonRef = r.ascii(e)
+		case tagGPSLongitude:
+			lon = r.rationals(e)
+		}
+	}
+	if len(lat) != 3 || len(lon) != 3 {
+		return nil
+	}
+
+	loc := &Location{
+		Latitude:  lat[0] + lat[1]/60 + lat[2]/3600,
+		Longitude: lon[0] + lon[1]/60 + lon[2]/3600,
+	}
+	switch latRef {
+	case "N":
+	case "S":
+		loc.Latitude = -loc.Latitude
+	default:
+		return nil
+	}
+	switch lonRef {
+	case "E":
+	case "W":
+		loc.Longitude = -loc.Longitude
+	default:
+		return nil
+	}
+
+	// Some phones write zeros when they have no fix
+	if loc.Latitude == 0 && loc.Longitude == 0 {
+		return nil
+	}
+	if math.IsNaN(loc.Latitude) || math.IsNaN(loc.Longitude) ||
+		math.Abs(loc.Latitude) > 90 || math.Abs(loc.Longitude) > 180 {
+		return nil
+	}
+	return loc
+}
+
+// jpegExif returns the TIFF data of the JPEG's APP1 Exif segment, or nil
+func jpegExif(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		// Metadata segments come before the image data
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		i += 2 + length
+	}
+	return nil
+}
+
+func newTIFFReader(data []byte) (*tiffReader, bool) {
+	if len(data) < 8 {
+		return nil, false
+	}
+	r := &tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, false
+	}
+	if r.order.Uint16(data[2:]) != 42 {
+		return nil, false
+	}
+	return r, true
+}
+
+func (r *tiffReader) firstIFD() uint32 {
+	return r.order.Uint32(r.data[4:])
+}
+
+// ifd returns the entries of the directory at offset, or nil if it is out of bounds
+func (r *tiffReader) ifd(offset uint32) []ifdEntry {
+	if offset == 0 || int64(offset)+2 > int64(len(r.data)) {
+		return nil
+	}
+	n := int(r.order.Uint16(r.data[offset:]))
+	start := int(offset) + 2
+	if start+n*12 > len(r.data) {
+		return nil
+	}
+
+	entries := make([]ifdEntry, 0, n)
+	for i := 0; i < n; i++ {
+		b := r.data[start+i*12:]
+		entries = append(entries, ifdEntry{
+			tag:   r.order.Uint16(b),
+			typ:   r.order.Uint16(b[2:]),
+			count: r.order.Uint32(b[4:]),
+			value: b[8:12],
+		})
+	}
+	return entries
+}
+
+// typeSizes is the size in bytes of one value of each TIFF field type
+var typeSizes = map[uint16]int{
+	1:  1, // BYTE
+	2:  1, // ASCII
+	3:  2, // SHORT
+	4:  4, // LONG
+	5:  8, // RATIONAL
+	7:  1, // UNDEFINED
+	9:  4, // SLONG
+	10: 8, // SRATIONAL
+}
+
+// bytes returns the raw value of an entry, or nil if it is out of bounds
+func (r *tiffReader) bytes(e ifdEntry) []byte {
+	size, ok := typeSizes[e.typ]
+	if !ok {
+		return nil
+	}
+	n := int64(size) * int64(e.count)
+	if n <= 4 {
+		return e.value[:n]
+	}
+	offset := int64(r.order.Uint32(e.value))
+	if offset+n > int64(len(r.data)) {
+		return nil
+	}
+	return r.data[offset : offset+n]
+}
+
+// ascii reads an ASCII value without its terminating NUL and surrounding spaces
+func (r *tiffReader) ascii(e ifdEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	value, _, _ := strings.Cut(string(r.bytes(e)), "\x00")
+	return strings.TrimSpace(value)
+}
+
+// rationals reads a RATIONAL value, giving nil if any denominator is zero
+func (r *tiffReader) rationals(e ifdEntry) []float64 {
+	if e.typ != 5 {
+		return nil
+	}
+	b := r.bytes(e)
+	if b == nil {
+		return nil
+	}
+	values := make([]float64, e.count)
+	for i := range values {
+		num := r.order.Uint32(b[i*8:])
+		den := r.order.Uint32(b[i*8+4:])
+		if den == 0 {
+			return nil
+		}
+		values[i] = float64(num) / float64(den)
+	}
+	return values
+}
+
+// uint reads a SHORT or LONG value
+func (r *tiffReader) uint(e ifdEntry) (uint32, bool) {
+	switch e.typ {
+	case 3: // SHORT
+		return uint32(r.order.Uint16(e.value)), true
+	case 4: // LONG
+		return r.order.Uint32(e.value), true
+	}
+	return 0, false
+}
@@ -0,0 +1,183 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntry struct {
+	tag, typ uint16
+	count    uint32
+	data     []byte
+}
+
+func asciiEntry(tag uint16, value string) testEntry {
+	return testEntry{tag: tag, typ: 2, count: uint32(len(value) + 1), data: append([]byte(value), 0)}
+}
+
+func rationalEntry(order binary.ByteOrder, tag uint16, values ...[2]uint32) testEntry {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		order.PutUint32(data[i*8:], v[0])
+		order.PutUint32(data[i*8+4:], v[1])
+	}
+	return testEntry{tag: tag, typ: 5, count: uint32(len(values)), data: data}
+}
+
+func longEntry(order binary.ByteOrder, tag uint16, value uint32) testEntry {
+	data := make([]byte, 4)
+	order.PutUint32(data, value)
+	return testEntry{tag: tag, typ: 4, count: 1, data: data}
+}
+
+// layoutIFD encodes a directory placed at start, followed by the values that don't
+// fit in their entries
+func layoutIFD(order binary.ByteOrder, start uint32, entries []testEntry) []byte {
+	dir := make([]byte, 2+12*len(entries)+4)
+	order.PutUint16(dir, uint16(len(entries)))
+	var extra []byte
+	for i, e := range entries {
+		b := dir[2+i*12:]
+		order.PutUint16(b, e.tag)
+		order.PutUint16(b[2:], e.typ)
+		order.PutUint32(b[4:], e.count)
+		if len(e.data) <= 4 {
+			copy(b[8:12], e.data)
+			continue
+		}
+		order.PutUint32(b[8:], start+uint32(len(dir)+len(extra)))
+		extra = append(extra, e.data...)
+	}
+	return append(dir, extra...)
+}
+
+// photoExif builds an APP1 segment with Exif and GPS directories
+func photoExif(order binary.ByteOrder, exifEntries, gpsEntries []testEntry) []byte {
+	header := []byte("II*\x00\x00\x00\x00\x00")
+	if order == binary.BigEndian {
+		header = []byte("MM\x00*\x00\x00\x00\x00")
+	}
+	order.PutUint32(header[4:], 8)
+
+	ifd0Size := uint32(2 + 12*2 + 4)
+	exifStart := 8 + ifd0Size
+	exifIFD := layoutIFD(order, exifStart, exifEntries)
+	gpsStart := exifStart + uint32(len(exifIFD))
+	gpsIFD := layoutIFD(order, gpsStart, gpsEntries)
+	ifd0 := layoutIFD(order, 8, []testEntry{
+		longEntry(order, tagExifIFD, exifStart),
+		longEntry(order, tagGPSIFD, gpsStart),
+	})
+
+	tiff := append(header, ifd0...)
+	tiff = append(tiff, exifIFD...)
+	tiff = append(tiff, gpsIFD...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// exeterGPS is 50°43'6.24"N 3°32'2.04"W
+func exeterGPS(order binary.ByteOrder) []testEntry {
+	return []testEntry{
+		asciiEntry(tagGPSLatitudeRef, "N"),
+		rationalEntry(order, tagGPSLatitude, [2]uint32{50, 1}, [2]uint32{43, 1}, [2]uint32{624, 100}),
+		asciiEntry(tagGPSLongitudeRef, "W"),
+		rationalEntry(order, tagGPSLongitude, [2]uint32{3, 1}, [2]uint32{32, 1}, [2]uint32{204, 100}),
+	}
+}
+
+func TestReadMetadataLocationAndCaptureTime(t *testing.T) {
+	jpegData := encodeTestJPEG(t, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			segment := photoExif(order, []testEntry{
+				asciiEntry(tagDateTimeOriginal, "2024:06:01 14:30:05"),
+				asciiEntry(tagOffsetTimeOriginal, "+01:00"),
+			}, exeterGPS(order))
+
+			meta := ReadMetadata(withExif(jpegData, segment))
+
+			if assert.NotNil(t, meta.Location) {
+				assert.InDelta(t, 50.71840, meta.Location.Latitude, 1e-5)
+				assert.InDelta(t, -3.53390, meta.Location.Longitude, 1e-5)
+			}
+			if assert.NotNil(t, meta.CapturedAt) {
+				assert.True(t, time.Date(2024, 6, 1, 13, 30, 5, 0, time.UTC).Equal(*meta.CapturedAt))
+			}
+		})
+	}
+}
+
+func TestReadMetadataCaptureTimeWithoutOffset(t *testing.T) {
+	jpegData := encodeTestJPEG(t, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	segment := photoExif(binary.LittleEndian, []testEntry{
+		asciiEntry(tagDateTimeDigitized, "2024:06:01 14:30:05"),
+	}, nil)
+
+	meta := ReadMetadata(withExif(jpegData, segment))
+
+	if assert.NotNil(t, meta.CapturedAt) {
+		assert.True(t, time.Date(2024, 6, 1, 14, 30, 5, 0, time.Local).Equal(*meta.CapturedAt))
+	}
+	assert.Nil(t, meta.Location)
+}
+
+func TestReadMetadataIgnoresUnusableValues(t *testing.T) {
+	jpegData := encodeTestJPEG(t, image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	order := binary.LittleEndian
+	zero := [2]uint32{0, 1}
+
+	tests := map[string]struct {
+		exif []testEntry
+		gps  []testEntry
+	}{
+		"blank time": {
+			exif: []testEntry{asciiEntry(tagDateTimeOriginal, "    :  :     :  :  ")},
+		},
+		"zero time": {
+			exif: []testEntry{asciiEntry(tagDateTimeOriginal, "0000:00:00 00:00:00")},
+		},
+		"no fix": {
+			gps: []testEntry{
+				asciiEntry(tagGPSLatitudeRef, "N"),
+				rationalEntry(order, tagGPSLatitude, zero, zero, zero),
+				asciiEntry(tagGPSLongitudeRef, "E"),
+				rationalEntry(order, tagGPSLongitude, zero, zero, zero),
+			},
+		},
+		"missing reference": {
+			gps: exeterGPS(order)[1:],
+		},
+		"zero denominator": {
+			gps: []testEntry{
+				asciiEntry(tagGPSLatitudeRef, "N"),
+				rationalEntry(order, tagGPSLatitude, [2]uint32{50, 0}, zero, zero),
+				asciiEntry(tagGPSLongitudeRef, "W"),
+				rationalEntry(order, tagGPSLongitude, [2]uint32{3, 1}, zero, zero),
+			},
+		},
+		"out of range": {
+			gps: []testEntry{
+				asciiEntry(tagGPSLatitudeRef, "N"),
+				rationalEntry(order, tagGPSLatitude, [2]uint32{95, 1}, zero, zero),
+				asciiEntry(tagGPSLongitudeRef, "W"),
+				rationalEntry(order, tagGPSLongitude, [2]uint32{3, 1}, zero, zero),
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			meta := ReadMetadata(withExif(jpegData, photoExif(order, tt.exif, tt.gps)))
+			assert.Nil(t, meta.Location)
+			assert.Nil(t, meta.CapturedAt)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package imaging prepares uploaded photos for publishing: it drops their metadata,
+// turns them upright and produces smaller renditions.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+)
+
+// Longest edge of each rendition, in pixels
+const (
+	MaxDimension    = 2560
+	MediumDimension = 1280
+	ThumbDimension  = 320
+)
+
+const (
+	jpegQuality = 85
+
+	// maxPixels refuses images that would take too much memory to decode
+	maxPixels = 50_000_000
+)
+
+// ErrTooLarge is returned for images with more pixels than we decode
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Rendition is an encoded version of an image
+type Rendition struct {
+	Data        []byte
+	ContentType string
+	// Ext is the file extension matching the content type, including the dot
+	Ext           string
+	Width, Height int
+}
+
+// Result holds the renditions of an uploaded image. Medium and Thumb are nil if the
+// original is already small enough to serve in their place.
+type Result struct {
+	Original *Rendition
+	Medium   *Rendition
+	Thumb    *Rendition
+	Metadata Metadata
+}
+
+// Process re-encodes a JPEG, PNG, GIF or WEBP image without its metadata. JPEGs are
+// turned upright according to their EXIF orientation, images larger than MaxDimension
+// are scaled down, and medium and thumbnail renditions are made. GIFs keep only their
+// first frame and become PNGs. WEBP images can't be decoded with the standard library,
+// so their metadata chunks are removed and the original is served at every size.
+func Process(data []byte, contentType string) (*Result, error) {
+	var meta Metadata
+	switch contentType {
+	case "image/webp":
+		stripped, err := stripWebPMetadata(data)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Original: &Rendition{Data: stripped, ContentType: contentType, Ext: ".webp"}}, nil
+	case "image/jpeg":
+		meta = ReadMetadata(data)
+	case "image/png", "image/gif":
+	default:
+		return nil, fmt.Errorf("unsupported image type %s", contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("invalid image: no pixels")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	img := toNRGBA(decoded)
+
+	// Scaling first means turning the image only touches the pixels we keep; fitting
+	// in a square doesn't depend on which way up the image is
+	w, h := fitSize(img, MaxDimension)
+	img = orient(resize(img, w, h), meta.Orientation)
+
+	// Photos compress better as JPEG; PNG keeps transparency and sharp edges of the rest
+	encode := encodePNG
+	if contentType == "image/jpeg" {
+		encode = encodeJPEG
+	}
+
+	result := &Result{Metadata: meta}
+	if result.Original, err = encode(img); err != nil {
+		return nil, err
+	}
+	if result.Medium, err = rendition(img, MediumDimension, encode); err != nil {
+		return nil, err
+	}
+	if result.Thumb, err = rendition(img, ThumbDimension, encode); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func fitSize(img *image.NRGBA, max int) (int, int) {
+	return fit(img.Rect.Dx(), img.Rect.Dy(), max)
+}
+
+// rendition scales the image down to fit max, or returns nil if it already fits
+func rendition(img *image.NRGBA, max int, encode func(*image.NRGBA) (*Rendition, error)) (*Rendition, error) {
+	w, h := fitSize(img, max)
+	if w == img.Rect.Dx() && h == img.Rect.Dy() {
+		return nil, nil
+	}
+	return encode(resize(img, w, h))
+}
+
+func encodeJPEG(img *image.NRGBA) (*Rendition, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return &Rendition{Data: buf.Bytes(), ContentType: "image/jpeg", Ext: ".jpg",
+		Width: img.Rect.Dx(), Height: img.Rect.Dy()}, nil
+}
+
+func encodePNG(img *image.NRGBA) (*Rendition, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return &Rendition{Data: buf.Bytes(), ContentType: "image/png", Ext: ".png",
+		Width: img.Rect.Dx(), Height: img.Rect.Dy()}, nil
+}
+
+// stripWebPMetadata removes the EXIF and XMP chunks of a WEBP file and clears their
+// flags in the extended header
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errors.New("invalid WEBP image")
+	}
+
+	out := append([]byte{}, data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errors.New("invalid WEBP image")
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, errors.New("invalid WEBP image")
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
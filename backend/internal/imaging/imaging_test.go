@@ -0,0 +1,198 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exifSegment builds an APP1 segment holding a little-endian TIFF structure with the
+// given IFD0 entries, each a SHORT value
+func exifSegment(entries map[uint16]uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))
+	binary.Write(&tiff, binary.LittleEndian, uint16(len(entries)))
+	for tag, value := range entries {
+		binary.Write(&tiff, binary.LittleEndian, tag)
+		binary.Write(&tiff, binary.LittleEndian, uint16(3))
+		binary.Write(&tiff, binary.LittleEndian, uint32(1))
+		binary.Write(&tiff, binary.LittleEndian, value)
+		binary.Write(&tiff, binary.LittleEndian, uint16(0))
+	}
+	binary.Write(&tiff, binary.LittleEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withExif inserts an EXIF segment after the start of image marker
+func withExif(jpegData, segment []byte) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+// twoTone returns a w×h image, red on the left half and blue on the right
+func twoTone(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeTestJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && g < 0x4000 && r < 0x4000
+}
+
+func TestProcessJPEG(t *testing.T) {
+	// A phone photo taken in portrait: stored sideways with orientation 6
+	data := withExif(encodeTestJPEG(t, twoTone(3200, 800)), exifSegment(map[uint16]uint16{tagOrientation: 6}))
+	assert.Equal(t, 6, ReadMetadata(data).Orientation)
+
+	result, err := Process(data, "image/jpeg")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, result.Metadata.Orientation)
+
+	// Capped, turned upright and without the EXIF data
+	orig := result.Original
+	assert.Equal(t, "image/jpeg", orig.ContentType)
+	assert.Equal(t, ".jpg", orig.Ext)
+	assert.Equal(t, 640, orig.Width)
+	assert.Equal(t, 2560, orig.Height)
+	assert.NotContains(t, string(orig.Data), "Exif")
+	assert.Equal(t, 0, ReadMetadata(orig.Data).Orientation)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(orig.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 640, 2560), decoded.Bounds())
+	assert.True(t, isRed(decoded.At(320, 100)), "the left of the sensor is now at the top")
+	assert.True(t, isBlue(decoded.At(320, 2460)))
+
+	assert.Equal(t, 320, result.Medium.Width)
+	assert.Equal(t, 1280, result.Medium.Height)
+	assert.Equal(t, 80, result.Thumb.Width)
+	assert.Equal(t, 320, result.Thumb.Height)
+}
+
+func TestProcessSmallPNGKeepsTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	img.SetNRGBA(10, 10, color.NRGBA{G: 255, A: 255})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+
+	result, err := Process(buf.Bytes(), "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", result.Original.ContentType)
+	assert.Nil(t, result.Medium, "small images are served as they are")
+	assert.Nil(t, result.Thumb)
+
+	decoded, err := png.Decode(bytes.NewReader(result.Original.Data))
+	assert.NoError(t, err)
+	_, _, _, a := decoded.At(50, 50).RGBA()
+	assert.Zero(t, a)
+}
+
+func TestProcessRejectsHugeImages(t *testing.T) {
+	// A PNG header claiming 20000×20000 pixels, which would need gigabytes to decode
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 20000)
+	binary.BigEndian.PutUint32(ihdr[4:], 20000)
+	ihdr[8], ihdr[9] = 8, 6
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+
+	_, err := Process(buf.Bytes(), "image/png")
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Process([]byte("\xff\xd8\xffnot really a jpeg"), "image/jpeg")
+	assert.Error(t, err)
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	chunk := func(fourCC string, data []byte) []byte {
+		out := append([]byte(fourCC), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(data)))
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	var body []byte
+	body = append(body, chunk("VP8X", []byte{0x08 | 0x04, 0, 0, 0, 9, 0, 0, 9, 0, 0})...)
+	body = append(body, chunk("VP8 ", []byte("frame"))...)
+	body = append(body, chunk("EXIF", []byte("GPS 51.5N"))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"), body...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	result, err := Process(data, "image/webp")
+	assert.NoError(t, err)
+	out := result.Original.Data
+	assert.NotContains(t, string(out), "GPS")
+	assert.NotContains(t, string(out), "xmpmeta")
+	assert.Contains(t, string(out), "frame")
+	assert.Equal(t, byte(0), out[20]&0x0C, "VP8X no longer announces metadata")
+	assert.Equal(t, uint32(len(out)-8), binary.LittleEndian.Uint32(out[4:]))
+
+	_, err = Process([]byte("RIFF\x00\x00\x00\x00WEBPVP8 \xff\xff\xff\x00"), "image/webp")
+	assert.Error(t, err)
+}
+
+func TestResizeDoesNotBleedTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 0})
+
+	p := resize(img, 1, 1).NRGBAAt(0, 0)
+	assert.Equal(t, color.NRGBA{R: 255, A: 128}, p)
+}
+
+func TestOrient(t *testing.T) {
+	// 2×1: red, blue
+	img := twoTone(2, 1)
+	for orientation, want := range map[int][2]image.Point{
+		1: {{0, 0}, {1, 0}},
+		2: {{1, 0}, {0, 0}},
+		3: {{1, 0}, {0, 0}},
+		6: {{0, 0}, {0, 1}},
+		8: {{0, 1}, {0, 0}},
+	} {
+		out := orient(img, orientation)
+		assert.True(t, isRed(out.At(want[0].X, want[0].Y)), "orientation %d", orientation)
+		assert.True(t, isBlue(out.At(want[1].X, want[1].Y)), "orientation %d", orientation)
+	}
+}
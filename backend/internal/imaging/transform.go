@@ -0,0 +1,147 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// toNRGBA copies the image into an NRGBA image with its origin at 0,0
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// orient turns the image upright according to its EXIF orientation
+func orient(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // upside down and mirrored
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // needs a quarter turn clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // needs a quarter turn anticlockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// fit returns the size of a w×h image scaled down to fit within max×max, keeping
+// its aspect ratio. Images that already fit keep their size.
+func fit(w, h, max int) (int, int) {
+	if w <= max && h <= max {
+		return w, h
+	}
+	if w >= h {
+		return max, int(math.Max(1, math.Round(float64(h)*float64(max)/float64(w))))
+	}
+	return int(math.Max(1, math.Round(float64(w)*float64(max)/float64(h)))), max
+}
+
+// contribution is the weight of a source pixel in a destination pixel
+type contribution struct {
+	index  int
+	weight float64
+}
+
+// boxWeights returns, for each of the dst pixels along an axis, the source pixels it
+// covers when shrinking src pixels to dst, weighted by how much of each is covered
+func boxWeights(src, dst int) [][]contribution {
+	scale := float64(src) / float64(dst)
+	weights := make([][]contribution, dst)
+	for i := range weights {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		for j := int(start); j < src && float64(j) < end; j++ {
+			overlap := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if overlap > 0 {
+				weights[i] = append(weights[i], contribution{j, overlap / scale})
+			}
+		}
+	}
+	return weights
+}
+
+// resize shrinks the image to w×h by averaging the source pixels each destination
+// pixel covers. Colours are weighted by alpha so transparent pixels don't bleed.
+// Only one row of intermediate values is kept, so large photos don't need a second
+// full-size buffer.
+func resize(src *image.NRGBA, w, h int) *image.NRGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw == w && sh == h {
+		return src
+	}
+
+	columns := boxWeights(sw, w)
+	row := make([]float64, w*4)
+	acc := make([]float64, w*4)
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y, rows := range boxWeights(sh, h) {
+		clear(acc)
+		for _, rc := range rows {
+			// Shrink the source row horizontally, as alpha-premultiplied values
+			srcRow := src.Pix[rc.index*src.Stride:]
+			for x, contributions := range columns {
+				var r, g, b, a float64
+				for _, c := range contributions {
+					p := srcRow[c.index*4:]
+					alpha := float64(p[3]) * c.weight
+					r += float64(p[0]) * alpha
+					g += float64(p[1]) * alpha
+					b += float64(p[2]) * alpha
+					a += alpha
+				}
+				row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = r, g, b, a
+			}
+			for i, v := range row {
+				acc[i] += v * rc.weight
+			}
+		}
+
+		for x := 0; x < w; x++ {
+			r, g, b, a := acc[x*4], acc[x*4+1], acc[x*4+2], acc[x*4+3]
+			p := dst.Pix[dst.PixOffset(x, y):]
+			if a > 0 {
+				p[0], p[1], p[2] = clamp(r/a), clamp(g/a), clamp(b/a)
+			}
+			p[3] = clamp(a)
+		}
+	}
+	return dst
+}
+
+func clamp(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -2,8 +2,8 @@ package middleware
 
 import (
 	"errors"
+	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -16,7 +16,7 @@ import (
 // Authenticator defines authentication behavior for testing
 type Authenticator interface {
 	AuthMiddleware() gin.HandlerFunc
-	StaffOnly() gin.HandlerFunc
+	RequirePermission(permissions ...string) gin.HandlerFunc
 }
 
 // RealAuth implements Authenticator with real authentication logic
@@ -27,38 +27,82 @@ func (r *RealAuth) AuthMiddleware() gin.HandlerFunc {
 	return AuthMiddleware()
 }
 
-// StaffOnly ensures only staff users can access
-func (r *RealAuth) StaffOnly() gin.HandlerFunc {
-	return StaffOnly()
+// RequirePermission ensures the user's role grants one of the permissions
+func (r *RealAuth) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return RequirePermission(permissions...)
 }
 
-var secretKey = []byte(os.Getenv("JWT_SECRET"))
-
-// SetSecretKeyForTesting allows tests to set a consistent secret key
+// SetSecretKeyForTesting allows tests to set a consistent secret key, without the
+// strength checks of NewSecretKeySet.
 // This should only be used in test code
 func SetSecretKeyForTesting(secret string) {
-	secretKey = []byte(secret)
+	SetKeySet(secretKeySet(secret))
 }
 
 type UserClaims struct {
 	UserID   string `json:"user_id"`
-	UserType string `json:"user_type"` // "public" or "staff"
+	UserType string `json:"user_type"` // the user's role, see models.Roles
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT for the given userID and userType.
+// GenerateToken generates a short-lived access token for the given userID and userType.
 func GenerateToken(userID, userType string) (string, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+	return SignAccessToken(userID, userType, tokenID, time.Now().Add(AccessTokenTTL))
+}
+
+// SignAccessToken signs an access token with the given ID (jti) and expiry
+func SignAccessToken(userID, userType, tokenID string, expiresAt time.Time) (string, error) {
 	claims := UserClaims{
 		UserID:   userID,
 		UserType: userType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+	return signToken(claims)
+}
+
+// challengeAudience marks two-factor challenge tokens, so they can't be used as access tokens
+const challengeAudience = "two-factor"
+
+// ChallengeTokenTTL is how long a user has to enter their second factor after their
+// password was accepted
+const ChallengeTokenTTL = 5 * time.Minute
+
+// GenerateChallengeToken signs a token proving that the user entered their password,
+// to be exchanged for an access token together with their second factor
+func GenerateChallengeToken(userID string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ChallengeTokenTTL)
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{challengeAudience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	signed, err := signToken(claims)
+	return signed, expiresAt, err
+}
+
+// ParseChallengeToken validates a challenge token and returns the user it was issued to
+func ParseChallengeToken(tokenString string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, verificationKeyFunc,
+		jwt.WithAudience(challengeAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", errors.New("challenge token has no subject")
+	}
+	return claims.Subject, nil
 }
 
 // AuthMiddleware validates the JWT in the Authorization header.
@@ -78,12 +122,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		tokenString := strings.TrimPrefix(header, "Bearer ")
 		claims := &UserClaims{}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("invalid signing method")
-			}
-			return secretKey, nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, verificationKeyFunc)
 
 		if err != nil {
 			if errors.Is(err, jwt.ErrTokenExpired) {
@@ -99,25 +138,35 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if claims.UserID == "" || claims.UserType == "" {
+		// Access tokens have no audience; challenge tokens do
+		if claims.UserID == "" || claims.UserType == "" || len(claims.Audience) > 0 {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			return
 		}
 
+		if revocations != nil {
+			if claims.ID == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+				return
+			}
+			revoked, err := revocations.IsTokenRevoked(claims.ID)
+			if err != nil {
+				log.Printf("Failed to check token revocation: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate token"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token revoked"})
+				return
+			}
+		}
+
 		// Store claims in context
 		c.Set("userID", claims.UserID)
 		c.Set("userType", claims.UserType)
-		c.Next()
-	}
-}
-
-// StaffOnly ensures only staff users can access the endpoint.
-func StaffOnly() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userType, exists := c.Get("userType")
-		if !exists || userType != "staff" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Staff access required"})
-			return
+		c.Set("tokenID", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
 		}
 		c.Next()
 	}
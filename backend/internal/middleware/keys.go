@@ -0,0 +1,390 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing algorithms. HS256 is only used with a shared JWT_SECRET; keys from a rotation
+// schedule are asymmetric, so other services can verify tokens with the public key.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+)
+
+const (
+	minSecretBytes     = 32
+	minSecretDistinct  = 8
+	minRSAKeyBits      = 2048
+	keyScheduleTimeFmt = time.RFC3339
+)
+
+// SigningKey is a key that tokens are signed and verified with, identified by the kid
+// header of the tokens
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	// ActiveFrom is when the key starts signing new tokens. Keys are published in the
+	// JWKS before that, so other services already know them when they are first used.
+	ActiveFrom time.Time
+	// ExpiresAt is when tokens signed with the key stop being accepted; zero never
+	// expires. Keep a replaced key until every token it signed has expired.
+	ExpiresAt time.Time
+
+	private interface{} // *rsa.PrivateKey, ed25519.PrivateKey or []byte; nil for verify-only keys
+	public  interface{} // *rsa.PublicKey, ed25519.PublicKey or []byte
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func (k *SigningKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// KeySet holds the signing keys, ordered by when they become active
+type KeySet struct {
+	keys []*SigningKey
+}
+
+// NewSecretKeySet returns a key set with a single shared HS256 secret, after checking
+// that the secret is long and random enough
+func NewSecretKeySet(secret string) (*KeySet, error) {
+	if err := ValidateSecret(secret); err != nil {
+		return nil, err
+	}
+	return secretKeySet(secret), nil
+}
+
+func secretKeySet(secret string) *KeySet {
+	return &KeySet{keys: []*SigningKey{{
+		Algorithm: AlgHS256,
+		private:   []byte(secret),
+		public:    []byte(secret),
+	}}}
+}
+
+// ValidateSecret rejects empty, short and low-entropy shared secrets
+func ValidateSecret(secret string) error {
+	if secret == "" {
+		return errors.New("JWT_SECRET is not set")
+	}
+	if len(secret) < minSecretBytes {
+		return fmt.Errorf("JWT_SECRET must be at least %d bytes long", minSecretBytes)
+	}
+	distinct := map[byte]bool{}
+	for i := 0; i < len(secret); i++ {
+		distinct[secret[i]] = true
+	}
+	if len(distinct) < minSecretDistinct {
+		return errors.New("JWT_SECRET is too repetitive, generate a random one")
+	}
+	return nil
+}
+
+// keySchedule is the JSON file listing the keys and when each is used, e.g.
+//
+//	{"keys": [
+//	  {"kid": "2026-07", "algorithm": "EdDSA", "private_key_file": "2026-07.pem",
+//	   "active_from": "2026-07-01T00:00:00Z", "expires_at": "2026-10-02T00:00:00Z"},
+//	  {"kid": "2026-10", "algorithm": "EdDSA", "private_key_file": "2026-10.pem",
+//	   "active_from": "2026-10-01T00:00:00Z"}
+//	]}
+//
+// Key files are PEM encoded, PKCS#8 for private keys and PKIX for public keys, with
+// paths relative to the schedule. Keys with only a public key are never used to sign.
+type keySchedule struct {
+	Keys []struct {
+		ID             string `json:"kid"`
+		Algorithm      string `json:"algorithm"`
+		PrivateKeyFile string `json:"private_key_file"`
+		PublicKeyFile  string `json:"public_key_file"`
+		ActiveFrom     string `json:"active_from"`
+		ExpiresAt      string `json:"expires_at"`
+	} `json:"keys"`
+}
+
+// LoadKeySchedule reads a key rotation schedule and the keys it lists. It fails unless
+// every key is valid and one of them can sign tokens now.
+func LoadKeySchedule(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schedule keySchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("invalid key schedule %s: %w", path, err)
+	}
+	if len(schedule.Keys) == 0 {
+		return nil, fmt.Errorf("key schedule %s lists no keys", path)
+	}
+
+	dir := filepath.Dir(path)
+	set := &KeySet{}
+	seen := map[string]bool{}
+	for _, entry := range schedule.Keys {
+		if entry.ID == "" {
+			return nil, errors.New("every key in the schedule needs a kid")
+		}
+		if seen[entry.ID] {
+			return nil, fmt.Errorf("duplicate kid %q", entry.ID)
+		}
+		seen[entry.ID] = true
+
+		key := &SigningKey{ID: entry.ID, Algorithm: entry.Algorithm}
+		if key.ActiveFrom, err = parseScheduleTime(entry.ActiveFrom); err != nil {
+			return nil, fmt.Errorf("key %q: invalid active_from: %w", entry.ID, err)
+		}
+		if key.ExpiresAt, err = parseScheduleTime(entry.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("key %q: invalid expires_at: %w", entry.ID, err)
+		}
+
+		switch {
+		case entry.PrivateKeyFile != "":
+			err = key.loadPrivateKey(resolvePath(dir, entry.PrivateKeyFile))
+		case entry.PublicKeyFile != "":
+			err = key.loadPublicKey(resolvePath(dir, entry.PublicKeyFile))
+		default:
+			err = errors.New("needs a private_key_file or public_key_file")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		set.keys = append(set.keys, key)
+	}
+
+	sort.SliceStable(set.keys, func(i, j int) bool {
+		return set.keys[i].ActiveFrom.Before(set.keys[j].ActiveFrom)
+	})
+	if _, err := set.SigningKey(time.Now()); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// LoadKeySet loads the key rotation schedule if one is configured, and otherwise the
+// shared secret. It refuses an empty or weak secret.
+func LoadKeySet(schedulePath, secret string) (*KeySet, error) {
+	if schedulePath != "" {
+		return LoadKeySchedule(schedulePath)
+	}
+	return NewSecretKeySet(secret)
+}
+
+func parseScheduleTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(keyScheduleTimeFmt, value)
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func readPEM(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+	return block.Bytes, nil
+}
+
+func (k *SigningKey) loadPrivateKey(path string) error {
+	der, err := readPEM(path)
+	if err != nil {
+		return err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return err
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.private, k.public = private, &private.PublicKey
+	case ed25519.PrivateKey:
+		k.private, k.public = private, private.Public()
+	default:
+		return fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return k.checkAlgorithm()
+}
+
+func (k *SigningKey) loadPublicKey(path string) error {
+	der, err := readPEM(path)
+	if err != nil {
+		return err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return err
+	}
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		k.public = public
+	default:
+		return fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return k.checkAlgorithm()
+}
+
+// checkAlgorithm makes sure the key suits its algorithm
+func (k *SigningKey) checkAlgorithm() error {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		if k.Algorithm != AlgRS256 {
+			return fmt.Errorf("RSA key can't be used with algorithm %q", k.Algorithm)
+		}
+		if public.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+	case ed25519.PublicKey:
+		if k.Algorithm != AlgEdDSA {
+			return fmt.Errorf("Ed25519 key can't be used with algorithm %q", k.Algorithm)
+		}
+	}
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with: the most recently activated
+// key that has a private key and hasn't expired
+func (s *KeySet) SigningKey(now time.Time) (*SigningKey, error) {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		key := s.keys[i]
+		if key.private != nil && !key.ActiveFrom.After(now) && !key.expired(now) {
+			return key, nil
+		}
+	}
+	return nil, errors.New("no signing key is active")
+}
+
+// verificationKey returns the key a token with the given kid was signed with. Tokens
+// without a kid are only accepted with a shared secret, which never had one.
+func (s *KeySet) verificationKey(kid string, now time.Time) (*SigningKey, error) {
+	for _, key := range s.keys {
+		if key.ID == kid && !key.expired(now) {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// JWK is the public half of a signing key, as published in the JWKS
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// PublicKeys returns the public keys other services need to verify tokens: every key
+// that hasn't expired, including keys scheduled to become active later. Shared secrets
+// are never published.
+func (s *KeySet) PublicKeys(now time.Time) []JWK {
+	jwks := []JWK{}
+	for _, key := range s.keys {
+		if key.expired(now) {
+			continue
+		}
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+var signingKeys *KeySet
+
+// SetKeySet sets the keys tokens are signed and verified with. Until it is called no
+// tokens can be issued or accepted.
+func SetKeySet(keys *KeySet) {
+	signingKeys = keys
+}
+
+// PublicKeys returns the published keys of the configured key set
+func PublicKeys() []JWK {
+	if signingKeys == nil {
+		return []JWK{}
+	}
+	return signingKeys.PublicKeys(time.Now())
+}
+
+// signToken signs the claims with the active key, naming it in the kid header
+func signToken(claims jwt.Claims) (string, error) {
+	if signingKeys == nil {
+		return "", errors.New("signing keys are not configured")
+	}
+	key, err := signingKeys.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.private)
+}
+
+// verificationKeyFunc finds the key a token was signed with, making sure the token
+// uses that key's algorithm
+func verificationKeyFunc(t *jwt.Token) (interface{}, error) {
+	if signingKeys == nil {
+		return nil, errors.New("signing keys are not configured")
+	}
+	kid, _ := t.Header["kid"].(string)
+	key, err := signingKeys.verificationKey(kid, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.public, nil
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// writePrivateKey stores a private key as PKCS#8 PEM in dir
+func writePrivateKey(t *testing.T, dir, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+}
+
+func writeSchedule(t *testing.T, dir, schedule string) string {
+	path := filepath.Join(dir, "keys.json")
+	assert.NoError(t, os.WriteFile(path, []byte(schedule), 0600))
+	return path
+}
+
+// sendWithToken calls a route behind AuthMiddleware with the token
+func sendWithToken(token string) int {
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp.Code
+}
+
+func TestKeySchedule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	_, edOld, _ := ed25519.GenerateKey(rand.Reader)
+	_, edRetired, _ := ed25519.GenerateKey(rand.Reader)
+	rsaCurrent, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edNext, _ := ed25519.GenerateKey(rand.Reader)
+	writePrivateKey(t, dir, "old.pem", edOld)
+	writePrivateKey(t, dir, "retired.pem", edRetired)
+	writePrivateKey(t, dir, "current.pem", rsaCurrent)
+	writePrivateKey(t, dir, "next.pem", edNext)
+
+	now := time.Now().UTC()
+	ts := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+	path := writeSchedule(t, dir, `{"keys": [
+		{"kid": "old", "algorithm": "EdDSA", "private_key_file": "old.pem",
+		 "active_from": "`+ts(-60*24*time.Hour)+`", "expires_at": "`+ts(-time.Hour)+`"},
+		{"kid": "retired", "algorithm": "EdDSA", "private_key_file": "retired.pem",
+		 "active_from": "`+ts(-30*24*time.Hour)+`", "expires_at": "`+ts(time.Hour)+`"},
+		{"kid": "next", "algorithm": "EdDSA", "private_key_file": "next.pem",
+		 "active_from": "`+ts(24*time.Hour)+`"},
+		{"kid": "current", "algorithm": "RS256", "private_key_file": "`+filepath.Join(dir, "current.pem")+`",
+		 "active_from": "`+ts(-24*time.Hour)+`"}
+	]}`)
+
+	keys, err := LoadKeySchedule(path)
+	assert.NoError(t, err)
+	SetKeySet(keys)
+	defer SetSecretKeyForTesting("test-secret")
+
+	// The most recently activated key signs, naming itself in the kid header
+	token, err := GenerateToken("user123", "resident")
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &UserClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "current", parsed.Header["kid"])
+	assert.Equal(t, AlgRS256, parsed.Method.Alg())
+	assert.Equal(t, http.StatusOK, sendWithToken(token))
+
+	// Tokens of the replaced key are accepted until it expires, expired keys are not
+	sign := func(kid string, key interface{}) string {
+		claims := UserClaims{UserID: "user123", UserType: "resident", RegisteredClaims: jwt.RegisteredClaims{
+			ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}}
+		tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		tok.Header["kid"] = kid
+		signed, err := tok.SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+	assert.Equal(t, http.StatusOK, sendWithToken(sign("retired", edRetired)))
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken(sign("old", edOld)))
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken(sign("unknown", edNext)))
+
+	// A token can't switch to another algorithm, e.g. HMAC with the public key
+	claims := UserClaims{UserID: "user123", UserType: "admin", RegisteredClaims: jwt.RegisteredClaims{
+		ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "current"
+	publicDER, _ := x509.MarshalPKIXPublicKey(&rsaCurrent.PublicKey)
+	forgedToken, err := forged.SignedString(publicDER)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, sendWithToken(forgedToken))
+
+	// Upcoming keys are published ahead of time, expired ones are withdrawn
+	var kids []string
+	for _, jwk := range PublicKeys() {
+		kids = append(kids, jwk.KeyID)
+		switch jwk.KeyID {
+		case "current":
+			assert.Equal(t, "RSA", jwk.KeyType)
+			assert.Equal(t, "AQAB", jwk.E)
+			assert.NotEmpty(t, jwk.N)
+		case "next":
+			assert.Equal(t, "OKP", jwk.KeyType)
+			assert.Equal(t, "Ed25519", jwk.Curve)
+			assert.NotEmpty(t, jwk.X)
+		}
+	}
+	assert.ElementsMatch(t, []string{"retired", "current", "next"}, kids)
+}
+
+func TestKeyScheduleErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writePrivateKey(t, dir, "ed.pem", edKey)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	tests := map[string]string{
+		"no keys":            `{"keys": []}`,
+		"missing kid":        `{"keys": [{"algorithm": "EdDSA", "private_key_file": "ed.pem"}]}`,
+		"algorithm mismatch": `{"keys": [{"kid": "a", "algorithm": "RS256", "private_key_file": "ed.pem"}]}`,
+		"missing file":       `{"keys": [{"kid": "a", "algorithm": "EdDSA", "private_key_file": "missing.pem"}]}`,
+		"none active yet":    `{"keys": [{"kid": "a", "algorithm": "EdDSA", "private_key_file": "ed.pem", "active_from": "` + future + `"}]}`,
+		"duplicate kid": `{"keys": [{"kid": "a", "algorithm": "EdDSA", "private_key_file": "ed.pem"},
+			{"kid": "a", "algorithm": "EdDSA", "private_key_file": "ed.pem"}]}`,
+	}
+	for name, schedule := range tests {
+		_, err := LoadKeySchedule(writeSchedule(t, dir, schedule))
+		assert.Error(t, err, name)
+	}
+
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	writePrivateKey(t, dir, "weak.pem", weakRSA)
+	_, err = LoadKeySchedule(writeSchedule(t, dir, `{"keys": [{"kid": "a", "algorithm": "RS256", "private_key_file": "weak.pem"}]}`))
+	assert.Error(t, err)
+}
+
+func TestLoadKeySetRefusesWeakSecrets(t *testing.T) {
+	_, err := LoadKeySet("", "")
+	assert.Error(t, err)
+	_, err = LoadKeySet("", "your_jwt_secret")
+	assert.Error(t, err)
+	_, err = LoadKeySet("", strings.Repeat("ab", 32))
+	assert.Error(t, err)
+
+	keys, err := LoadKeySet("", "k7Qm2vX9pL4wZ8rT1nB6yH3sD5fG0jCe")
+	assert.NoError(t, err)
+	assert.Empty(t, keys.PublicKeys(time.Now()), "shared secrets are never published")
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: auth.go
+// Source: internal/middleware/auth.go
 //
 // Generated by this command:
 //
-//	mockgen -source=auth.go -destination=mocks/mock_auth.go -package=mocks
+//	mockgen -source=internal/middleware/auth.go -destination=/tmp/a.go -package=mocks
 //
 
 // Package mocks is a generated GoMock package.
@@ -54,16 +54,20 @@ func (mr *MockAuthenticatorMockRecorder) AuthMiddleware() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthMiddleware", reflect.TypeOf((*MockAuthenticator)(nil).AuthMiddleware))
 }
 
-// StaffOnly mocks base method.
-func (m *MockAuthenticator) StaffOnly() gin.HandlerFunc {
+// RequirePermission mocks base method.
+func (m *MockAuthenticator) RequirePermission(permissions ...string) gin.HandlerFunc {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StaffOnly")
+	varargs := []any{}
+	for _, a := range permissions {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RequirePermission", varargs...)
 	ret0, _ := ret[0].(gin.HandlerFunc)
 	return ret0
 }
 
-// StaffOnly indicates an expected call of StaffOnly.
-func (mr *MockAuthenticatorMockRecorder) StaffOnly() *gomock.Call {
+// RequirePermission indicates an expected call of RequirePermission.
+func (mr *MockAuthenticatorMockRecorder) RequirePermission(permissions ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StaffOnly", reflect.TypeOf((*MockAuthenticator)(nil).StaffOnly))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequirePermission", reflect.TypeOf((*MockAuthenticator)(nil).RequirePermission), permissions...)
 }
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"chalkstone.council/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HasPermission reports whether the authenticated user's role grants the permission
+func HasPermission(c *gin.Context, permission string) bool {
+	return models.HasPermission(c.GetString("userType"), permission)
+}
+
+// RequirePermission ensures the user's role grants at least one of the permissions.
+// It must run after AuthMiddleware.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, permission := range permissions {
+			if HasPermission(c, permission) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chalkstone.council/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	send := func(role string, permissions ...string) int {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if role != "" {
+				c.Set("userType", role)
+			}
+		}, RequirePermission(permissions...))
+		router.GET("/", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "Allowed"})
+		})
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(models.RoleDispatcher, models.PermIssuesAssign))
+	assert.Equal(t, http.StatusForbidden, send(models.RoleEngineer, models.PermIssuesAssign))
+	assert.Equal(t, http.StatusForbidden, send(models.RoleResident, models.PermIssuesRead))
+	assert.Equal(t, http.StatusForbidden, send("", models.PermIssuesRead))
+
+	// Any one of the permissions is enough
+	assert.Equal(t, http.StatusOK, send(models.RoleEngineer, models.PermIssuesUpdate, models.PermIssuesUpdateAssigned))
+
+	// Tokens signed before roles were introduced
+	assert.Equal(t, http.StatusOK, send("staff", models.PermIssuesAssign))
+	assert.Equal(t, http.StatusForbidden, send("staff", models.PermUsersManage))
+	assert.Equal(t, http.StatusForbidden, send("public", models.PermIssuesRead))
+}
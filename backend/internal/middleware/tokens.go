@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Access tokens are short-lived; clients renew them with a refresh token.
+// ACCESS_TOKEN_TTL_MINUTES and REFRESH_TOKEN_TTL_HOURS override the defaults.
+var (
+	AccessTokenTTL  = durationFromEnv("ACCESS_TOKEN_TTL_MINUTES", time.Minute, 15*time.Minute)
+	RefreshTokenTTL = durationFromEnv("REFRESH_TOKEN_TTL_HOURS", time.Hour, 30*24*time.Hour)
+)
+
+func durationFromEnv(key string, unit, fallback time.Duration) time.Duration {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return time.Duration(n) * unit
+	}
+	return fallback
+}
+
+// RevocationList reports whether an access token has been revoked before it expired
+type RevocationList interface {
+	IsTokenRevoked(tokenID string) (bool, error)
+}
+
+var revocations RevocationList
+
+// SetRevocationList makes AuthMiddleware reject tokens on the list. Once set, tokens
+// without an ID are rejected too, as they could never be revoked.
+func SetRevocationList(list RevocationList) {
+	revocations = list
+}
+
+// NewTokenID returns a random ID for the jti claim of an access token
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewRefreshToken returns a random refresh token and the hash to store in its place
+func NewRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hex digest stored in place of a refresh token or invite code
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRevocationList struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeRevocationList) IsTokenRevoked(tokenID string) (bool, error) {
+	return f.revoked[tokenID], f.err
+}
+
+func TestAuthMiddleware_RevocationList(t *testing.T) {
+	SetSecretKeyForTesting("test-secret")
+	list := &fakeRevocationList{revoked: map[string]bool{"revoked-jti": true}}
+	SetRevocationList(list)
+	defer SetRevocationList(nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"token_id": c.GetString("tokenID")})
+	})
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	valid, err := SignAccessToken("user123", "public", "valid-jti", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	resp := send(valid)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "valid-jti")
+
+	revoked, err := SignAccessToken("user123", "public", "revoked-jti", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	resp = send(revoked)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Token revoked")
+
+	// Tokens without an ID can't be revoked, so they are refused
+	noID, err := SignAccessToken("user123", "public", "", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, send(noID).Code)
+
+	list.err = errors.New("db error")
+	assert.Equal(t, http.StatusInternalServerError, send(valid).Code)
+}
+
+func TestGenerateTokenIsShortLived(t *testing.T) {
+	SetSecretKeyForTesting("test-secret")
+
+	token, err := GenerateToken("user123", "staff")
+	assert.NoError(t, err)
+
+	claims := &UserClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(AccessTokenTTL), claims.ExpiresAt.Time, time.Minute)
+}
+
+func TestRefreshTokenHashing(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashToken(token))
+
+	other, _, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestChallengeToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetSecretKeyForTesting("test-secret")
+
+	challenge, expiresAt, err := GenerateChallengeToken("user123")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(ChallengeTokenTTL), expiresAt, time.Minute)
+
+	userID, err := ParseChallengeToken(challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", userID)
+
+	// Access tokens and challenge tokens can't stand in for each other
+	access, err := GenerateToken("user123", "admin")
+	assert.NoError(t, err)
+	_, err = ParseChallengeToken(access)
+	assert.Error(t, err)
+
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeSpecializations maps each issue type to the engineer specializations that handle it
+var TypeSpecializations = map[IssueType][]string{
+	TypePothole:      {"Roads and Infrastructure"},
+	TypeStreetLight:  {"Roads and Infrastructure", "Public Safety"},
+	TypeGraffiti:     {"Environmental Services", "Urban Planning"},
+	TypeAntiSocial:   {"Public Safety"},
+	TypeFlyTipping:   {"Environmental Services"},
+	TypeBlockedDrain: {"Drainage Systems"},
+}
+
+// AssignmentDecision records which engineer the assignment engine picked and why
+type AssignmentDecision struct {
+	EngineerID   int64  `json:"engineer_id"`
+	EngineerName string `json:"engineer_name"`
+	OpenIssues   int    `json:"open_issues"`
+	Reason       string `json:"reason"`
+}
+
+// HandlesIssueType reports whether an engineer's specialization covers the issue type
+func HandlesIssueType(specialization string, issueType IssueType) bool {
+	for _, s := range TypeSpecializations[issueType] {
+		if strings.EqualFold(strings.TrimSpace(specialization), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChooseEngineer picks the active engineer with the fewest open issues, preferring
+// specialists in the issue type and falling back to any active engineer when there
+// are none. Ties go to the lowest engineer ID. Returns nil if nobody is available.
+func ChooseEngineer(issueType IssueType, workloads []*EngineerPerformance) *AssignmentDecision {
+	var specialist, anyone *EngineerPerformance
+	for _, w := range workloads {
+		if w.Engineer == nil || !w.Engineer.Active {
+			continue
+		}
+		if lighterLoad(w, anyone) {
+			anyone = w
+		}
+		if HandlesIssueType(w.Engineer.Specialization, issueType) && lighterLoad(w, specialist) {
+			specialist = w
+		}
+	}
+
+	switch {
+	case specialist != nil:
+		return &AssignmentDecision{
+			EngineerID:   specialist.Engineer.ID,
+			EngineerName: specialist.Engineer.Name,
+			OpenIssues:   specialist.IssuesAssigned,
+			Reason: fmt.Sprintf("%s specialist with the fewest open issues (%d) for %s",
+				specialist.Engineer.Specialization, specialist.IssuesAssigned, issueType),
+		}
+	case anyone != nil:
+		return &AssignmentDecision{
+			EngineerID:   anyone.Engineer.ID,
+			EngineerName: anyone.Engineer.Name,
+			OpenIssues:   anyone.IssuesAssigned,
+			Reason: fmt.Sprintf("No active specialist for %s; engineer with the fewest open issues (%d)",
+				issueType, anyone.IssuesAssigned),
+		}
+	}
+	return nil
+}
+
+// lighterLoad reports whether candidate should be preferred over the current choice
+func lighterLoad(candidate, current *EngineerPerformance) bool {
+	if current == nil {
+		return true
+	}
+	if candidate.IssuesAssigned != current.IssuesAssigned {
+		return candidate.IssuesAssigned < current.IssuesAssigned
+	}
+	return candidate.Engineer.ID < current.Engineer.ID
+}
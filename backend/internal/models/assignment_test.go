@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workload(id int64, specialization string, open int, active bool) *EngineerPerformance {
+	return &EngineerPerformance{
+		Engineer:       &Engineer{ID: id, Name: "Engineer", Specialization: specialization, Active: active},
+		IssuesAssigned: open,
+	}
+}
+
+func TestHandlesIssueType(t *testing.T) {
+	assert.True(t, HandlesIssueType("Drainage Systems", TypeBlockedDrain))
+	assert.True(t, HandlesIssueType(" drainage systems ", TypeBlockedDrain))
+	assert.False(t, HandlesIssueType("Drainage Systems", TypePothole))
+	assert.False(t, HandlesIssueType("", TypePothole))
+}
+
+func TestChooseEngineer(t *testing.T) {
+	workloads := []*EngineerPerformance{
+		workload(1, "Roads and Infrastructure", 4, true),
+		workload(2, "Roads and Infrastructure", 2, true),
+		workload(3, "Roads and Infrastructure", 0, false),
+		workload(4, "Environmental Services", 0, true),
+	}
+
+	// The least loaded active specialist wins, even if others are less busy
+	decision := ChooseEngineer(TypePothole, workloads)
+	assert.Equal(t, int64(2), decision.EngineerID)
+	assert.Equal(t, 2, decision.OpenIssues)
+	assert.Contains(t, decision.Reason, "Roads and Infrastructure specialist")
+
+	// Ties go to the lowest engineer ID
+	workloads[0].IssuesAssigned = 2
+	assert.Equal(t, int64(1), ChooseEngineer(TypePothole, workloads).EngineerID)
+
+	// Without a specialist, any active engineer is used and the reason says so
+	decision = ChooseEngineer(TypeBlockedDrain, workloads)
+	assert.Equal(t, int64(4), decision.EngineerID)
+	assert.Contains(t, decision.Reason, "No active specialist for BLOCKED_DRAIN")
+
+	assert.Nil(t, ChooseEngineer(TypePothole, []*EngineerPerformance{workload(3, "Roads and Infrastructure", 0, false)}))
+	assert.Nil(t, ChooseEngineer(TypePothole, nil))
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment is a note attached to an issue. Internal comments are only visible to staff.
+type Comment struct {
+	ID        int64     `json:"id" db:"id"`
+	IssueID   int64     `json:"issue_id" db:"issue_id"`
+	Author    string    `json:"author" db:"author"`
+	Body      string    `json:"body" db:"body"`
+	Internal  bool      `json:"internal" db:"internal"`
+	Images    []string  `json:"images" db:"images"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CommentUpdate struct {
+	Body string `json:"body" binding:"required"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// DuplicateCandidate is an open issue that may describe the same problem as a new report
+type DuplicateCandidate struct {
+	Issue
+	DistanceMeters float64 `json:"distance_meters" db:"distance_meters"`
+}
+
+// IssueMerge lists the issues to fold into a primary issue
+type IssueMerge struct {
+	IssueIDs []int64 `json:"issue_ids" binding:"required,min=1"`
+}
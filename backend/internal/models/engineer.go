@@ -12,10 +12,28 @@ type Engineer struct {
 	Phone          string    `json:"phone" db:"phone"`
 	Specialization string    `json:"specialization" db:"specialization"`
 	JoinDate       time.Time `json:"join_date" db:"join_date"`
+	Active         bool      `json:"active" db:"active"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// EngineerCreate holds the details needed to onboard an engineer
+type EngineerCreate struct {
+	Name           string `json:"name" binding:"required,max=100"`
+	Email          string `json:"email" binding:"required,email,max=100"`
+	Phone          string `json:"phone" binding:"max=20"`
+	Specialization string `json:"specialization" binding:"max=50"`
+}
+
+// EngineerUpdate holds the engineer fields to change; nil fields are left as they are
+type EngineerUpdate struct {
+	Name           *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Email          *string `json:"email,omitempty" binding:"omitempty,email,max=100"`
+	Phone          *string `json:"phone,omitempty" binding:"omitempty,max=20"`
+	Specialization *string `json:"specialization,omitempty" binding:"omitempty,max=50"`
+	Active         *bool   `json:"active,omitempty"`
+}
+
 // EngineerPerformance tracks the performance metrics for an engineer
 type EngineerPerformance struct {
 	Engineer             *Engineer       `json:"engineer"`
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+type IssueEventType string
+
+const (
+	EventCreated         IssueEventType = "CREATED"
+	EventStatusChanged   IssueEventType = "STATUS_CHANGED"
+	EventAssigned        IssueEventType = "ASSIGNED"
+	EventCommented       IssueEventType = "COMMENTED"
+	EventMerged          IssueEventType = "MERGED"
+	EventPriorityChanged IssueEventType = "PRIORITY_CHANGED"
+	EventImageAdded      IssueEventType = "IMAGE_ADDED"
+	EventImageRemoved    IssueEventType = "IMAGE_REMOVED"
+	// EventLocationReviewed records staff clearing a location review; the old value is its reason
+	EventLocationReviewed IssueEventType = "LOCATION_REVIEWED"
+)
+
+// IssueEvent is a single entry in an issue's audit trail
+type IssueEvent struct {
+	ID        int64          `json:"id" db:"id"`
+	IssueID   int64          `json:"issue_id" db:"issue_id"`
+	EventType IssueEventType `json:"event_type" db:"event_type"`
+	OldValue  *string        `json:"old_value,omitempty" db:"old_value"`
+	NewValue  *string        `json:"new_value,omitempty" db:"new_value"`
+	Actor     string         `json:"actor" db:"actor"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
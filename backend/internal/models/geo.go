@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math"
+)
+
+const (
+	earthRadiusMeters = 6371000.0
+	metersPerDegree   = 111320.0
+)
+
+// BoundingBox is a rectangular area in WGS84 degrees
+type BoundingBox struct {
+	MinLon float64 `json:"min_lon"`
+	MinLat float64 `json:"min_lat"`
+	MaxLon float64 `json:"max_lon"`
+	MaxLat float64 `json:"max_lat"`
+}
+
+// Valid reports whether the box has sensible coordinates and a non-negative area
+func (b BoundingBox) Valid() bool {
+	return ValidLatitude(b.MinLat) && ValidLatitude(b.MaxLat) &&
+		ValidLongitude(b.MinLon) && ValidLongitude(b.MaxLon) &&
+		b.MinLat <= b.MaxLat && b.MinLon <= b.MaxLon
+}
+
+// MapFilter restricts the issues returned for the map view. Zero values mean no filter.
+type MapFilter struct {
+	BBox         *BoundingBox
+	NearLat      *float64
+	NearLon      *float64
+	RadiusMeters float64
+	Status       IssueStatus
+	Type         IssueType
+}
+
+func ValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func ValidLongitude(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
+
+// HaversineDistance returns the great-circle distance in meters between two points
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// BoundingBoxAround returns a box that contains every point within radius meters of
+// the given point. It is used to narrow a search with the spatial index before the
+// exact distance check.
+func BoundingBoxAround(lat, lon, radiusMeters float64) BoundingBox {
+	latDelta := radiusMeters / metersPerDegree
+	lonDelta := 180.0
+	if cos := math.Cos(lat * math.Pi / 180); cos > 1e-6 {
+		lonDelta = math.Min(radiusMeters/(metersPerDegree*cos), 180)
+	}
+	return BoundingBox{
+		MinLon: math.Max(lon-lonDelta, -180),
+		MinLat: math.Max(lat-latDelta, -90),
+		MaxLon: math.Min(lon+lonDelta, 180),
+		MaxLat: math.Min(lat+latDelta, 90),
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	// Exeter Cathedral to Exeter St Davids station is roughly 1.2km
+	d := HaversineDistance(50.7226, -3.5300, 50.7293, -3.5435)
+	assert.InDelta(t, 1200, d, 100)
+
+	assert.Equal(t, 0.0, HaversineDistance(50.7, -3.5, 50.7, -3.5))
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	box := BoundingBoxAround(50.7184, -3.5339, 500)
+	assert.True(t, box.Valid())
+
+	// Every corner of the box is at least the radius away from the centre along each axis
+	assert.GreaterOrEqual(t, HaversineDistance(50.7184, -3.5339, box.MaxLat, -3.5339), 499.0)
+	assert.GreaterOrEqual(t, HaversineDistance(50.7184, -3.5339, 50.7184, box.MaxLon), 499.0)
+
+	// Boxes are clamped to valid coordinates
+	polar := BoundingBoxAround(89.9999, 0, 50000)
+	assert.Equal(t, 90.0, polar.MaxLat)
+	assert.Equal(t, -180.0, polar.MinLon)
+	assert.Equal(t, 180.0, polar.MaxLon)
+}
+
+func TestBoundingBoxValid(t *testing.T) {
+	assert.True(t, BoundingBox{MinLon: -3.6, MinLat: 50.7, MaxLon: -3.5, MaxLat: 50.8}.Valid())
+	assert.False(t, BoundingBox{MinLon: -3.5, MinLat: 50.7, MaxLon: -3.6, MaxLat: 50.8}.Valid())
+	assert.False(t, BoundingBox{MinLon: -3.6, MinLat: 95, MaxLon: -3.5, MaxLat: 96}.Valid())
+}
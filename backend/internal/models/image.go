@@ -0,0 +1,68 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// IssueImage is a photo of an issue in the sizes it is served at. Medium and Thumb
+// are the same as Original when the photo is already that small. CapturedAt is when
+// the photo was taken, read from its EXIF data before that was stripped. ContentType,
+// Size and Checksum (SHA-256, hex) describe the stored original; photos uploaded
+// before they were recorded have no size or checksum.
+type IssueImage struct {
+	ID          int64      `json:"id,omitempty"`
+	Original    string     `json:"original"`
+	Medium      string     `json:"medium"`
+	Thumb       string     `json:"thumb"`
+	CapturedAt  *time.Time `json:"captured_at,omitempty"`
+	ContentType string     `json:"content_type,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	Checksum    string     `json:"checksum,omitempty"`
+	UploadedBy  string     `json:"uploaded_by,omitempty"`
+}
+
+// Keys returns the distinct object keys of the photo's renditions
+func (image IssueImage) Keys() []string {
+	keys := []string{image.Original}
+	for _, key := range []string{image.Medium, image.Thumb} {
+		if key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// IssueImages is read from the issue_images table as a JSON array, in display order
+type IssueImages []IssueImage
+
+// ImageOrder lists every photo of an issue by ID in the order they should be shown
+type ImageOrder struct {
+	Order []int64 `json:"order" binding:"required"`
+}
+
+func (images IssueImages) Value() (driver.Value, error) {
+	if images == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(images)
+}
+
+func (images *IssueImages) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*images = IssueImages{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into IssueImages", src)
+	}
+	return json.Unmarshal(data, images)
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// InviteRoles are the roles a staff invite can grant
+var InviteRoles = []string{RoleEngineer, RoleDispatcher, RoleSupervisor, RoleAdmin}
+
+// ValidateInviteRole reports whether an invite may grant the given role
+func ValidateInviteRole(role string) bool {
+	for _, r := range InviteRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// StaffInvite is a single-use invitation to register with a staff role.
+// Only the hash of the invite code is stored.
+type StaffInvite struct {
+	ID         int64      `json:"id" db:"id"`
+	CodeHash   string     `json:"-" db:"code_hash"`
+	Email      string     `json:"email" db:"email"`
+	Role       string     `json:"role" db:"role"`
+	EngineerID *int64     `json:"engineer_id,omitempty" db:"engineer_id"`
+	CreatedBy  string     `json:"created_by" db:"created_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty" db:"redeemed_at"`
+	RedeemedBy *string    `json:"redeemed_by,omitempty" db:"redeemed_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StaffInviteCreate holds the details of a new invite. Engineer invites must name
+// the engineer the account belongs to.
+type StaffInviteCreate struct {
+	Email          string `json:"email" binding:"required,email,max=255"`
+	Role           string `json:"role" binding:"required"`
+	EngineerID     *int64 `json:"engineer_id"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
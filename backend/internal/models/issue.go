@@ -8,8 +8,23 @@ type IssueStatus string
 
 const (
 	StatusNew        IssueStatus = "NEW"
+	StatusTriaged    IssueStatus = "TRIAGED"
+	StatusAssigned   IssueStatus = "ASSIGNED"
 	StatusInProgress IssueStatus = "IN_PROGRESS"
 	StatusResolved   IssueStatus = "RESOLVED"
+	StatusClosed     IssueStatus = "CLOSED"
+	StatusRejected   IssueStatus = "REJECTED"
+	StatusDuplicate  IssueStatus = "DUPLICATE"
+	StatusReopened   IssueStatus = "REOPENED"
+)
+
+type IssuePriority string
+
+const (
+	PriorityLow    IssuePriority = "LOW"
+	PriorityMedium IssuePriority = "MEDIUM"
+	PriorityHigh   IssuePriority = "HIGH"
+	PriorityUrgent IssuePriority = "URGENT"
 )
 
 type IssueType string
@@ -23,6 +38,30 @@ const (
 	TypeBlockedDrain IssueType = "BLOCKED_DRAIN"
 )
 
+func ValidateIssuePriority(p IssuePriority) bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// IssueSort is the order issues are listed in
+type IssueSort string
+
+const (
+	SortNewest     IssueSort = "newest"
+	SortSupporters IssueSort = "supporters"
+)
+
+func ValidateIssueSort(s IssueSort) bool {
+	switch s {
+	case SortNewest, SortSupporters:
+		return true
+	}
+	return false
+}
+
 func ValidateIssueType(t IssueType) bool {
 	switch t {
 	case TypePothole, TypeStreetLight, TypeGraffiti, TypeAntiSocial, TypeFlyTipping, TypeBlockedDrain:
@@ -33,7 +72,8 @@ func ValidateIssueType(t IssueType) bool {
 
 func ValidateIssueStatus(s IssueStatus) bool {
 	switch s {
-	case StatusNew, StatusInProgress, StatusResolved:
+	case StatusNew, StatusTriaged, StatusAssigned, StatusInProgress, StatusResolved,
+		StatusClosed, StatusRejected, StatusDuplicate, StatusReopened:
 		return true
 	}
 	return false
@@ -48,11 +88,28 @@ type Issue struct {
 		Latitude  float64 `json:"latitude" db:"latitude"`
 		Longitude float64 `json:"longitude" db:"longitude"`
 	} `json:"location"`
-	Images     []string  `json:"images" db:"images"`
-	ReportedBy string    `json:"reported_by" db:"reported_by"`
-	AssignedTo *int64    `json:"assigned_to,omitempty" db:"assigned_to"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	Images           IssueImages   `json:"images" db:"images"`
+	ReportedBy       string        `json:"reported_by" db:"reported_by"`
+	AssignedTo       *int64        `json:"assigned_to,omitempty" db:"assigned_to"`
+	Priority         IssuePriority `json:"priority" db:"priority"`
+	DueAt            *time.Time    `json:"due_at,omitempty" db:"due_at"`
+	DuplicateOf      *int64        `json:"duplicate_of,omitempty" db:"duplicate_of"`
+	AssignmentReason *string       `json:"assignment_reason,omitempty" db:"assignment_reason"`
+	ResolutionNote   *string       `json:"resolution_note,omitempty" db:"resolution_note"`
+	AfterImages      []string      `json:"after_images,omitempty" db:"after_images"`
+	LocationReview   *string       `json:"location_review,omitempty" db:"location_review"`
+	SupporterCount   int           `json:"supporter_count" db:"supporter_count"`
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
+	// AllowedTransitions lists the statuses the issue may move to next, returned with
+	// the details of a single issue
+	AllowedTransitions []IssueStatus `json:"allowed_transitions,omitempty" db:"-"`
+}
+
+// ReportedIssue is the view of an issue shown to the resident who reported it
+type ReportedIssue struct {
+	Issue
+	AssignedEngineerName *string `json:"assigned_engineer_name,omitempty" db:"assigned_engineer_name"`
 }
 
 type IssueCreate struct {
@@ -62,13 +119,20 @@ type IssueCreate struct {
 		Latitude  float64 `json:"latitude" binding:"required"`
 		Longitude float64 `json:"longitude" binding:"required"`
 	} `json:"location" binding:"required"`
-	Images     []string `json:"images"`
-	ReportedBy string   `json:"reported_by" binding:"required"`
+	Images     IssueImages `json:"images"`
+	ReportedBy string      `json:"reported_by" binding:"required"`
+	// LocationReview explains why the reported location needs checking by staff,
+	// e.g. because the photos were taken elsewhere
+	LocationReview *string `json:"location_review,omitempty"`
 }
 
 // Engineer functions moved to models/engineer.go
 
 type IssueUpdate struct {
-	Status     *IssueStatus `json:"status,omitempty"`
-	AssignedTo *int64       `json:"assigned_to,omitempty"`
+	Status     *IssueStatus   `json:"status,omitempty"`
+	AssignedTo *int64         `json:"assigned_to,omitempty"`
+	Priority   *IssuePriority `json:"priority,omitempty"`
+	UpdatedBy  string         `json:"-"` // Set from the authenticated user, recorded in the issue history
+	// EngineerID is set when an engineer makes the update, who may only update their own issues
+	EngineerID *int64 `json:"-"`
 }
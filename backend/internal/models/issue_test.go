@@ -18,6 +18,9 @@ func TestValidateIssueStatus(t *testing.T) {
 	assert.True(t, ValidateIssueStatus(StatusNew))
 	assert.True(t, ValidateIssueStatus(StatusResolved))
 	assert.True(t, ValidateIssueStatus(StatusInProgress))
+	assert.True(t, ValidateIssueStatus(StatusTriaged))
+	assert.True(t, ValidateIssueStatus(StatusClosed))
+	assert.True(t, ValidateIssueStatus(StatusReopened))
 	assert.False(t, ValidateIssueStatus("UNKNOWN"))
 }
 
@@ -32,14 +35,14 @@ func TestIssueCreateJSON(t *testing.T) {
 			Latitude  float64 `json:"latitude"`
 			Longitude float64 `json:"longitude"`
 		}{Latitude: 51.5074, Longitude: -0.1278}),
-		Images:     []string{"img1.jpg", "img2.jpg"},
+		Images:     IssueImages{{Original: "img1.jpg", Medium: "img1-medium.jpg", Thumb: "img1-thumb.jpg"}},
 		ReportedBy: "user123",
 	}
 
 	data, err := json.Marshal(issue)
 	assert.NoError(t, err, "JSON marshalling failed")
 
-	expectedJSON := `{"type":"POTHOLE","description":"Pothole on main road","location":{"latitude":51.5074,"longitude":-0.1278},"images":["img1.jpg","img2.jpg"],"reported_by":"user123"}`
+	expectedJSON := `{"type":"POTHOLE","description":"Pothole on main road","location":{"latitude":51.5074,"longitude":-0.1278},"images":[{"original":"img1.jpg","medium":"img1-medium.jpg","thumb":"img1-thumb.jpg"}],"reported_by":"user123"}`
 	assert.JSONEq(t, expectedJSON, string(data))
 }
 
@@ -56,3 +59,29 @@ func TestIssueUpdateJSON(t *testing.T) {
 	expectedJSON := `{"status":"RESOLVED"}`
 	assert.JSONEq(t, expectedJSON, string(data))
 }
+
+func TestValidateIssuePriority(t *testing.T) {
+	assert.True(t, ValidateIssuePriority(PriorityLow))
+	assert.True(t, ValidateIssuePriority(PriorityUrgent))
+	assert.False(t, ValidateIssuePriority("CRITICAL"))
+	assert.False(t, ValidateIssuePriority(""))
+}
+
+func TestIssueImagesScanAndValue(t *testing.T) {
+	images := IssueImages{{Original: "a.jpg", Medium: "a-medium.jpg", Thumb: "a-thumb.jpg"}}
+	value, err := images.Value()
+	assert.NoError(t, err)
+
+	var scanned IssueImages
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, images, scanned)
+
+	// NULL and missing images are an empty list, not null in JSON
+	value, err = IssueImages(nil).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("[]"), value)
+	assert.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, IssueImages{}, scanned)
+
+	assert.Error(t, scanned.Scan(42))
+}
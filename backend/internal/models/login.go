@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// LoginAttempts tracks the consecutive failed logins for a username. Unknown usernames
+// are tracked too, so the response doesn't reveal whether an account exists.
+type LoginAttempts struct {
+	Username     string     `json:"username" db:"username"`
+	FailedCount  int        `json:"failed_count" db:"failed_count"`
+	LastFailedAt time.Time  `json:"last_failed_at" db:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+// AccountLockout is the audit record of a username being locked out
+type AccountLockout struct {
+	ID          int64      `json:"id" db:"id"`
+	Username    string     `json:"username" db:"username"`
+	FailedCount int        `json:"failed_count" db:"failed_count"`
+	LockedUntil time.Time  `json:"locked_until" db:"locked_until"`
+	UnlockedBy  *string    `json:"unlocked_by,omitempty" db:"unlocked_by"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty" db:"unlocked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LoginPolicy throttles failed logins per username. After FreeAttempts failures each
+// further attempt has to wait twice as long as the one before, starting at BaseDelay.
+// MaxAttempts failures lock the username for LockoutDuration. A zero MaxAttempts
+// disables lockout.
+type LoginPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxAttempts     int
+	LockoutDuration time.Duration
+}
+
+// RetryAt returns when the next login attempt is allowed, or the zero time if it is
+// allowed straight away
+func (p LoginPolicy) RetryAt(a *LoginAttempts) time.Time {
+	if a == nil {
+		return time.Time{}
+	}
+	if a.LockedUntil != nil {
+		return *a.LockedUntil
+	}
+	if a.FailedCount <= p.FreeAttempts || p.BaseDelay <= 0 {
+		return time.Time{}
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < a.FailedCount && delay < p.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if p.LockoutDuration > 0 && delay > p.LockoutDuration {
+		delay = p.LockoutDuration
+	}
+	return a.LastFailedAt.Add(delay)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginPolicyRetryAt(t *testing.T) {
+	policy := LoginPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxAttempts:     10,
+		LockoutDuration: 15 * time.Minute,
+	}
+	last := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, policy.RetryAt(nil).IsZero())
+	assert.True(t, policy.RetryAt(&LoginAttempts{FailedCount: 3, LastFailedAt: last}).IsZero())
+
+	// The delay doubles with every failure after the free attempts
+	assert.Equal(t, last.Add(time.Second), policy.RetryAt(&LoginAttempts{FailedCount: 4, LastFailedAt: last}))
+	assert.Equal(t, last.Add(2*time.Second), policy.RetryAt(&LoginAttempts{FailedCount: 5, LastFailedAt: last}))
+	assert.Equal(t, last.Add(32*time.Second), policy.RetryAt(&LoginAttempts{FailedCount: 9, LastFailedAt: last}))
+
+	// and never exceeds the lockout duration
+	assert.Equal(t, last.Add(15*time.Minute), policy.RetryAt(&LoginAttempts{FailedCount: 40, LastFailedAt: last}))
+
+	lockedUntil := last.Add(15 * time.Minute)
+	assert.Equal(t, lockedUntil, policy.RetryAt(&LoginAttempts{FailedCount: 10, LastFailedAt: last, LockedUntil: &lockedUntil}))
+}
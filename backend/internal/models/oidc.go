@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OIDCLogin is a login started with the identity provider that hasn't come back yet.
+// The state is stored hashed; the nonce and PKCE verifier are needed to finish it.
+type OIDCLogin struct {
+	StateHash    string    `json:"-" db:"state_hash"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// OIDCIdentity is a staff member as asserted by the identity provider, with the role
+// their groups map to
+type OIDCIdentity struct {
+	Issuer   string
+	Subject  string
+	Username string
+	// Email links engineer accounts to their engineer record; it isn't stored on the
+	// user, as provisioned users can't reset a password by email
+	Email string
+	// EmailVerified is set when the provider vouches that the user owns the email;
+	// anyone could otherwise claim an engineer's address
+	EmailVerified bool
+	Role          string
+}
@@ -1,8 +1,17 @@
 package models
 
 type User struct {
-	ID           int64  `json:"id" db:"id"`
-	Username     string `json:"username" db:"username"`
-	PasswordHash string `json:"-" db:"password_hash"`
-	UserType     string `json:"user_type" db:"user_type"`
+	ID           int64   `json:"id" db:"id"`
+	Username     string  `json:"username" db:"username"`
+	PasswordHash string  `json:"-" db:"password_hash"`
+	UserType     string  `json:"user_type" db:"user_type"`
+	EngineerID   *int64  `json:"engineer_id,omitempty" db:"engineer_id"`
+	Email        *string `json:"email,omitempty" db:"email"`
+}
+
+// UserRoleUpdate changes the role of a user account. Engineer accounts must be
+// linked to an engineer.
+type UserRoleUpdate struct {
+	Role       string `json:"role" binding:"required"`
+	EngineerID *int64 `json:"engineer_id"`
 }
@@ -0,0 +1,108 @@
+package models
+
+// Roles a user account can hold. The role is stored in users.user_type and carried in
+// the user_type claim of access tokens.
+const (
+	RoleResident   = "resident"
+	RoleEngineer   = "engineer"
+	RoleDispatcher = "dispatcher"
+	RoleSupervisor = "supervisor"
+	RoleAdmin      = "admin"
+)
+
+// Roles lists every role, from least to most privileged
+var Roles = []string{RoleResident, RoleEngineer, RoleDispatcher, RoleSupervisor, RoleAdmin}
+
+// Permissions checked by the API
+const (
+	PermIssuesSupport        = "issues:support"
+	PermIssuesRead           = "issues:read"
+	PermIssuesUpdate         = "issues:update"
+	PermIssuesUpdateAssigned = "issues:update:assigned"
+	PermIssuesAssign         = "issues:assign"
+	PermIssuesMerge          = "issues:merge"
+	PermCommentsInternal     = "comments:internal"
+	PermCommentsModerate     = "comments:moderate"
+	PermEngineersRead        = "engineers:read"
+	PermEngineersManage      = "engineers:manage"
+	PermAnalyticsRead        = "analytics:read"
+	PermUsersManage          = "users:manage"
+)
+
+// rolePermissions is the permission matrix. Engineers may only update the issues
+// assigned to them; dispatchers triage and assign; supervisors also manage engineers
+// and see analytics; admins manage user accounts.
+var rolePermissions = map[string][]string{
+	RoleResident: {
+		PermIssuesSupport,
+	},
+	RoleEngineer: {
+		PermIssuesRead, PermIssuesUpdateAssigned, PermCommentsInternal,
+	},
+	RoleDispatcher: {
+		PermIssuesRead, PermIssuesUpdate, PermIssuesAssign, PermIssuesMerge,
+		PermCommentsInternal, PermCommentsModerate, PermEngineersRead,
+	},
+	RoleSupervisor: {
+		PermIssuesRead, PermIssuesUpdate, PermIssuesAssign, PermIssuesMerge,
+		PermCommentsInternal, PermCommentsModerate, PermEngineersRead, PermEngineersManage,
+		PermAnalyticsRead,
+	},
+	RoleAdmin: {
+		PermIssuesRead, PermIssuesUpdate, PermIssuesAssign, PermIssuesMerge,
+		PermCommentsInternal, PermCommentsModerate, PermEngineersRead, PermEngineersManage,
+		PermAnalyticsRead, PermUsersManage,
+	},
+}
+
+// legacyRoles maps the user types used before roles were introduced. Access tokens
+// signed before the migration still carry them until they expire. Staff get no user
+// management, as anyone with the old registration code could become staff.
+var legacyRoles = map[string]string{
+	"public": RoleResident,
+	"staff":  RoleDispatcher,
+}
+
+// NormalizeRole resolves legacy user types to their role
+func NormalizeRole(role string) string {
+	if r, ok := legacyRoles[role]; ok {
+		return r
+	}
+	return role
+}
+
+// ValidateRole reports whether the given role exists
+func ValidateRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[NormalizeRole(role)] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaffRole reports whether the role belongs to council staff rather than a resident
+func IsStaffRole(role string) bool {
+	role = NormalizeRole(role)
+	return ValidateRole(role) && role != RoleResident
+}
+
+// HighestRole returns the most privileged of the given roles, or "" if there are none
+func HighestRole(roles []string) string {
+	highest := ""
+	rank := -1
+	for _, role := range roles {
+		for i, r := range Roles {
+			if r == role && i > rank {
+				highest, rank = role, i
+			}
+		}
+	}
+	return highest
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{RoleResident, PermIssuesSupport, true},
+		{RoleResident, PermIssuesRead, false},
+		{RoleEngineer, PermIssuesUpdateAssigned, true},
+		{RoleEngineer, PermIssuesUpdate, false},
+		{RoleEngineer, PermIssuesAssign, false},
+		{RoleDispatcher, PermIssuesAssign, true},
+		{RoleDispatcher, PermEngineersManage, false},
+		{RoleSupervisor, PermAnalyticsRead, true},
+		{RoleSupervisor, PermUsersManage, false},
+		{RoleAdmin, PermUsersManage, true},
+		{"staff", PermIssuesAssign, true},
+		{"staff", PermUsersManage, false},
+		{"public", PermIssuesSupport, true},
+		{"unknown", PermIssuesSupport, false},
+		{"", PermIssuesRead, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, HasPermission(tt.role, tt.permission), "%s %s", tt.role, tt.permission)
+	}
+}
+
+func TestIsStaffRole(t *testing.T) {
+	assert.False(t, IsStaffRole(RoleResident))
+	assert.False(t, IsStaffRole("public"))
+	assert.False(t, IsStaffRole("unknown"))
+	assert.True(t, IsStaffRole(RoleEngineer))
+	assert.True(t, IsStaffRole(RoleAdmin))
+	assert.True(t, IsStaffRole("staff"))
+}
+
+func TestValidateInviteRole(t *testing.T) {
+	assert.True(t, ValidateInviteRole(RoleDispatcher))
+	assert.True(t, ValidateInviteRole(RoleEngineer))
+	assert.False(t, ValidateInviteRole(RoleResident))
+	assert.False(t, ValidateInviteRole("staff"))
+}
+
+func TestHighestRole(t *testing.T) {
+	assert.Equal(t, RoleSupervisor, HighestRole([]string{RoleEngineer, RoleSupervisor, RoleDispatcher}))
+	assert.Equal(t, RoleEngineer, HighestRole([]string{"unknown", RoleEngineer}))
+	assert.Equal(t, "", HighestRole(nil))
+}
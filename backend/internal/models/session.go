@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RefreshToken is a stored refresh token. Only the SHA-256 hash of the token is kept,
+// along with the ID of the access token issued with it so both can be revoked together.
+type RefreshToken struct {
+	ID              int64      `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username"`
+	TokenHash       string     `json:"-" db:"token_hash"`
+	AccessJTI       string     `json:"-" db:"access_jti"`
+	AccessExpiresAt time.Time  `json:"-" db:"access_expires_at"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TOTPEnrollment is a user's authenticator app. It is pending until a first code
+// confirms that the app was set up correctly.
+type TOTPEnrollment struct {
+	Username     string     `json:"username" db:"username"`
+	Secret       string     `json:"-" db:"secret"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	LastUsedStep *int64     `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Enabled reports whether the enrolment has been confirmed
+func (e *TOTPEnrollment) Enabled() bool {
+	return e != nil && e.ConfirmedAt != nil
+}
+
+// RequiresTwoFactor reports whether accounts with the role must use two-factor
+// authentication. Every staff role can see personal data, so only residents may opt out.
+func RequiresTwoFactor(role string) bool {
+	return IsStaffRole(role)
+}
@@ -0,0 +1,27 @@
+package models
+
+// WorkItem is an open issue in an engineer's work queue
+type WorkItem struct {
+	Issue
+	DistanceMeters *float64 `json:"distance_meters,omitempty" db:"distance_meters"`
+}
+
+// IssueWork is an engineer's progress on one of their issues: starting work, or
+// resolving it with a note and photos of the finished work
+type IssueWork struct {
+	Status         IssueStatus
+	ResolutionNote *string
+	AfterImages    []string
+	UpdatedBy      string
+}
+
+// CanWorkTransition reports whether an engineer may move an issue from one status to
+// another. Unlike CanTransition the status must change, so work is only recorded once.
+func CanWorkTransition(from, to IssueStatus) bool {
+	return from != to && CanTransition(from, to)
+}
+
+// ValidateEngineerStatus reports whether engineers may move their issues to the status
+func ValidateEngineerStatus(s IssueStatus) bool {
+	return s == StatusInProgress || s == StatusResolved
+}
@@ -0,0 +1,59 @@
+package models
+
+// issueTransitions defines the issue lifecycle. Each status maps to the
+// statuses an issue may move to next.
+var issueTransitions = map[IssueStatus][]IssueStatus{
+	StatusNew:        {StatusTriaged, StatusRejected, StatusDuplicate},
+	StatusTriaged:    {StatusAssigned, StatusRejected, StatusDuplicate},
+	StatusAssigned:   {StatusInProgress, StatusTriaged},
+	StatusInProgress: {StatusResolved, StatusAssigned},
+	StatusResolved:   {StatusClosed, StatusReopened},
+	StatusClosed:     {StatusReopened},
+	StatusRejected:   {StatusReopened},
+	StatusDuplicate:  {StatusReopened},
+	StatusReopened:   {StatusTriaged, StatusAssigned, StatusInProgress},
+}
+
+// AllowedTransitions returns the statuses an issue in the given status may move to.
+func AllowedTransitions(from IssueStatus) []IssueStatus {
+	next := issueTransitions[from]
+	allowed := make([]IssueStatus, len(next))
+	copy(allowed, next)
+	return allowed
+}
+
+// CanTransition reports whether an issue may move from one status to another.
+// Staying in the same status is always allowed.
+func CanTransition(from, to IssueStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range issueTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignmentTransitions returns the statuses an issue in the given status passes through
+// when an engineer is assigned to it, ending with ASSIGNED. Assigning a NEW issue also
+// triages it. Issues already being worked on, or closed, keep their status.
+func AssignmentTransitions(from IssueStatus) []IssueStatus {
+	switch from {
+	case StatusNew:
+		return []IssueStatus{StatusTriaged, StatusAssigned}
+	case StatusTriaged, StatusReopened:
+		return []IssueStatus{StatusAssigned}
+	}
+	return nil
+}
+
+// IsOpenStatus reports whether an issue in the given status still needs work.
+func IsOpenStatus(s IssueStatus) bool {
+	switch s {
+	case StatusResolved, StatusClosed, StatusRejected, StatusDuplicate:
+		return false
+	}
+	return true
+}
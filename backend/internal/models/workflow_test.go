@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatusNew, StatusTriaged))
+	assert.True(t, CanTransition(StatusTriaged, StatusAssigned))
+	assert.True(t, CanTransition(StatusAssigned, StatusInProgress))
+	assert.True(t, CanTransition(StatusInProgress, StatusResolved))
+	assert.True(t, CanTransition(StatusResolved, StatusClosed))
+	assert.True(t, CanTransition(StatusClosed, StatusReopened))
+	assert.True(t, CanTransition(StatusReopened, StatusInProgress))
+	assert.True(t, CanTransition(StatusNew, StatusNew))
+
+	assert.False(t, CanTransition(StatusNew, StatusResolved))
+	assert.False(t, CanTransition(StatusResolved, StatusNew))
+	assert.False(t, CanTransition(StatusClosed, StatusInProgress))
+	assert.False(t, CanTransition("UNKNOWN", StatusNew))
+}
+
+func TestCanWorkTransition(t *testing.T) {
+	assert.True(t, CanWorkTransition(StatusAssigned, StatusInProgress))
+	assert.True(t, CanWorkTransition(StatusInProgress, StatusResolved))
+
+	assert.False(t, CanWorkTransition(StatusInProgress, StatusInProgress))
+	assert.False(t, CanWorkTransition(StatusResolved, StatusResolved))
+	assert.False(t, CanWorkTransition(StatusAssigned, StatusResolved))
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	assert.Equal(t, []IssueStatus{StatusClosed, StatusReopened}, AllowedTransitions(StatusResolved))
+	assert.Empty(t, AllowedTransitions("UNKNOWN"))
+
+	// Callers must not be able to modify the transition table
+	allowed := AllowedTransitions(StatusNew)
+	allowed[0] = StatusClosed
+	assert.False(t, CanTransition(StatusNew, StatusClosed))
+}
+
+func TestAssignmentTransitions(t *testing.T) {
+	assert.Equal(t, []IssueStatus{StatusTriaged, StatusAssigned}, AssignmentTransitions(StatusNew))
+	assert.Equal(t, []IssueStatus{StatusAssigned}, AssignmentTransitions(StatusTriaged))
+	assert.Equal(t, []IssueStatus{StatusAssigned}, AssignmentTransitions(StatusReopened))
+	assert.Empty(t, AssignmentTransitions(StatusAssigned))
+	assert.Empty(t, AssignmentTransitions(StatusInProgress))
+	assert.Empty(t, AssignmentTransitions(StatusClosed))
+}
+
+func TestIsOpenStatus(t *testing.T) {
+	assert.True(t, IsOpenStatus(StatusNew))
+	assert.True(t, IsOpenStatus(StatusInProgress))
+	assert.True(t, IsOpenStatus(StatusReopened))
+	assert.False(t, IsOpenStatus(StatusResolved))
+	assert.False(t, IsOpenStatus(StatusClosed))
+	assert.False(t, IsOpenStatus(StatusDuplicate))
+}
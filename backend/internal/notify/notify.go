@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultNotificationFile = "notifications.log"
+
+// Message is a notification for a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users, e.g. password reset tokens
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// FromEnv returns the notifier selected by NOTIFIER: "log" (the default) writes
+// messages to the application log, "file" appends them to NOTIFIER_FILE.
+// Both are meant for local use, as messages can contain secrets.
+func FromEnv() Notifier {
+	switch kind := strings.ToLower(os.Getenv("NOTIFIER")); kind {
+	case "file":
+		path := os.Getenv("NOTIFIER_FILE")
+		if path == "" {
+			path = defaultNotificationFile
+		}
+		return NewFileNotifier(path)
+	case "", "log":
+		return LogNotifier{}
+	default:
+		log.Printf("Unknown NOTIFIER %q, logging notifications instead", kind)
+		return LogNotifier{}
+	}
+}
+
+// LogNotifier writes messages to the application log
+type LogNotifier struct{}
+
+func (LogNotifier) Send(msg Message) error {
+	log.Printf("Notification to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileNotifier appends messages to a file
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Send(msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().UTC().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	n := NewFileNotifier(path)
+
+	assert.NoError(t, n.Send(Message{To: "jane@example.com", Subject: "First", Body: "token-1"}))
+	assert.NoError(t, n.Send(Message{To: "jane@example.com", Subject: "Second", Body: "token-2"}))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: jane@example.com")
+	assert.Contains(t, string(content), "Subject: First")
+	assert.Contains(t, string(content), "token-1")
+	assert.Contains(t, string(content), "token-2")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("NOTIFIER", "")
+	assert.IsType(t, LogNotifier{}, FromEnv())
+
+	t.Setenv("NOTIFIER", "file")
+	t.Setenv("NOTIFIER_FILE", filepath.Join(t.TempDir(), "out.log"))
+	assert.IsType(t, &FileNotifier{}, FromEnv())
+
+	t.Setenv("NOTIFIER", "carrier-pigeon")
+	assert.IsType(t, LogNotifier{}, FromEnv())
+}
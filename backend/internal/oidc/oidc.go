@@ -0,0 +1,366 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"chalkstone.council/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultGroupsClaim = "groups"
+	httpTimeout        = 10 * time.Second
+
+	// keysRefreshInterval limits how often an unknown kid makes us fetch the keys again
+	keysRefreshInterval = time.Minute
+)
+
+// Config is the client registration with the identity provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim is the ID token claim listing the user's groups
+	GroupsClaim string
+	// GroupRoles maps IdP groups to staff roles. Users in none of the groups can't log in.
+	GroupRoles map[string]string
+	// PostLoginRedirect is the frontend page the callback sends the tokens to, in the
+	// URL fragment. Without it the callback responds with JSON.
+	PostLoginRedirect string
+}
+
+// LoadConfig reads OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL,
+// OIDC_SCOPES, OIDC_GROUPS_CLAIM, OIDC_GROUP_ROLES and OIDC_POST_LOGIN_REDIRECT.
+// OIDC_GROUP_ROLES is a comma separated list of group=role pairs, e.g.
+// "highways-dispatch=dispatcher,it-admins=admin".
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Issuer:            strings.TrimSpace(os.Getenv("OIDC_ISSUER")),
+		ClientID:          strings.TrimSpace(os.Getenv("OIDC_CLIENT_ID")),
+		ClientSecret:      os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:       strings.TrimSpace(os.Getenv("OIDC_REDIRECT_URL")),
+		Scopes:            []string{"openid", "profile", "email"},
+		GroupsClaim:       defaultGroupsClaim,
+		GroupRoles:        map[string]string{},
+		PostLoginRedirect: strings.TrimSpace(os.Getenv("OIDC_POST_LOGIN_REDIRECT")),
+	}
+	if scopes := strings.Fields(strings.ReplaceAll(os.Getenv("OIDC_SCOPES"), ",", " ")); len(scopes) > 0 {
+		cfg.Scopes = scopes
+	}
+	if claim := strings.TrimSpace(os.Getenv("OIDC_GROUPS_CLAIM")); claim != "" {
+		cfg.GroupsClaim = claim
+	}
+
+	for _, pair := range strings.Split(os.Getenv("OIDC_GROUP_ROLES"), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || !models.IsStaffRole(role) {
+			return cfg, fmt.Errorf("invalid OIDC_GROUP_ROLES entry %q, expected group=staff role", pair)
+		}
+		cfg.GroupRoles[group] = models.NormalizeRole(role)
+	}
+
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return cfg, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required with OIDC_ISSUER")
+	}
+	return cfg, nil
+}
+
+// FromEnv returns the provider configured by LoadConfig, or nil if OIDC_ISSUER is unset
+// or the configuration is invalid, which disables OIDC login
+func FromEnv() *Provider {
+	if os.Getenv("OIDC_ISSUER") == "" {
+		return nil
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Printf("OIDC login disabled: %v", err)
+		return nil
+	}
+	return NewProvider(cfg, &http.Client{Timeout: httpTimeout})
+}
+
+// RoleForGroups returns the most privileged role the groups map to, or "" if none do
+func (c Config) RoleForGroups(groups []string) string {
+	roles := []string{}
+	for _, group := range groups {
+		if role, ok := c.GroupRoles[group]; ok {
+			roles = append(roles, role)
+		}
+	}
+	return models.HighestRole(roles)
+}
+
+// Claims are the ID token claims used to provision a user
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	Groups            []string
+}
+
+// metadata is the part of the provider's discovery document we use
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to an OpenID Connect identity provider. The discovery document and
+// signing keys are fetched on first use, so the API starts even if the provider is down.
+type Provider struct {
+	Config
+	client *http.Client
+
+	mu          sync.Mutex
+	metadata    *metadata
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	return &Provider{Config: cfg, client: client}
+}
+
+// NewPKCE returns a random PKCE code verifier and its S256 code challenge
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString returns 32 random bytes, URL-safe base64 encoded, for a state or nonce
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the provider URL that starts an authorization code login with PKCE
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the claims of the verified ID
+// token, which must carry the nonce the login was started with
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Claims, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.getJSON(req, &token)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	if status != http.StatusOK || token.Error != "" {
+		return nil, fmt.Errorf("token request failed with status %d: %s %s", status, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return p.verifyIDToken(ctx, meta, token.IDToken, nonce)
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, meta *metadata, idToken, nonce string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, mapClaims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if got, _ := mapClaims["nonce"].(string); got == "" || got != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	claims := &Claims{Issuer: meta.Issuer}
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.EmailVerified, _ = mapClaims["email_verified"].(bool)
+	claims.PreferredUsername, _ = mapClaims["preferred_username"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.Groups = stringList(mapClaims[p.GroupsClaim])
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: no subject")
+	}
+	return claims, nil
+}
+
+// stringList reads a claim that holds either a list of strings or a single string
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(p.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	status, err := p.getJSON(req, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery failed with status %d", status)
+	}
+	if meta.Issuer != p.Issuer {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", meta.Issuer, p.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// key returns the provider's signing key with the given kid, fetching the key set again
+// if the kid is unknown, e.g. after the provider rotated its keys
+func (p *Provider) key(ctx context.Context, meta *metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jwkSet
+	status, err := p.getJSON(req, &set)
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing keys failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("fetching signing keys failed with status %d", status)
+	}
+
+	p.keys = set.publicKeys()
+	p.keysFetched = time.Now()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) getJSON(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
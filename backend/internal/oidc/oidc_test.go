@@ -0,0 +1,139 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"chalkstone.council/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorize starts a login at the mock provider and returns the code it redirects back with
+func authorize(t *testing.T, p *Provider, state, nonce, challenge string) string {
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, challenge)
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/auth/oidc/callback", callback.Path)
+	assert.Equal(t, state, callback.Query().Get("state"))
+	return callback.Query().Get("code")
+}
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Provider) {
+	mock, server, err := oidctest.NewServer("council-api")
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	p := NewProvider(Config{
+		Issuer:      mock.Issuer,
+		ClientID:    "council-api",
+		RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:      []string{"openid"},
+		GroupsClaim: "groups",
+		GroupRoles:  map[string]string{"dispatch": "dispatcher", "it": "admin"},
+	}, server.Client())
+	return p, mock
+}
+
+func TestExchange(t *testing.T) {
+	p, mock := newTestProvider(t)
+	mock.SetUser(oidctest.User{
+		Subject: "abc-123", Email: "j.smith@chalkstone.gov.uk", PreferredUsername: "jsmith",
+		Groups: []string{"dispatch", "everyone"},
+	})
+
+	verifier, challenge, err := NewPKCE()
+	require.NoError(t, err)
+	code := authorize(t, p, "state-1", "nonce-1", challenge)
+
+	claims, err := p.Exchange(context.Background(), code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, mock.Issuer, claims.Issuer)
+	assert.Equal(t, "abc-123", claims.Subject)
+	assert.Equal(t, "j.smith@chalkstone.gov.uk", claims.Email)
+	assert.Equal(t, "jsmith", claims.PreferredUsername)
+	assert.Equal(t, "dispatcher", p.RoleForGroups(claims.Groups))
+
+	// Codes are single use
+	_, err = p.Exchange(context.Background(), code, verifier, "nonce-1")
+	assert.Error(t, err)
+}
+
+func TestExchangeRejects(t *testing.T) {
+	p, mock := newTestProvider(t)
+	verifier, challenge, err := NewPKCE()
+	require.NoError(t, err)
+
+	// Wrong PKCE verifier
+	code := authorize(t, p, "s", "n", challenge)
+	_, err = p.Exchange(context.Background(), code, "not-the-verifier", "n")
+	assert.Error(t, err)
+
+	// Replayed ID token from another login
+	code = authorize(t, p, "s", "other-nonce", challenge)
+	_, err = p.Exchange(context.Background(), code, verifier, "n")
+	assert.Error(t, err)
+
+	// Tokens meant for another client or already expired
+	meta, err := p.discover(context.Background())
+	require.NoError(t, err)
+	for name, claims := range map[string]jwt.MapClaims{
+		"wrong audience": {"iss": mock.Issuer, "sub": "x", "aud": "other", "nonce": "n",
+			"exp": time.Now().Add(time.Minute).Unix()},
+		"expired": {"iss": mock.Issuer, "sub": "x", "aud": "council-api", "nonce": "n",
+			"exp": time.Now().Add(-time.Minute).Unix()},
+		"wrong issuer": {"iss": "https://evil.example", "sub": "x", "aud": "council-api", "nonce": "n",
+			"exp": time.Now().Add(time.Minute).Unix()},
+	} {
+		token, err := mock.SignIDToken(claims)
+		require.NoError(t, err)
+		_, err = p.verifyIDToken(context.Background(), meta, token, "n")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRoleForGroups(t *testing.T) {
+	cfg := Config{GroupRoles: map[string]string{"dispatch": "dispatcher", "it": "admin", "crew": "engineer"}}
+
+	assert.Equal(t, "admin", cfg.RoleForGroups([]string{"crew", "it", "dispatch"}))
+	assert.Equal(t, "engineer", cfg.RoleForGroups([]string{"crew"}))
+	assert.Equal(t, "", cfg.RoleForGroups([]string{"everyone"}))
+	assert.Equal(t, "", cfg.RoleForGroups(nil))
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("OIDC_ISSUER", "https://login.example.gov.uk")
+	t.Setenv("OIDC_CLIENT_ID", "council-api")
+	t.Setenv("OIDC_REDIRECT_URL", "https://api.example.gov.uk/api/auth/oidc/callback")
+	t.Setenv("OIDC_GROUP_ROLES", "highways-dispatch=dispatcher, it-admins=admin")
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"highways-dispatch": "dispatcher", "it-admins": "admin"}, cfg.GroupRoles)
+	assert.Equal(t, "groups", cfg.GroupsClaim)
+	assert.NotNil(t, FromEnv())
+
+	// Residents sign up locally, the IdP only grants staff roles
+	t.Setenv("OIDC_GROUP_ROLES", "everyone=resident")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+	assert.Nil(t, FromEnv())
+
+	t.Setenv("OIDC_ISSUER", "")
+	assert.Nil(t, FromEnv())
+}
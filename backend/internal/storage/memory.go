@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+}
+
+// MemoryStore keeps objects in memory, for tests and running the API without storage
+type MemoryStore struct {
+	urlSigner
+
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewMemoryStore returns an empty store whose presigned URLs point at baseURL
+func NewMemoryStore(baseURL string, signingKey []byte) *MemoryStore {
+	return &MemoryStore{
+		urlSigner: newURLSigner(baseURL, signingKey),
+		objects:   map[string]memoryObject{},
+	}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = memoryObject{data: data, contentType: contentType}
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ObjectInfo{}, ErrNotFound
+	}
+	info := ObjectInfo{Key: key, ContentType: obj.contentType, Size: int64(len(obj.data))}
+	return io.NopCloser(bytes.NewReader(obj.data)), info, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStore) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return s.sign(key, expiry), nil
+}
+
+// Keys returns the keys of the stored objects, in no particular order
+func (s *MemoryStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	return keys
+}
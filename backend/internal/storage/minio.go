@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// defaultMinIORegion is the region MinIO uses unless it is configured with another
+const defaultMinIORegion = "us-east-1"
+
+// MinIOConfig locates a bucket on MinIO or another S3 compatible service
+type MinIOConfig struct {
+	// Endpoint is host:port, optionally prefixed with http:// or https://
+	Endpoint string
+	// PublicEndpoint is the endpoint browsers reach the service at, which presigned
+	// URLs are made for. It defaults to Endpoint, which is often an address only
+	// reachable inside the deployment, such as minio:9000 under docker-compose.
+	PublicEndpoint string
+	// Region of the bucket, defaulting to MinIO's. Presigned URLs are signed for it.
+	Region    string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+}
+
+// MinIOStore keeps objects in a private MinIO bucket and hands out presigned URLs to
+// read them
+type MinIOStore struct {
+	client *minio.Client
+	// presigner signs URLs for the public endpoint; it never connects to it
+	presigner *minio.Client
+	bucket    string
+}
+
+// NewMinIOStore returns a store for the bucket. It doesn't connect until first used.
+func NewMinIOStore(cfg MinIOConfig) (*MinIOStore, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("MINIO_ENDPOINT and MINIO_BUCKET are required")
+	}
+	if cfg.PublicEndpoint == "" {
+		cfg.PublicEndpoint = cfg.Endpoint
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultMinIORegion
+	}
+
+	creds := credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, "")
+	endpoint, secure := splitEndpoint(cfg.Endpoint)
+	client, err := minio.New(endpoint, &minio.Options{Creds: creds, Secure: secure, Region: cfg.Region})
+	if err != nil {
+		return nil, err
+	}
+	// With the region known the client doesn't have to ask the endpoint for it, so
+	// URLs can be signed for an endpoint the server itself can't reach
+	publicEndpoint, publicSecure := splitEndpoint(cfg.PublicEndpoint)
+	presigner, err := minio.New(publicEndpoint, &minio.Options{Creds: creds, Secure: publicSecure, Region: cfg.Region})
+	if err != nil {
+		return nil, err
+	}
+	return &MinIOStore{client: client, presigner: presigner, bucket: cfg.Bucket}, nil
+}
+
+// splitEndpoint strips the scheme from an endpoint URL and reports whether it uses TLS
+func splitEndpoint(endpoint string) (string, bool) {
+	if rest, ok := strings.CutPrefix(endpoint, "https://"); ok {
+		return strings.TrimSuffix(rest, "/"), true
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(endpoint, "http://"), "/"), false
+}
+
+func (s *MinIOStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *MinIOStore) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, minioError(err)
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, minioError(err)
+	}
+	return obj, ObjectInfo{Key: key, ContentType: info.ContentType, Size: info.Size}, nil
+}
+
+func (s *MinIOStore) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *MinIOStore) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	u, err := s.presigner.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// minioError maps missing objects to ErrNotFound
+func minioError(err error) error {
+	if resp := minio.ToErrorResponse(err); resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -1,97 +1,130 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"strings"
 
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"chalkstone.council/internal/imaging"
+	"chalkstone.council/internal/models"
+
 	"github.com/google/uuid"
 )
 
-var (
-	endpoint        = os.Getenv("MINIO_ENDPOINT")
-	accessKeyID     = os.Getenv("MINIO_ACCESS_KEY")
-	secretAccessKey = os.Getenv("MINIO_SECRET_KEY")
-	bucketName      = os.Getenv("MINIO_BUCKET")
-)
+// maxImageBytes limits the size of an uploaded image file
+const maxImageBytes = 20 << 20
+
+// metadataBytes is how much of a JPEG is read to find its EXIF data, which has to fit
+// in one 64KB segment near the start of the file
+const metadataBytes = 256 << 10
 
-func UploadImage(file multipart.File, fileName string) (string, error) {
+// ImageMetadata reads the EXIF metadata of an uploaded JPEG, such as where and when
+// it was taken, before UploadImage strips it. Other image types give empty metadata.
+// The file is left at its start.
+func ImageMetadata(file multipart.File) (imaging.Metadata, error) {
+	head, err := io.ReadAll(io.LimitReader(file, metadataBytes))
+	if err != nil {
+		return imaging.Metadata{}, fmt.Errorf("failed to read file")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return imaging.Metadata{}, fmt.Errorf("failed to process file")
+	}
+	return imaging.ReadMetadata(head), nil
+}
+
+// UploadImage checks that the file is a JPEG, PNG, GIF or WEBP image, removes its
+// metadata, turns it upright and caps its size, and stores it with medium and
+// thumbnail renditions under new random keys. Returns the keys, the time the photo
+// was taken and the type, size and checksum of the stored original; renditions the
+// image is too small for use the original's key.
+func UploadImage(ctx context.Context, store ObjectStore, file multipart.File, fileName string) (models.IssueImage, error) {
 	// Read the first 512 bytes to detect the content type
 	buffer := make([]byte, 512)
 	n, err := file.Read(buffer)
 	if err != nil && err != io.EOF {
 		log.Printf("Error reading file for content type detection: %v", err)
-		return "", fmt.Errorf("failed to read file header")
+		return models.IssueImage{}, fmt.Errorf("failed to read file header")
 	}
-	// Reset the read pointer so minio gets the full file
+	// Reset the read pointer so the whole file is processed
 	_, seekErr := file.Seek(0, io.SeekStart)
 	if seekErr != nil {
 		log.Printf("Error seeking file pointer: %v", seekErr)
-		return "", fmt.Errorf("failed to process file")
+		return models.IssueImage{}, fmt.Errorf("failed to process file")
 	}
 
 	// Detect the actual content type
 	contentType := http.DetectContentType(buffer[:n])
 
 	// Validate content type - allow only common image types
-	allowedTypes := map[string]string{
-		"image/jpeg": ".jpg",
-		"image/png":  ".png",
-		"image/gif":  ".gif",
-		"image/webp": ".webp",
+	allowedTypes := map[string]bool{
+		"image/jpeg": true,
+		"image/png":  true,
+		"image/gif":  true,
+		"image/webp": true,
 	}
-	ext, ok := allowedTypes[contentType]
-	if !ok {
+	if !allowedTypes[contentType] {
 		log.Printf("Upload rejected: Invalid content type '%s' detected for file '%s'", contentType, fileName)
-		return "", fmt.Errorf("invalid file type: %s. Only JPEG, PNG, GIF, WEBP allowed", contentType)
+		return models.IssueImage{}, fmt.Errorf("invalid file type: %s. Only JPEG, PNG, GIF, WEBP allowed", contentType)
 	}
 
-	// Generate a unique filename using UUID + validated extension
-	// This prevents path traversal and filename conflicts/overwrites
-	secureFileName := uuid.New().String() + ext
-
-	minioClient, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: false, // Assuming local dev/docker setup, use true for production HTTPS
-	})
+	data, err := io.ReadAll(io.LimitReader(file, maxImageBytes+1))
 	if err != nil {
-		log.Printf("Failed to initialize MinIO client: %v", err)
-		return "", err
+		return models.IssueImage{}, fmt.Errorf("failed to read file")
 	}
-
-	// Use file size from header if available, otherwise -1 for unknown size (streams whole file)
-	// Determine file size for PutObject - important for MinIO progress/resource allocation
-	// Try to get size from the file handle itself if it supports it
-	var fileSize int64 = -1 // Default to unknown size
-	if seeker, ok := file.(io.Seeker); ok {
-		currentPos, _ := seeker.Seek(0, io.SeekCurrent) // Get current position
-		size, err := seeker.Seek(0, io.SeekEnd) // Seek to end to get size
-		if err == nil {
-			fileSize = size
-		}
-		_, _ = seeker.Seek(currentPos, io.SeekStart) // Reset position to where it was
+	if len(data) > maxImageBytes {
+		return models.IssueImage{}, fmt.Errorf("image is larger than %d MB", maxImageBytes>>20)
 	}
 
-	_, err = minioClient.PutObject(context.Background(), bucketName, secureFileName, file, fileSize, minio.PutObjectOptions{ContentType: contentType})
+	// Re-encode the image so that location and device metadata never reach the store
+	processed, err := imaging.Process(data, contentType)
 	if err != nil {
-		log.Printf("Failed to upload image '%s' (original: '%s'): %v", secureFileName, fileName, err)
-		return "", err
+		log.Printf("Upload rejected: could not process image '%s': %v", fileName, err)
+		return models.IssueImage{}, fmt.Errorf("failed to process image: %w", err)
 	}
 
-	// Create a local copy of endpoint for URL formation
-	urlEndpoint := endpoint
-	// For Docker/local access: If internal endpoint contains "minio", use localhost for the URL
-	if strings.Contains(urlEndpoint, "minio") {
-		urlEndpoint = "localhost:9000" // Make sure this matches your docker-compose port mapping
+	// Generate unique keys from a UUID and the rendition's extension
+	// This prevents path traversal and filename conflicts/overwrites
+	id := uuid.New().String()
+	checksum := sha256.Sum256(processed.Original.Data)
+	keys := models.IssueImage{
+		CapturedAt:  processed.Metadata.CapturedAt,
+		ContentType: processed.Original.ContentType,
+		Size:        int64(len(processed.Original.Data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+	}
+	stored := []string{}
+	put := func(r *imaging.Rendition, suffix string) (string, error) {
+		if r == nil {
+			return keys.Original, nil
+		}
+		key := id + suffix + r.Ext
+		if err := store.Put(ctx, key, bytes.NewReader(r.Data), int64(len(r.Data)), r.ContentType); err != nil {
+			log.Printf("Failed to upload image '%s' (original: '%s'): %v", key, fileName, err)
+			return "", err
+		}
+		stored = append(stored, key)
+		return key, nil
 	}
 
-	imageURL := fmt.Sprintf("http://%s/%s/%s", urlEndpoint, bucketName, secureFileName)
-	return imageURL, nil
+	if keys.Original, err = put(processed.Original, ""); err == nil {
+		if keys.Medium, err = put(processed.Medium, "-medium"); err == nil {
+			keys.Thumb, err = put(processed.Thumb, "-thumb")
+		}
+	}
+	if err != nil {
+		// Don't leave some of the renditions behind
+		for _, key := range stored {
+			if delErr := store.Delete(ctx, key); delErr != nil {
+				log.Printf("Failed to remove image '%s' after failed upload: %v", key, delErr)
+			}
+		}
+		return models.IssueImage{}, err
+	}
+	return keys, nil
 }
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultStorageDir = "uploads"
+
+// ErrNotFound is returned when reading an object that doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+}
+
+// ObjectStore keeps uploaded files, such as issue photos, by key
+type ObjectStore interface {
+	// Put stores the object, replacing any object with the same key. Size may be -1
+	// if it isn't known.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object for reading. The caller closes it. Returns ErrNotFound if
+	// there is no such object.
+	Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error)
+	// Delete removes the object. Deleting a missing object isn't an error.
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the object without further credentials
+	// until it expires
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// PresignVerifier is implemented by stores whose presigned URLs point at the API's
+// image route rather than at a storage service, which then has to check them
+type PresignVerifier interface {
+	// VerifyPresigned reports whether the query of a request for the key carries a
+	// valid, unexpired signature from PresignGet
+	VerifyPresigned(key string, query url.Values) bool
+}
+
+// FromEnv returns the store selected by STORAGE_BACKEND: "minio" (the default) uses
+// the MINIO_* settings, "filesystem" keeps objects in STORAGE_DIR and "memory" keeps
+// them in memory until the process exits. Presigned URLs of the filesystem and memory
+// stores point at IMAGE_BASE_URL and are signed with STORAGE_SIGNING_KEY.
+func FromEnv() (ObjectStore, error) {
+	switch backend := strings.ToLower(os.Getenv("STORAGE_BACKEND")); backend {
+	case "", "minio":
+		store, err := NewMinIOStore(MinIOConfig{
+			Endpoint:       os.Getenv("MINIO_ENDPOINT"),
+			PublicEndpoint: os.Getenv("MINIO_PUBLIC_ENDPOINT"),
+			Region:         os.Getenv("MINIO_REGION"),
+			AccessKey:      os.Getenv("MINIO_ACCESS_KEY"),
+			SecretKey:      os.Getenv("MINIO_SECRET_KEY"),
+			Bucket:         os.Getenv("MINIO_BUCKET"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "filesystem":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = defaultStorageDir
+		}
+		store, err := NewFileStore(dir, baseURLFromEnv(), signingKeyFromEnv())
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(baseURLFromEnv(), signingKeyFromEnv()), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+// baseURLFromEnv returns IMAGE_BASE_URL, the public URL of the API's image route that
+// the filesystem and memory stores presign URLs under
+func baseURLFromEnv() string {
+	if base := strings.TrimSpace(os.Getenv("IMAGE_BASE_URL")); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return "http://localhost:" + port + "/api/images"
+}
+
+// signingKeyFromEnv returns STORAGE_SIGNING_KEY, or a random key if it is unset, in
+// which case presigned URLs stop working when the server restarts
+func signingKeyFromEnv() []byte {
+	if key := os.Getenv("STORAGE_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	log.Printf("STORAGE_SIGNING_KEY not set, presigned image URLs will not survive a restart")
+	return nil
+}
+
+// validateKey rejects keys that could escape the store, such as "../secret"
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
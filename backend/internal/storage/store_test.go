@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testObjectStore checks the behaviour every ObjectStore shares
+func testObjectStore(t *testing.T, store ObjectStore) {
+	ctx := context.Background()
+	data := []byte("\x89PNG\r\n\x1a\nimage data")
+
+	assert.NoError(t, store.Put(ctx, "a1b2.png", bytes.NewReader(data), int64(len(data)), "image/png"))
+
+	body, info, err := store.Get(ctx, "a1b2.png")
+	assert.NoError(t, err)
+	got, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, data, got)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, int64(len(data)), info.Size)
+
+	presigned, err := store.PresignGet(ctx, "a1b2.png", time.Minute)
+	assert.NoError(t, err)
+	assert.Contains(t, presigned, "a1b2.png")
+
+	assert.NoError(t, store.Delete(ctx, "a1b2.png"))
+	_, _, err = store.Get(ctx, "a1b2.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "a1b2.png"), "deleting twice is fine")
+
+	for _, key := range []string{"", "../escape.png", "/etc/passwd", "a/../../b.png", `a\b.png`} {
+		assert.Error(t, store.Put(ctx, key, bytes.NewReader(data), -1, "image/png"), key)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testObjectStore(t, NewMemoryStore("http://localhost:8080/api/images", nil))
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "uploads"), "http://localhost:8080/api/images", nil)
+	assert.NoError(t, err)
+	testObjectStore(t, store)
+
+	// Nothing is written outside the directory
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPresignedURLs(t *testing.T) {
+	store := NewMemoryStore("http://localhost:8080/api/images", []byte("signing-key"))
+	presigned, err := store.PresignGet(context.Background(), "a1b2.jpg", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(presigned, "http://localhost:8080/api/images/a1b2.jpg?"))
+
+	u, _ := url.Parse(presigned)
+	assert.True(t, store.VerifyPresigned("a1b2.jpg", u.Query()))
+	assert.False(t, store.VerifyPresigned("other.jpg", u.Query()), "signature is bound to the key")
+
+	other := NewMemoryStore("http://localhost:8080/api/images", []byte("another-key"))
+	assert.False(t, other.VerifyPresigned("a1b2.jpg", u.Query()))
+
+	expired, _ := store.PresignGet(context.Background(), "a1b2.jpg", -time.Minute)
+	u, _ = url.Parse(expired)
+	assert.False(t, store.VerifyPresigned("a1b2.jpg", u.Query()))
+}
+
+// writeJPEG writes a w×h JPEG with an EXIF segment to dir
+func writeJPEG(t *testing.T, dir, name string, w, h int) string {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil))
+	exif := []byte("\xff\xe1\x00\x16Exif\x00\x00II*\x00\x08\x00\x00\x00\x00\x00")
+	data := append(append([]byte{0xff, 0xd8}, exif...), buf.Bytes()[2:]...)
+
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestUploadImage(t *testing.T) {
+	store := NewMemoryStore("", nil)
+	dir := t.TempDir()
+
+	f, _ := os.Open(writeJPEG(t, dir, "photo.jpg", 3000, 2000))
+	defer f.Close()
+	keys, err := UploadImage(context.Background(), store, f, "photo.jpg")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(keys.Original, ".jpg"))
+	assert.True(t, strings.HasSuffix(keys.Medium, "-medium.jpg"))
+	assert.True(t, strings.HasSuffix(keys.Thumb, "-thumb.jpg"))
+	assert.ElementsMatch(t, []string{keys.Original, keys.Medium, keys.Thumb}, store.Keys())
+
+	body, info, err := store.Get(context.Background(), keys.Original)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	assert.Equal(t, "image/jpeg", info.ContentType)
+	assert.NotContains(t, string(data), "Exif", "metadata is stripped")
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 2560, cfg.Width)
+
+	// The stored original is described so it can be checked later
+	checksum := sha256.Sum256(data)
+	assert.Equal(t, "image/jpeg", keys.ContentType)
+	assert.Equal(t, int64(len(data)), keys.Size)
+	assert.Equal(t, hex.EncodeToString(checksum[:]), keys.Checksum)
+
+	// Small images are stored once
+	f2, _ := os.Open(writeJPEG(t, dir, "small.jpg", 200, 100))
+	defer f2.Close()
+	keys, err = UploadImage(context.Background(), store, f2, "small.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, keys.Original, keys.Medium)
+	assert.Equal(t, keys.Original, keys.Thumb)
+	assert.Len(t, store.Keys(), 4)
+
+	// The uploaded name is ignored, the content decides
+	path := filepath.Join(dir, "script.jpg")
+	assert.NoError(t, os.WriteFile(path, []byte("<html><script>alert(1)</script>"), 0600))
+	f3, _ := os.Open(path)
+	defer f3.Close()
+	_, err = UploadImage(context.Background(), store, f3, "script.jpg")
+	assert.Error(t, err)
+	assert.Len(t, store.Keys(), 4)
+}
+
+func TestSplitEndpoint(t *testing.T) {
+	endpoint, secure := splitEndpoint("http://localhost:9000")
+	assert.Equal(t, "localhost:9000", endpoint)
+	assert.False(t, secure)
+
+	endpoint, secure = splitEndpoint("https://s3.example.gov.uk/")
+	assert.Equal(t, "s3.example.gov.uk", endpoint)
+	assert.True(t, secure)
+
+	endpoint, _ = splitEndpoint("minio:9000")
+	assert.Equal(t, "minio:9000", endpoint)
+}
+
+func TestMinIOPresignUsesPublicEndpoint(t *testing.T) {
+	// minio:9000 only resolves inside docker-compose; signing must not need it
+	store, err := NewMinIOStore(MinIOConfig{
+		Endpoint:       "minio:9000",
+		PublicEndpoint: "http://localhost:9000",
+		AccessKey:      "access",
+		SecretKey:      "secret",
+		Bucket:         "issues-bucket",
+	})
+	assert.NoError(t, err)
+
+	presigned, err := store.PresignGet(context.Background(), "a1b2.jpg", 15*time.Minute)
+	assert.NoError(t, err)
+	u, _ := url.Parse(presigned)
+	assert.Equal(t, "localhost:9000", u.Host)
+	assert.Equal(t, "/issues-bucket/a1b2.jpg", u.Path)
+	assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+
+	_, err = store.PresignGet(context.Background(), "../secret", time.Minute)
+	assert.Error(t, err)
+}
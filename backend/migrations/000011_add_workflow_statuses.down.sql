@@ -0,0 +1,17 @@
+-- Postgres cannot drop enum values, so rebuild issue_status without them
+
+-- Map the new statuses back onto the original ones
+ALTER TABLE issues ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE issues ALTER COLUMN status TYPE TEXT;
+
+UPDATE issues SET status = 'NEW' WHERE status IN ('TRIAGED', 'REOPENED');
+UPDATE issues SET status = 'IN_PROGRESS' WHERE status = 'ASSIGNED';
+UPDATE issues SET status = 'CLOSED' WHERE status IN ('REJECTED', 'DUPLICATE');
+
+DROP INDEX IF EXISTS idx_issues_status;
+DROP TYPE issue_status;
+CREATE TYPE issue_status AS ENUM ('NEW', 'IN_PROGRESS', 'RESOLVED', 'CLOSED');
+
+ALTER TABLE issues ALTER COLUMN status TYPE issue_status USING status::issue_status;
+ALTER TABLE issues ALTER COLUMN status SET DEFAULT 'NEW';
+CREATE INDEX IF NOT EXISTS idx_issues_status ON issues(status);
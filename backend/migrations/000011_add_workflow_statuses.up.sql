@@ -0,0 +1,7 @@
+-- Extend issue_status with the full issue lifecycle
+-- CLOSED already exists from the initial schema
+ALTER TYPE issue_status ADD VALUE IF NOT EXISTS 'TRIAGED' AFTER 'NEW';
+ALTER TYPE issue_status ADD VALUE IF NOT EXISTS 'ASSIGNED' AFTER 'TRIAGED';
+ALTER TYPE issue_status ADD VALUE IF NOT EXISTS 'REJECTED';
+ALTER TYPE issue_status ADD VALUE IF NOT EXISTS 'DUPLICATE';
+ALTER TYPE issue_status ADD VALUE IF NOT EXISTS 'REOPENED';
@@ -0,0 +1,23 @@
+-- Audit trail of everything that happens to an issue
+CREATE TABLE issue_events (
+    id SERIAL PRIMARY KEY,
+    issue_id INTEGER NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_events_issue_id ON issue_events(issue_id, created_at);
+
+-- Backfill a creation event for every existing issue
+INSERT INTO issue_events (issue_id, event_type, new_value, actor, created_at)
+SELECT id, 'CREATED', 'NEW', reported_by, created_at
+FROM issues;
+
+-- Backfill the last known resolution for resolved issues
+INSERT INTO issue_events (issue_id, event_type, old_value, new_value, actor, created_at)
+SELECT id, 'STATUS_CHANGED', 'NEW', 'RESOLVED', 'system', resolved_at
+FROM issues
+WHERE resolved_at IS NOT NULL;
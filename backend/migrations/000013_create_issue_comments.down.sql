@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_issue_comments_updated_at ON issue_comments;
+DROP TABLE IF EXISTS issue_comments;
@@ -0,0 +1,18 @@
+-- Comments and internal staff notes on issues
+CREATE TABLE issue_comments (
+    id SERIAL PRIMARY KEY,
+    issue_id INTEGER NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    author VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    internal BOOLEAN NOT NULL DEFAULT FALSE,
+    images TEXT[] DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_issue_comments_issue_id ON issue_comments(issue_id, created_at);
+
+CREATE TRIGGER update_issue_comments_updated_at
+    BEFORE UPDATE ON issue_comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Speed up residents listing their own reports
+CREATE INDEX IF NOT EXISTS idx_issues_reported_by ON issues(reported_by, updated_at DESC);
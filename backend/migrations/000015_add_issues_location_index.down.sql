@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_issues_location;
@@ -0,0 +1,2 @@
+-- Spatial index for map bounding box and radius searches
+CREATE INDEX IF NOT EXISTS idx_issues_location ON issues USING gist (point(longitude, latitude));
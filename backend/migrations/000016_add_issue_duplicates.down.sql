@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS issue_reporters;
+DROP INDEX IF EXISTS idx_issues_duplicate_of;
+ALTER TABLE issues DROP COLUMN IF EXISTS duplicate_of;
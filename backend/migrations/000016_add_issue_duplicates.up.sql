@@ -0,0 +1,16 @@
+-- Link duplicate reports to the issue they were merged into
+ALTER TABLE issues ADD COLUMN duplicate_of BIGINT REFERENCES issues(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_issues_duplicate_of ON issues(duplicate_of);
+
+-- Everyone who reported an issue, including reporters of merged duplicates
+CREATE TABLE IF NOT EXISTS issue_reporters (
+    issue_id BIGINT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    reported_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issue_id, reported_by)
+);
+
+INSERT INTO issue_reporters (issue_id, reported_by, created_at)
+SELECT id, reported_by, created_at FROM issues
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- Residents who are also affected by an issue
+CREATE TABLE IF NOT EXISTS issue_supporters (
+    issue_id BIGINT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issue_id, user_id)
+);
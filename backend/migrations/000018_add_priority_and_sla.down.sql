@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_issues_due_at;
+DROP TRIGGER IF EXISTS trigger_set_due_at ON issues;
+DROP FUNCTION IF EXISTS set_due_at;
+DROP TABLE IF EXISTS sla_targets;
+ALTER TABLE issues DROP COLUMN IF EXISTS due_at;
+ALTER TABLE issues DROP COLUMN IF EXISTS priority;
+DROP TYPE IF EXISTS issue_priority;
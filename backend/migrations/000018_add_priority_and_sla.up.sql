@@ -0,0 +1,63 @@
+DO $$ BEGIN
+CREATE TYPE issue_priority AS ENUM ('LOW', 'MEDIUM', 'HIGH', 'URGENT');
+EXCEPTION
+    WHEN duplicate_object THEN null;
+END $$;
+
+ALTER TABLE issues ADD COLUMN priority issue_priority NOT NULL DEFAULT 'MEDIUM';
+ALTER TABLE issues ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
+
+-- Target resolution time for each issue type and priority
+CREATE TABLE IF NOT EXISTS sla_targets (
+    issue_type issue_type NOT NULL,
+    priority issue_priority NOT NULL,
+    target_hours INTEGER NOT NULL CHECK (target_hours > 0),
+    PRIMARY KEY (issue_type, priority)
+);
+
+INSERT INTO sla_targets (issue_type, priority, target_hours)
+SELECT t.issue_type, p.priority, GREATEST(4, ROUND(t.base_hours * p.factor))
+FROM (VALUES
+    ('POTHOLE'::issue_type, 168),
+    ('STREET_LIGHT'::issue_type, 120),
+    ('GRAFFITI'::issue_type, 336),
+    ('ANTI_SOCIAL'::issue_type, 72),
+    ('FLY_TIPPING'::issue_type, 72),
+    ('BLOCKED_DRAIN'::issue_type, 48)
+) AS t(issue_type, base_hours)
+CROSS JOIN (VALUES
+    ('LOW'::issue_priority, 2.0),
+    ('MEDIUM'::issue_priority, 1.0),
+    ('HIGH'::issue_priority, 0.5),
+    ('URGENT'::issue_priority, 0.125)
+) AS p(priority, factor)
+ON CONFLICT DO NOTHING;
+
+-- Work out the deadline when an issue is reported or its type or priority changes
+CREATE OR REPLACE FUNCTION set_due_at()
+    RETURNS TRIGGER AS $$
+BEGIN
+    SELECT COALESCE(NEW.created_at, CURRENT_TIMESTAMP) + make_interval(hours => target_hours)
+    INTO NEW.due_at
+    FROM sla_targets
+    WHERE issue_type = NEW.type AND priority = NEW.priority;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_due_at
+    BEFORE INSERT OR UPDATE OF type, priority ON issues
+    FOR EACH ROW
+EXECUTE FUNCTION set_due_at();
+
+-- Backfill deadlines without touching updated_at
+ALTER TABLE issues DISABLE TRIGGER update_issues_updated_at;
+
+UPDATE issues i
+SET due_at = i.created_at + make_interval(hours => s.target_hours)
+FROM sla_targets s
+WHERE s.issue_type = i.type AND s.priority = i.priority;
+
+ALTER TABLE issues ENABLE TRIGGER update_issues_updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_issues_due_at ON issues(due_at);
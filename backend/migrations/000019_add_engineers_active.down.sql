@@ -0,0 +1 @@
+ALTER TABLE engineers DROP COLUMN IF EXISTS active;
@@ -0,0 +1,2 @@
+-- Retired engineers are kept for history but can't take new work
+ALTER TABLE engineers ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,2 @@
+-- Why the assignment engine picked the current engineer; cleared on manual assignment
+ALTER TABLE issues ADD COLUMN assignment_reason TEXT;
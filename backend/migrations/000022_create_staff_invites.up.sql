@@ -0,0 +1,15 @@
+-- Single-use invite codes for staff registration, replacing the shared STAFF_SECRET.
+-- Codes are stored as SHA-256 hashes and can only be redeemed with the invited email.
+CREATE TABLE staff_invites (
+    id SERIAL PRIMARY KEY,
+    code_hash CHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE,
+    redeemed_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_staff_invites_email ON staff_invites(LOWER(email));
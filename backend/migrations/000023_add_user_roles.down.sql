@@ -0,0 +1,10 @@
+ALTER TABLE staff_invites DROP COLUMN IF EXISTS engineer_id;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_engineer_link_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+DROP INDEX IF EXISTS idx_users_engineer_id;
+ALTER TABLE users DROP COLUMN IF EXISTS engineer_id;
+
+UPDATE staff_invites SET role = 'staff' WHERE role <> 'staff';
+UPDATE users SET user_type = 'public' WHERE user_type = 'resident';
+UPDATE users SET user_type = 'staff' WHERE user_type <> 'public';
@@ -0,0 +1,16 @@
+-- Replace the public/staff user types with roles. Anyone could register as staff with
+-- the shared code, so existing staff become dispatchers; admins are promoted explicitly.
+UPDATE users SET user_type = 'resident' WHERE user_type = 'public';
+UPDATE users SET user_type = 'dispatcher' WHERE user_type = 'staff';
+UPDATE staff_invites SET role = 'dispatcher' WHERE role = 'staff';
+
+-- Engineer accounts belong to exactly one engineer
+ALTER TABLE users ADD COLUMN engineer_id INTEGER REFERENCES engineers(id);
+CREATE UNIQUE INDEX idx_users_engineer_id ON users(engineer_id) WHERE engineer_id IS NOT NULL;
+
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (user_type IN ('resident', 'engineer', 'dispatcher', 'supervisor', 'admin'));
+ALTER TABLE users ADD CONSTRAINT users_engineer_link_check
+    CHECK ((user_type = 'engineer') = (engineer_id IS NOT NULL));
+
+ALTER TABLE staff_invites ADD COLUMN engineer_id INTEGER REFERENCES engineers(id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_issues_assigned_to;
+ALTER TABLE issues DROP COLUMN IF EXISTS after_images;
+ALTER TABLE issues DROP COLUMN IF EXISTS resolution_note;
@@ -0,0 +1,5 @@
+-- Engineers record how an issue was fixed, with photos of the finished work
+ALTER TABLE issues ADD COLUMN resolution_note TEXT;
+ALTER TABLE issues ADD COLUMN after_images TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_issues_assigned_to ON issues(assigned_to);
@@ -0,0 +1,16 @@
+-- Optional email address, used to deliver password reset tokens
+ALTER TABLE users ADD COLUMN email VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_users_email ON users(LOWER(email));
+
+-- Password reset tokens are stored as SHA-256 hashes and can only be used once
+CREATE TABLE password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_tokens_username ON password_reset_tokens(username) WHERE used_at IS NULL;
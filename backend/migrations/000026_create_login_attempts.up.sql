@@ -0,0 +1,21 @@
+-- Consecutive failed logins per username, including unknown usernames.
+-- Cleared by a successful login.
+CREATE TABLE login_attempts (
+    username VARCHAR(255) PRIMARY KEY,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+
+-- Audit log of every lockout and who lifted it early
+CREATE TABLE account_lockouts (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    failed_count INTEGER NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    unlocked_by VARCHAR(255),
+    unlocked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_account_lockouts_username ON account_lockouts(username, created_at DESC);
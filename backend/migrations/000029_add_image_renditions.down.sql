@@ -0,0 +1,10 @@
+ALTER TABLE issues ADD COLUMN image_urls TEXT[] DEFAULT '{}';
+
+UPDATE issues SET image_urls = ARRAY(
+    SELECT image->>'original'
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS t(image, n)
+    ORDER BY n
+);
+
+ALTER TABLE issues DROP COLUMN images;
+ALTER TABLE issues RENAME COLUMN image_urls TO images;
@@ -0,0 +1,11 @@
+-- Issue photos are stored with their renditions: [{"original": url, "medium": url, "thumb": url}]
+-- Photos uploaded before have no smaller renditions, so all three point at the original
+ALTER TABLE issues ADD COLUMN image_renditions JSONB NOT NULL DEFAULT '[]';
+
+UPDATE issues SET image_renditions = (
+    SELECT COALESCE(jsonb_agg(jsonb_build_object('original', url, 'medium', url, 'thumb', url) ORDER BY n), '[]'::jsonb)
+    FROM unnest(images) WITH ORDINALITY AS t(url, n)
+);
+
+ALTER TABLE issues DROP COLUMN images;
+ALTER TABLE issues RENAME COLUMN image_renditions TO images;
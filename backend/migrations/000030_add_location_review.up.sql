@@ -0,0 +1,3 @@
+-- Why staff should check an issue's reported location, e.g. its photos were taken
+-- somewhere else; NULL when there is nothing to check
+ALTER TABLE issues ADD COLUMN location_review TEXT;
@@ -0,0 +1,31 @@
+-- Turn keys back into public URLs of the bucket set up by docker-compose; images
+-- stored elsewhere need their URLs fixing by hand
+
+-- Rewriting the references isn't an edit of the issue or comment
+ALTER TABLE issues DISABLE TRIGGER update_issues_updated_at;
+ALTER TABLE issue_comments DISABLE TRIGGER update_issue_comments_updated_at;
+
+UPDATE issues SET images = COALESCE((
+    SELECT jsonb_agg(image || jsonb_build_object(
+        'original', 'http://localhost:9000/issues-bucket/' || (image->>'original'),
+        'medium', 'http://localhost:9000/issues-bucket/' || (image->>'medium'),
+        'thumb', 'http://localhost:9000/issues-bucket/' || (image->>'thumb')
+    ) ORDER BY n)
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS t(image, n)
+), '[]'::jsonb);
+
+UPDATE issues SET after_images = ARRAY(
+    SELECT 'http://localhost:9000/issues-bucket/' || key
+    FROM unnest(after_images) WITH ORDINALITY AS t(key, n)
+    ORDER BY n
+);
+
+UPDATE issue_comments SET images = ARRAY(
+    SELECT 'http://localhost:9000/issues-bucket/' || key
+    FROM unnest(images) WITH ORDINALITY AS t(key, n)
+    ORDER BY n
+)
+WHERE images IS NOT NULL;
+
+ALTER TABLE issues ENABLE TRIGGER update_issues_updated_at;
+ALTER TABLE issue_comments ENABLE TRIGGER update_issue_comments_updated_at;
@@ -0,0 +1,32 @@
+-- Images are kept in a private bucket and served through short-lived presigned URLs,
+-- so only their object keys are stored. Keys never contain a slash, so the key of a
+-- stored URL is everything after the last one.
+
+-- Rewriting the references isn't an edit of the issue or comment
+ALTER TABLE issues DISABLE TRIGGER update_issues_updated_at;
+ALTER TABLE issue_comments DISABLE TRIGGER update_issue_comments_updated_at;
+
+UPDATE issues SET images = COALESCE((
+    SELECT jsonb_agg(image || jsonb_build_object(
+        'original', regexp_replace(image->>'original', '^.*/', ''),
+        'medium', regexp_replace(image->>'medium', '^.*/', ''),
+        'thumb', regexp_replace(image->>'thumb', '^.*/', '')
+    ) ORDER BY n)
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS t(image, n)
+), '[]'::jsonb);
+
+UPDATE issues SET after_images = ARRAY(
+    SELECT regexp_replace(url, '^.*/', '')
+    FROM unnest(after_images) WITH ORDINALITY AS t(url, n)
+    ORDER BY n
+);
+
+UPDATE issue_comments SET images = ARRAY(
+    SELECT regexp_replace(url, '^.*/', '')
+    FROM unnest(images) WITH ORDINALITY AS t(url, n)
+    ORDER BY n
+)
+WHERE images IS NOT NULL;
+
+ALTER TABLE issues ENABLE TRIGGER update_issues_updated_at;
+ALTER TABLE issue_comments ENABLE TRIGGER update_issue_comments_updated_at;
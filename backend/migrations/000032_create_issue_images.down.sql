@@ -0,0 +1,18 @@
+ALTER TABLE issues ADD COLUMN images JSONB NOT NULL DEFAULT '[]';
+
+-- Putting the photos back isn't an edit of the issue
+ALTER TABLE issues DISABLE TRIGGER update_issues_updated_at;
+
+UPDATE issues SET images = COALESCE((
+    SELECT jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+        'original', original_key,
+        'medium', medium_key,
+        'thumb', thumb_key,
+        'captured_at', captured_at
+    )) ORDER BY position)
+    FROM issue_images WHERE issue_images.issue_id = issues.id
+), '[]'::jsonb);
+
+ALTER TABLE issues ENABLE TRIGGER update_issues_updated_at;
+
+DROP TABLE IF EXISTS issue_images;
@@ -0,0 +1,34 @@
+-- Issue photos get a row each so they can be added, removed and reordered one at a
+-- time, and so their object keys can be found to delete them from the store
+CREATE TABLE issue_images (
+    id BIGSERIAL PRIMARY KEY,
+    issue_id BIGINT NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    original_key VARCHAR(255) NOT NULL,
+    medium_key VARCHAR(255) NOT NULL,
+    thumb_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    -- Size and SHA-256 checksum of the stored original; unknown for earlier photos
+    size_bytes BIGINT,
+    checksum CHAR(64),
+    captured_at TIMESTAMP WITH TIME ZONE,
+    uploaded_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Checked at commit so photos can swap places in a reorder
+    CONSTRAINT issue_images_position_key UNIQUE (issue_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Earlier photos were uploaded by the reporter along with the issue
+INSERT INTO issue_images (issue_id, position, original_key, medium_key, thumb_key,
+                          content_type, captured_at, uploaded_by, created_at)
+SELECT issues.id, t.n - 1, image->>'original', image->>'medium', image->>'thumb',
+       CASE lower(substring(image->>'original' FROM '\.([^.]*)$'))
+           WHEN 'png' THEN 'image/png'
+           WHEN 'gif' THEN 'image/gif'
+           WHEN 'webp' THEN 'image/webp'
+           ELSE 'image/jpeg'
+       END,
+       (image->>'captured_at')::timestamptz, issues.reported_by, issues.created_at
+FROM issues, jsonb_array_elements(issues.images) WITH ORDINALITY AS t(image, n);
+
+ALTER TABLE issues DROP COLUMN images;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_issue_reporters_reported_by;
@@ -0,0 +1,2 @@
+-- Speed up residents listing their reports, including those merged into other issues
+CREATE INDEX IF NOT EXISTS idx_issue_reporters_reported_by ON issue_reporters(reported_by);
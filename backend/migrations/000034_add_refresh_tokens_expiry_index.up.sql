@@ -0,0 +1,2 @@
+-- Expired refresh tokens are pruned whenever a new session starts
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
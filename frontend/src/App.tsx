@@ -22,6 +22,7 @@ const RegisterPage = lazy(() => import('./pages/RegisterPage'));
 const ReportIssuePage = lazy(() => import('./pages/ReportIssuePage'));
 const IssueDetailPage = lazy(() => import('./pages/IssueDetailPage'));
 const DashboardPage = lazy(() => import('./pages/DashboardPage'));
+const AccountSecurityPage = lazy(() => import('./pages/AccountSecurityPage'));
 const AboutPage = lazy(() => import('./pages/AboutPage'));
 const NotFoundPage = lazy(() => import('./pages/NotFoundPage'));
 
@@ -193,6 +194,15 @@ function App() {
                         }
                       />
 
+                      <Route
+                        path="/account/security"
+                        element={
+                          <ProtectedRoute>
+                            <AccountSecurityPage />
+                          </ProtectedRoute>
+                        }
+                      />
+
                       <Route path="/issues/:id" element={<IssueDetailPage />} />
 
                       {/* Staff-only routes */}
@@ -307,6 +307,9 @@ const Header = () => {
                       </Typography>
                     </Box>
                     <Divider />
+                    <MenuItem onClick={() => { handleCloseUserMenu(); navigate('/account/security'); }}>
+                      <Typography textAlign="center">Two-factor authentication</Typography>
+                    </MenuItem>
                     <MenuItem onClick={handleLogout}>
                       <Typography textAlign="center">Logout</Typography>
                     </MenuItem>
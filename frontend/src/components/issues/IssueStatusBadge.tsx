@@ -1,7 +1,7 @@
 // src/components/issues/IssueStatusBadge.tsx
 
 import React from 'react';
-import { IssueStatus } from '../../utils/constants';
+import { IssueStatus, ISSUE_STATUS_LABELS } from '../../utils/constants';
 
 interface IssueStatusBadgeProps {
   status: IssueStatus;
@@ -24,18 +24,7 @@ const IssueStatusBadge: React.FC<IssueStatusBadgeProps> = ({ status }) => {
     }
   };
 
-  const getDisplayText = (): string => {
-    switch (status) {
-      case 'NEW':
-        return 'New';
-      case 'IN_PROGRESS':
-        return 'In Progress';
-      case 'RESOLVED':
-        return 'Resolved';
-      default:
-        return status;
-    }
-  };
+  const getDisplayText = (): string => ISSUE_STATUS_LABELS[status] || status;
 
   return (
     <span className={`status-badge ${getStatusClassName()}`}>
@@ -1,8 +1,17 @@
 // src/contexts/AuthContext.tsx
 
 import React, { createContext, useState, useEffect, ReactNode } from 'react';
-import { authService, User, LoginCredentials, RegisterData, AuthResponse } from '../services/api';
-import { AxiosResponse } from 'axios';
+import {
+  authService,
+  storeSession,
+  clearSession,
+  User,
+  LoginCredentials,
+  RegisterData,
+  AuthResponse,
+  TwoFactorChallenge,
+  TwoFactorEnrollment,
+} from '../services/api';
 
 interface JwtPayload {
   user_id: number;
@@ -11,18 +20,32 @@ interface JwtPayload {
   [key: string]: any;
 }
 
+export interface TwoFactorResult {
+  user: User;
+  recoveryCodes?: string[];
+}
+
 interface AuthContextType {
   currentUser: User | null;
   isLoading: boolean;
   error: string | null;
-  login: (username: string | LoginCredentials, password?: string) => Promise<User>;
+  // Resolves to null when the login needs a second factor, see twoFactorChallenge
+  login: (username: string | LoginCredentials, password?: string) => Promise<User | null>;
+  twoFactorChallenge: TwoFactorChallenge | null;
+  verifyTwoFactor: (code: string, isRecoveryCode?: boolean) => Promise<TwoFactorResult>;
+  enrollTwoFactor: () => Promise<TwoFactorEnrollment>;
+  cancelTwoFactor: () => void;
   register: (userData: RegisterData) => Promise<any>;
-  logout: () => void;
+  logout: () => Promise<void>;
   isStaff: () => boolean;
 }
 
 export const AuthContext = createContext<AuthContextType>({} as AuthContextType);
 
+// Every role except resident belongs to council staff ('public' and 'staff' are pre-role tokens)
+const isStaffRole = (userType?: string): boolean =>
+  !!userType && userType !== 'resident' && userType !== 'public';
+
 // Helper function to decode JWT tokens
 const parseJwt = (token: string): JwtPayload | null => {
   try {
@@ -52,6 +75,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   const [currentUser, setCurrentUser] = useState<User | null>(null);
   const [isLoading, setIsLoading] = useState<boolean>(true);
   const [error, setError] = useState<string | null>(null);
+  const [twoFactorChallenge, setTwoFactorChallenge] = useState<TwoFactorChallenge | null>(null);
 
   useEffect(() => {
     // Check for stored token on initial load
@@ -69,8 +93,9 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
           const userData: User = {
             id: decodedToken.user_id,
             username: decodedToken.username || String(decodedToken.user_id) || 'User',
-            // Check if user_type is 'staff' to set is_staff to true
-            is_staff: decodedToken.user_type === 'staff'
+            // Any staff role (engineer, dispatcher, supervisor, admin) sets is_staff
+            is_staff: isStaffRole(decodedToken.user_type),
+            userType: decodedToken.user_type
           };
 
           console.log('Constructed user data from token:', userData);
@@ -78,15 +103,50 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
         }
       } catch (e) {
         console.error('Error processing token:', e);
-        sessionStorage.removeItem('token');
+        clearSession();
       }
     }
     setIsLoading(false);
   }, []);
 
-  const login = async (username: string | LoginCredentials, password?: string): Promise<User> => {
+  // Keep the tokens of a completed login and return the user they belong to
+  const completeLogin = (responseData: AuthResponse, usernameStr: string): User => {
+    // Store the access token and the refresh token that renews it
+    storeSession(responseData);
+
+    // Handle the case where user object is directly provided in the response (for testing)
+    if (responseData.user) {
+      console.log('Using user object from response:', responseData.user);
+      setCurrentUser(responseData.user);
+      return responseData.user;
+    }
+
+    // Extract user information from token
+    const decodedToken = parseJwt(responseData.token);
+    console.log('Decoded token after login:', decodedToken);
+
+    if (!decodedToken) {
+      throw new Error('Invalid token received');
+    }
+
+    // Create a user object based on the token payload
+    const userData: User = {
+      id: decodedToken.user_id,
+      username: decodedToken.username || String(decodedToken.user_id) || usernameStr,
+      // Any staff role (engineer, dispatcher, supervisor, admin) sets is_staff
+      is_staff: isStaffRole(decodedToken.user_type),
+      userType: decodedToken.user_type
+    };
+
+    console.log('Setting current user from token:', userData);
+    setCurrentUser(userData);
+    return userData;
+  };
+
+  const login = async (username: string | LoginCredentials, password?: string): Promise<User | null> => {
     setIsLoading(true);
     setError(null);
+    setTwoFactorChallenge(null);
     try {
       console.log('Attempting login for:', username);
 
@@ -103,64 +163,77 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
 
       // Handle both API response formats (direct response or response.data)
       // Make sure we can handle the response in multiple formats to be flexible for testing
-      let responseData: AuthResponse | undefined;
-      let token: string | undefined;
+      const responseData = (response && 'data' in response ? response.data : response) as
+        AuthResponse | TwoFactorChallenge | undefined;
 
-      // First try to access token via response.data.token (normal API format)
-      if (response && 'data' in response && response.data && 'token' in response.data) {
-        responseData = response.data as AuthResponse;
-        token = responseData.token;
-      }
-      // Then try direct access (for mocks that return { token, user })
-      else if (response && 'token' in response) {
-        responseData = response as unknown as AuthResponse;
-        token = responseData.token;
+      // Staff, and residents who turned it on, must also enter a code from their authenticator app
+      if (responseData && 'two_factor_required' in responseData && responseData.two_factor_required) {
+        setTwoFactorChallenge(responseData);
+        return null;
       }
 
-      if (!token) {
+      if (!responseData || !('token' in responseData) || !responseData.token) {
         console.error('No token in response:', response);
         throw new Error('No token received from server');
       }
 
-      // Store token
-      sessionStorage.setItem('token', token);
-
-      // Handle the case where user object is directly provided in the response (for testing)
-      if (responseData && responseData.user) {
-        console.log('Using user object from response:', responseData.user);
-        setCurrentUser(responseData.user);
-        return responseData.user;
-      }
-      
-      // Extract user information from token
-      const decodedToken = parseJwt(token);
-      console.log('Decoded token after login:', decodedToken);
-
-      if (decodedToken) {
-        // Create a user object based on the token payload
-        const usernameStr = typeof username === 'string' ? username : username.username;
-        const userData: User = {
-          id: decodedToken.user_id,
-          username: decodedToken.username || String(decodedToken.user_id) || usernameStr,
-          // Check if user_type is 'staff' to set is_staff to true
-          is_staff: decodedToken.user_type === 'staff'
-        };
-
-        console.log('Setting current user from token:', userData);
-        setCurrentUser(userData);
-        return userData;
-      } else {
-        throw new Error('Invalid token received');
-      }
+      return completeLogin(responseData, loginParams.username);
     } catch (err: any) {
       console.error('Login error:', err);
-      setError(err.response?.data?.message || 'Failed to login');
+      setError(err.response?.data?.error || err.response?.data?.message || 'Failed to login');
+      throw err;
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  // Finish a login that answered with a two-factor challenge, with a code from the
+  // authenticator app or one of the recovery codes. Recovery codes are returned once,
+  // when the code confirms a new enrolment.
+  const verifyTwoFactor = async (code: string, isRecoveryCode = false): Promise<TwoFactorResult> => {
+    if (!twoFactorChallenge) {
+      throw new Error('Log in with your password first');
+    }
+    setIsLoading(true);
+    setError(null);
+    try {
+      const response = await authService.verifyTwoFactor({
+        challenge_token: twoFactorChallenge.challenge_token,
+        ...(isRecoveryCode ? { recovery_code: code } : { code }),
+      });
+      const user = completeLogin(response.data, '');
+      setTwoFactorChallenge(null);
+      return { user, recoveryCodes: response.data.recovery_codes };
+    } catch (err: any) {
+      console.error('Two-factor error:', err);
+      setError(err.response?.data?.error || 'Failed to verify code');
       throw err;
     } finally {
       setIsLoading(false);
     }
   };
 
+  // Get a new authenticator app secret for staff who must enrol before logging in
+  const enrollTwoFactor = async (): Promise<TwoFactorEnrollment> => {
+    if (!twoFactorChallenge) {
+      throw new Error('Log in with your password first');
+    }
+    setError(null);
+    try {
+      const response = await authService.enrollTwoFactor(twoFactorChallenge.challenge_token);
+      return response.data;
+    } catch (err: any) {
+      console.error('Two-factor enrolment error:', err);
+      setError(err.response?.data?.error || 'Failed to start two-factor enrolment');
+      throw err;
+    }
+  };
+
+  const cancelTwoFactor = (): void => {
+    setTwoFactorChallenge(null);
+    setError(null);
+  };
+
   const register = async (userData: RegisterData): Promise<any> => {
     setIsLoading(true);
     setError(null);
@@ -177,9 +250,11 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
     }
   };
 
-  const logout = () => {
+  const logout = async () => {
     console.log('Logging out user:', currentUser?.username);
-    sessionStorage.removeItem('token');
+    // Revoke the session on the server while the token is still here to send
+    await authService.logout();
+    clearSession();
     setCurrentUser(null);
   };
 
@@ -192,6 +267,10 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
     isLoading,
     error,
     login,
+    twoFactorChallenge,
+    verifyTwoFactor,
+    enrollTwoFactor,
+    cancelTwoFactor,
     register,
     logout,
     isStaff,
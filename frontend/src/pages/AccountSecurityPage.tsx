@@ -0,0 +1,168 @@
+// src/pages/AccountSecurityPage.tsx
+
+import React, { useContext, useState } from 'react';
+import { AuthContext } from '../contexts/AuthContext';
+import { accountService, TwoFactorEnrollment } from '../services/api';
+import {
+  Alert,
+  Box,
+  Button,
+  Container,
+  Paper,
+  TextField,
+  Typography,
+} from '@mui/material';
+
+// Two-factor authentication settings for the logged in user. Staff must keep it on;
+// residents can turn it on and off.
+const AccountSecurityPage = () => {
+  const { isStaff } = useContext(AuthContext);
+
+  const [enrollment, setEnrollment] = useState<TwoFactorEnrollment | null>(null);
+  const [alreadyEnabled, setAlreadyEnabled] = useState(false);
+  const [code, setCode] = useState('');
+  const [password, setPassword] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null);
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const handleStart = async (): Promise<void> => {
+    setError('');
+    setMessage('');
+    setLoading(true);
+    try {
+      const response = await accountService.startTwoFactorEnrollment();
+      setEnrollment(response.data);
+    } catch (err: any) {
+      if (err.response?.status === 409) {
+        setAlreadyEnabled(true);
+      } else {
+        setError(err.response?.data?.error || 'Failed to start setting up your authenticator app');
+      }
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleConfirm = async (e: React.FormEvent<HTMLFormElement>): Promise<void> => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+    try {
+      const response = await accountService.confirmTwoFactorEnrollment(code.trim());
+      setRecoveryCodes(response.data.recovery_codes);
+      setEnrollment(null);
+      setCode('');
+      setAlreadyEnabled(true);
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Invalid code');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleDisable = async (e: React.FormEvent<HTMLFormElement>): Promise<void> => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+    try {
+      await accountService.disableTwoFactor(password);
+      setPassword('');
+      setAlreadyEnabled(false);
+      setRecoveryCodes(null);
+      setMessage('Two-factor authentication is now off');
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to turn off two-factor authentication');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <Container maxWidth="sm" sx={{ py: 6 }}>
+      <Paper sx={{ p: 4 }}>
+        <Typography variant="h4" component="h1" gutterBottom>
+          Two-Factor Authentication
+        </Typography>
+        <Typography color="text.secondary" sx={{ mb: 3 }}>
+          Protect your account with a code from an authenticator app whenever you log in.
+        </Typography>
+
+        {error && <Alert severity="error" sx={{ mb: 2 }}>{error}</Alert>}
+        {message && <Alert severity="success" sx={{ mb: 2 }}>{message}</Alert>}
+
+        {recoveryCodes && (
+          <Box sx={{ mb: 3 }}>
+            <Alert severity="warning" sx={{ mb: 2 }}>
+              Save these recovery codes somewhere safe. Each one lets you log in once if you
+              lose your authenticator app, and they won&apos;t be shown again.
+            </Alert>
+            <Box component="ul" className="recovery-codes">
+              {recoveryCodes.map((recoveryCode) => (
+                <li key={recoveryCode}><code>{recoveryCode}</code></li>
+              ))}
+            </Box>
+          </Box>
+        )}
+
+        {!enrollment && !alreadyEnabled && (
+          <Button variant="contained" onClick={handleStart} disabled={loading}>
+            Set up authenticator app
+          </Button>
+        )}
+
+        {enrollment && (
+          <Box component="form" onSubmit={handleConfirm}>
+            <Typography sx={{ mb: 1 }}>
+              Add this account to your authenticator app by opening the setup link on your
+              phone, or by entering the key by hand. Then enter the code it shows.
+            </Typography>
+            <Typography sx={{ mb: 1 }}>
+              <a href={enrollment.provisioning_uri}>Open in authenticator app</a>
+            </Typography>
+            <Typography sx={{ mb: 2 }}>
+              Key: <code>{enrollment.secret}</code>
+            </Typography>
+            <TextField
+              label="Authentication code"
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
+              inputProps={{ inputMode: 'numeric', autoComplete: 'one-time-code' }}
+              required
+              fullWidth
+              sx={{ mb: 2 }}
+            />
+            <Button type="submit" variant="contained" disabled={loading || !code.trim()}>
+              Turn on
+            </Button>
+          </Box>
+        )}
+
+        {alreadyEnabled && !recoveryCodes && (
+          <Typography sx={{ mb: 2 }}>Two-factor authentication is on for your account.</Typography>
+        )}
+
+        {/* Staff accounts can't turn it off; an administrator can reset a lost device */}
+        {alreadyEnabled && !isStaff() && (
+          <Box component="form" onSubmit={handleDisable} sx={{ mt: 2 }}>
+            <TextField
+              label="Current password"
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              required
+              fullWidth
+              sx={{ mb: 2 }}
+            />
+            <Button type="submit" color="error" variant="outlined" disabled={loading || !password}>
+              Turn off
+            </Button>
+          </Box>
+        )}
+      </Paper>
+    </Container>
+  );
+};
+
+export default AccountSecurityPage;
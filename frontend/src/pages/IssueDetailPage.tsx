@@ -6,6 +6,7 @@ import { useParams, useNavigate } from 'react-router-dom';
 import { issuesService, engineersService, Issue, Engineer } from '../services/api';
 import { AuthContext } from '../contexts/AuthContext';
 import IssueStatusBadge from '../components/issues/IssueStatusBadge';
+import { ISSUE_STATUS_LABELS } from '../utils/constants';
 import { MapContainer, TileLayer, Marker } from 'react-leaflet';
 import { SelectChangeEvent } from '@mui/material';
 import 'leaflet/dist/leaflet.css';
@@ -142,17 +143,33 @@ const IssueDetailPage: React.FC = () => {
       const issueId = parseInt(id, 10);
       await issuesService.updateIssue(issueId, updateData);
 
-      // Refresh issue data
+      // Refresh issue data; assigning an engineer can move the issue on too
       const response = await issuesService.getIssueById(issueId);
       setIssue(response.data);
+      setUpdateData({
+        status: response.data.status,
+        assigned_to: response.data.assigned_to || '',
+      });
     } catch (err: any) {
-      const errorMessage = err?.response?.data?.message || 'Failed to update issue';
+      const errorMessage = err?.response?.data?.error || err?.response?.data?.message || 'Failed to update issue';
       setUpdateError(errorMessage);
     } finally {
       setUpdating(false);
     }
   };
 
+  // Staff clear the location review once they have checked where the issue is
+  const handleResolveLocationReview = async (): Promise<void> => {
+    if (!issue) return;
+    setUpdateError(null);
+    try {
+      await issuesService.resolveLocationReview(issue.id);
+      setIssue({ ...issue, location_review: undefined });
+    } catch (err: any) {
+      setUpdateError(err?.response?.data?.error || 'Failed to resolve location review');
+    }
+  };
+
   const handleImageError = (index: number): void => {
     setImageError(prev => ({ ...prev, [index]: true }));
     console.error(`Failed to load image at index ${index}`);
@@ -293,7 +310,7 @@ const IssueDetailPage: React.FC = () => {
                 {!imageError[activeImageIndex] ? (
                   <Box 
                     component="img"
-                    src={issue.images[activeImageIndex]}
+                    src={issue.images[activeImageIndex].original}
                     alt={`Issue ${activeImageIndex + 1} fullscreen`}
                     sx={{
                       maxWidth: '90%',
@@ -312,7 +329,7 @@ const IssueDetailPage: React.FC = () => {
                     maxWidth: '80%'
                   }}>
                     <Typography variant="h6">Failed to load image</Typography>
-                    <Typography variant="caption" display="block">{issue.images[activeImageIndex]}</Typography>
+                    <Typography variant="caption" display="block">{issue.images[activeImageIndex].original}</Typography>
                   </Box>
                 )}
                 <IconButton 
@@ -494,9 +511,12 @@ const IssueDetailPage: React.FC = () => {
                               label="Status"
                               required
                             >
-                              <MenuItem value="NEW">New</MenuItem>
-                              <MenuItem value="IN_PROGRESS">In Progress</MenuItem>
-                              <MenuItem value="RESOLVED">Resolved</MenuItem>
+                              {/* The current status and the ones the workflow allows next */}
+                              {[issue.status, ...(issue.allowed_transitions || [])].map(status => (
+                                <MenuItem key={status} value={status}>
+                                  {ISSUE_STATUS_LABELS[status] || status}
+                                </MenuItem>
+                              ))}
                             </Select>
                           </FormControl>
                         </Grid>
@@ -550,7 +570,21 @@ const IssueDetailPage: React.FC = () => {
                   <LocationOnIcon sx={{ mr: 1 }} />
                   Location
                 </Typography>
-                
+
+                {issue.location_review && (
+                  <Alert
+                    severity="warning"
+                    sx={{ mt: 2 }}
+                    action={isStaff() && (
+                      <Button color="inherit" size="small" onClick={handleResolveLocationReview}>
+                        Location checked
+                      </Button>
+                    )}
+                  >
+                    {issue.location_review}
+                  </Alert>
+                )}
+
                 <Box sx={{ mt: 2, borderRadius: 1, overflow: 'hidden' }}>
                   {issue.location && issue.location.latitude && issue.location.longitude ? (
                     <MapContainer
@@ -622,7 +656,7 @@ const IssueDetailPage: React.FC = () => {
                       {!imageError[activeImageIndex] ? (
                         <Box
                           component="img"
-                          src={issue.images[activeImageIndex]}
+                          src={issue.images[activeImageIndex].medium}
                           alt={`Issue ${activeImageIndex + 1}`}
                           sx={{
                             maxWidth: '100%',
@@ -637,7 +671,7 @@ const IssueDetailPage: React.FC = () => {
                         <Alert severity="error" sx={{ m: 2 }}>
                           <Typography variant="body1">Failed to load image</Typography>
                           <Typography variant="caption" display="block">
-                            {issue.images[activeImageIndex]}
+                            {issue.images[activeImageIndex].medium}
                           </Typography>
                         </Alert>
                       )}
@@ -707,7 +741,7 @@ const IssueDetailPage: React.FC = () => {
                             {!imageError[idx] ? (
                               <Box
                                 component="img"
-                                src={image}
+                                src={image.thumb}
                                 alt={`Thumbnail ${idx + 1}`}
                                 sx={{
                                   width: '100%',
@@ -3,9 +3,17 @@
 import React, { useState, useContext } from 'react';
 import { Link, useNavigate, useLocation } from 'react-router-dom';
 import { AuthContext } from '../contexts/AuthContext';
+import { TwoFactorEnrollment } from '../services/api';
 
 const LoginPage = () => {
-  const { login, error: authError } = useContext(AuthContext);
+  const {
+    login,
+    error: authError,
+    twoFactorChallenge,
+    verifyTwoFactor,
+    enrollTwoFactor,
+    cancelTwoFactor,
+  } = useContext(AuthContext);
   const navigate = useNavigate();
   const location = useLocation();
 
@@ -20,6 +28,12 @@ const LoginPage = () => {
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
 
+  // Second step for accounts using two-factor authentication
+  const [code, setCode] = useState('');
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+  const [enrollment, setEnrollment] = useState<TwoFactorEnrollment | null>(null);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null);
+
   const handleChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
     const { name, value } = e.target;
     setFormData({ ...formData, [name]: value });
@@ -38,15 +52,182 @@ const LoginPage = () => {
     setLoading(true);
 
     try {
-      await login(formData.username, formData.password);
-      navigate(from, { replace: true });
+      const user = await login(formData.username, formData.password);
+      // No user yet means a code from the authenticator app is needed first
+      if (user) {
+        navigate(from, { replace: true });
+      }
+    } catch (err: any) {
+      setError(err.response?.data?.error || err.response?.data?.message || 'Failed to login. Please check your credentials.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleStartEnrollment = async (): Promise<void> => {
+    setError('');
+    setLoading(true);
+    try {
+      setEnrollment(await enrollTwoFactor());
+    } catch (err: any) {
+      setError(err.response?.data?.error || 'Failed to start setting up your authenticator app.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleVerify = async (e: React.FormEvent<HTMLFormElement>): Promise<void> => {
+    e.preventDefault();
+
+    if (!code.trim()) {
+      setError('Enter the code to continue');
+      return;
+    }
+
+    setError('');
+    setLoading(true);
+
+    try {
+      const result = await verifyTwoFactor(code.trim(), useRecoveryCode);
+      setCode('');
+      setEnrollment(null);
+      // Recovery codes are only shown once, so keep them on screen until saved
+      if (result.recoveryCodes && result.recoveryCodes.length > 0) {
+        setRecoveryCodes(result.recoveryCodes);
+      } else {
+        navigate(from, { replace: true });
+      }
     } catch (err: any) {
-      setError(err.response?.data?.message || 'Failed to login. Please check your credentials.');
+      setError(err.response?.data?.error || 'Invalid code. Please try again.');
     } finally {
       setLoading(false);
     }
   };
 
+  const handleCancel = (): void => {
+    cancelTwoFactor();
+    setCode('');
+    setUseRecoveryCode(false);
+    setEnrollment(null);
+    setError('');
+  };
+
+  if (recoveryCodes) {
+    return (
+      <div className="auth-container">
+        <div className="auth-form-container">
+          <h1>Save Your Recovery Codes</h1>
+          <p>
+            Each code lets you log in once if you lose access to your authenticator app.
+            They won&apos;t be shown again, so store them somewhere safe.
+          </p>
+          <ul className="recovery-codes">
+            {recoveryCodes.map((recoveryCode) => (
+              <li key={recoveryCode}><code>{recoveryCode}</code></li>
+            ))}
+          </ul>
+          <button
+            type="button"
+            className="auth-button"
+            onClick={() => navigate(from, { replace: true })}
+          >
+            I have saved these codes
+          </button>
+        </div>
+      </div>
+    );
+  }
+
+  if (twoFactorChallenge) {
+    const mustEnroll = twoFactorChallenge.enrollment_required;
+    return (
+      <div className="auth-container">
+        <div className="auth-form-container">
+          <h1>Two-Factor Authentication</h1>
+
+          {(error || authError) && (
+            <div className="error-message">{error || authError}</div>
+          )}
+
+          {mustEnroll && !enrollment ? (
+            <>
+              <p>
+                Staff accounts need an authenticator app. Set one up to finish logging in.
+              </p>
+              <button
+                type="button"
+                className="auth-button"
+                onClick={handleStartEnrollment}
+                disabled={loading}
+              >
+                {loading ? 'Preparing...' : 'Set up authenticator app'}
+              </button>
+            </>
+          ) : (
+            <form onSubmit={handleVerify}>
+              {enrollment && (
+                <div className="form-group">
+                  <p>
+                    Add this account to your authenticator app by opening the setup link on
+                    your phone, or by entering the key by hand. Then enter the code it shows.
+                  </p>
+                  <p>
+                    <a href={enrollment.provisioning_uri} className="auth-link">Open in authenticator app</a>
+                  </p>
+                  <p>Key: <code>{enrollment.secret}</code></p>
+                </div>
+              )}
+
+              <div className="form-group">
+                <label htmlFor="code">{useRecoveryCode ? 'Recovery code' : 'Authentication code'}</label>
+                <input
+                  type="text"
+                  id="code"
+                  name="code"
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  autoComplete="one-time-code"
+                  inputMode={useRecoveryCode ? 'text' : 'numeric'}
+                  autoFocus
+                  required
+                />
+              </div>
+
+              <button
+                type="submit"
+                className="auth-button"
+                disabled={loading}
+              >
+                {loading ? 'Verifying...' : 'Verify'}
+              </button>
+            </form>
+          )}
+
+          <div className="auth-footer">
+            {!enrollment && !mustEnroll && (
+              <>
+                <button
+                  type="button"
+                  className="auth-link"
+                  onClick={() => {
+                    setUseRecoveryCode(!useRecoveryCode);
+                    setCode('');
+                  }}
+                >
+                  {useRecoveryCode ? 'Use your authenticator app' : 'Use a recovery code'}
+                </button>
+                {' · '}
+              </>
+            )}
+            <button type="button" className="auth-link" onClick={handleCancel}>
+              Back to login
+            </button>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className="auth-container">
       <div className="auth-form-container">
@@ -12,7 +12,8 @@ const RegisterPage = () => {
     password: '',
     confirmPassword: '',
     is_staff: false,
-    staff_secret: '',
+    email: '',
+    invite_code: '',
   });
 
   const [error, setError] = useState('');
@@ -45,10 +46,10 @@ const RegisterPage = () => {
       return;
     }
 
-    // Only validate that the staff secret is provided if user is registering as staff
-    // The actual validation will happen on the server side
-    if (formData.is_staff && !formData.staff_secret) {
-      setError('Staff secret phrase is required');
+    // Staff accounts are created by redeeming an invite sent to the staff member's email
+    // The invite itself is checked on the server side
+    if (formData.is_staff && (!formData.email.trim() || !formData.invite_code.trim())) {
+      setError('Email and invite code are required');
       return;
     }
 
@@ -60,9 +61,9 @@ const RegisterPage = () => {
       await authService.register({
         username: formData.username,
         password: formData.password,
-        is_staff: formData.is_staff,
-        // Send the staff secret to the backend for server-side validation
-        staff_secret: formData.is_staff ? formData.staff_secret : undefined
+        // Send the invite to the backend for server-side validation
+        email: formData.is_staff ? formData.email.trim() : undefined,
+        invite_code: formData.is_staff ? formData.invite_code.trim() : undefined
       });
 
       // Navigate to login page after successful registration
@@ -72,7 +73,7 @@ const RegisterPage = () => {
         }
       });
     } catch (err: any) {
-      setError(err.response?.data?.message || 'Failed to register. Please try again.');
+      setError(err.response?.data?.error || err.response?.data?.message || 'Failed to register. Please try again.');
     } finally {
       setLoading(false);
     }
@@ -131,26 +132,41 @@ const RegisterPage = () => {
                 checked={formData.is_staff}
                 onChange={handleChange}
               />
-              Register as council staff member
+              I have a council staff invite
             </label>
           </div>
 
           {formData.is_staff && (
-            <div className="form-group">
-              <label htmlFor="staff_secret">Staff Secret Phrase</label>
-              <input
-                type="password"
-                id="staff_secret"
-                name="staff_secret"
-                value={formData.staff_secret}
-                onChange={handleChange}
-                required={formData.is_staff}
-                placeholder="Enter the staff secret phrase"
-              />
-              <small className="form-text text-muted">
-                A secret phrase is required to register as staff. This helps ensure only authorized personnel can access staff features.
-              </small>
-            </div>
+            <>
+              <div className="form-group">
+                <label htmlFor="email">Work Email</label>
+                <input
+                  type="email"
+                  id="email"
+                  name="email"
+                  value={formData.email}
+                  onChange={handleChange}
+                  required={formData.is_staff}
+                  placeholder="The email address your invite was sent to"
+                />
+              </div>
+
+              <div className="form-group">
+                <label htmlFor="invite_code">Invite Code</label>
+                <input
+                  type="text"
+                  id="invite_code"
+                  name="invite_code"
+                  value={formData.invite_code}
+                  onChange={handleChange}
+                  required={formData.is_staff}
+                  placeholder="Enter your invite code"
+                />
+                <small className="form-text text-muted">
+                  Staff accounts need a single-use invite from a council administrator. Invite codes expire, so ask for a new one if yours no longer works.
+                </small>
+              </div>
+            </>
           )}
 
           <button
@@ -21,10 +21,31 @@ export const authService = {
     });
   }),
   register: jest.fn().mockResolvedValue({ success: true }),
-  logout: jest.fn(),
+  logout: jest.fn().mockResolvedValue(undefined),
+  verifyTwoFactor: jest.fn().mockResolvedValue({ data: { token: 'mock-jwt-token-for-testing', refresh_token: 'mock-refresh-token' } }),
+  enrollTwoFactor: jest.fn().mockResolvedValue({ data: { secret: 'JBSWY3DPEHPK3PXP', provisioning_uri: 'otpauth://totp/Chalkstone:testuser?secret=JBSWY3DPEHPK3PXP' } }),
   verifyToken: jest.fn().mockResolvedValue({ isValid: true }),
 };
 
+// Mock account services
+export const accountService = {
+  startTwoFactorEnrollment: jest.fn().mockResolvedValue({ data: { secret: 'JBSWY3DPEHPK3PXP', provisioning_uri: 'otpauth://totp/Chalkstone:testuser?secret=JBSWY3DPEHPK3PXP' } }),
+  confirmTwoFactorEnrollment: jest.fn().mockResolvedValue({ data: { recovery_codes: ['aaaa-bbbb', 'cccc-dddd'] } }),
+  disableTwoFactor: jest.fn().mockResolvedValue({ data: { message: 'Two-factor authentication disabled' } }),
+};
+
+export const storeSession = jest.fn((data) => {
+  sessionStorage.setItem('token', data.token);
+  if (data.refresh_token) {
+    sessionStorage.setItem('refresh_token', data.refresh_token);
+  }
+});
+
+export const clearSession = jest.fn(() => {
+  sessionStorage.removeItem('token');
+  sessionStorage.removeItem('refresh_token');
+});
+
 // Mock analytics service
 export const analyticsService = {
   getIssueTypeBreakdown: jest.fn().mockResolvedValue([
@@ -79,7 +100,8 @@ export const issuesService = {
       location: { latitude: 51.5074, longitude: -0.1278 },
       reportedBy: 'citizen1',
       createdAt: '2023-01-01T12:00:00Z',
-      images: ['test-image.jpg']
+      images: [{ original: 'test-image.jpg', medium: 'test-image.jpg', thumb: 'test-image.jpg' }],
+      allowed_transitions: ['TRIAGED', 'REJECTED', 'DUPLICATE']
     })
   ),
   createIssue: jest.fn().mockImplementation((issueData) => 
@@ -97,6 +119,7 @@ export const issuesService = {
       updatedAt: '2023-01-01T12:00:00Z',
     })
   ),
+  resolveLocationReview: jest.fn().mockResolvedValue({ data: { message: 'Location review resolved' } }),
   deleteIssue: jest.fn().mockResolvedValue({ success: true }),
 };
 
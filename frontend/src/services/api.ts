@@ -1,6 +1,6 @@
 // src/services/api.ts
 
-import axios, { AxiosResponse, InternalAxiosRequestConfig } from 'axios';
+import axios, { AxiosInstance, AxiosResponse, InternalAxiosRequestConfig } from 'axios';
 import { IssueType, IssueStatus } from '../utils/constants';
 
 const API_URL = import.meta.env.VITE_API_URL;
@@ -16,7 +16,33 @@ export interface User {
 
 export interface AuthResponse {
   token: string;
-  user: User;
+  // Single-use token for getting a new access token once this one expires
+  refresh_token?: string;
+  expires_at?: string;
+  user?: User;
+  // Only shown once, after enrolling in two-factor authentication
+  recovery_codes?: string[];
+}
+
+// Returned by login instead of tokens when a second factor is needed. Staff who
+// haven't set up an authenticator app yet must enrol before they can continue.
+export interface TwoFactorChallenge {
+  two_factor_required: true;
+  enrollment_required: boolean;
+  challenge_token: string;
+  expires_at: string;
+}
+
+export interface TwoFactorVerification {
+  challenge_token: string;
+  code?: string;
+  recovery_code?: string;
+}
+
+// A new authenticator app secret; provisioning_uri is the otpauth:// URI to scan
+export interface TwoFactorEnrollment {
+  secret: string;
+  provisioning_uri: string;
 }
 
 export interface LoginCredentials {
@@ -28,8 +54,7 @@ export interface RegisterData {
   username: string;
   password: string;
   email?: string;
-  is_staff?: boolean;
-  staff_secret?: string;
+  invite_code?: string;
 }
 
 export interface Location {
@@ -47,7 +72,23 @@ export interface Issue {
   assigned_to: string | null;
   created_at: string;
   updated_at: string;
-  images: string[];
+  images: IssueImage[];
+  location_review?: string;
+  // Statuses the issue may move to next
+  allowed_transitions?: IssueStatus[];
+}
+
+// A photo of an issue in the sizes the API serves it at
+export interface IssueImage {
+  id?: number;
+  original: string;
+  medium: string;
+  thumb: string;
+  captured_at?: string;
+  content_type?: string;
+  size?: number;
+  checksum?: string;
+  uploaded_by?: string;
 }
 
 export interface IssueData {
@@ -116,8 +157,42 @@ apiFormData.interceptors.request.use(
     }
 );
 
-// Response interceptor for handling errors (shared logic)
-const handleResponseError = (error: any): Promise<never> => {
+// Keep the tokens of the current session for this browser tab
+export const storeSession = (data: AuthResponse): void => {
+  sessionStorage.setItem('token', data.token);
+  if (data.refresh_token) {
+    sessionStorage.setItem('refresh_token', data.refresh_token);
+  }
+};
+
+export const clearSession = (): void => {
+  sessionStorage.removeItem('token');
+  sessionStorage.removeItem('refresh_token');
+};
+
+// Refresh tokens are single use, so requests failing together share one refresh
+let refreshPromise: Promise<string> | null = null;
+
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshPromise) {
+    const refreshToken = sessionStorage.getItem('refresh_token');
+    refreshPromise = (refreshToken
+      ? axios.post<AuthResponse>(`${API_URL}/auth/refresh`, { refresh_token: refreshToken })
+          .then((response) => {
+            storeSession(response.data);
+            return response.data.token;
+          })
+      : Promise.reject(new Error('No refresh token'))
+    ).finally(() => {
+      refreshPromise = null;
+    });
+  }
+  return refreshPromise;
+};
+
+// Response interceptor for handling errors (shared logic). An expired access token is
+// refreshed and the request retried once before the user is sent back to log in.
+const handleResponseError = (instance: AxiosInstance) => async (error: any): Promise<AxiosResponse> => {
   // Log less information in production
   if (import.meta.env.DEV) {
     console.error('API Error:', error.response || error);
@@ -125,26 +200,39 @@ const handleResponseError = (error: any): Promise<never> => {
     console.error(`API Error on ${error.config?.url}: ${error.message}`);
   }
 
-  if (error.response && error.response.status === 401) {
+  const config = error.config;
+  // Wrong passwords and codes are shown by the login form, which must keep its state
+  const authRequest = config?.url?.startsWith('/auth/');
+  if (error.response && error.response.status === 401 && !authRequest) {
+    if (config && !config._retried) {
+      config._retried = true;
+      try {
+        const token = await refreshAccessToken();
+        config.headers['Authorization'] = `Bearer ${token}`;
+        return instance(config);
+      } catch (refreshError) {
+        console.log('Session could not be refreshed');
+      }
+    }
+
     // Handle unauthorized error (e.g., redirect to login)
     console.log('401 Unauthorized - clearing auth data');
-    sessionStorage.removeItem('token'); // Use sessionStorage
-    // localStorage.removeItem('user'); // No longer storing user object separately
+    clearSession();
     window.location.href = '/login';
   }
   return Promise.reject(error);
 };
 
 // Add response interceptors to both instances
-api.interceptors.response.use(response => response, handleResponseError);
-apiFormData.interceptors.response.use(response => response, handleResponseError);
+api.interceptors.response.use(response => response, handleResponseError(api));
+apiFormData.interceptors.response.use(response => response, handleResponseError(apiFormData));
 
 // Auth services
 export const authService = {
-  login: async (credentials: LoginCredentials): Promise<AxiosResponse<AuthResponse>> => {
+  login: async (credentials: LoginCredentials): Promise<AxiosResponse<AuthResponse | TwoFactorChallenge>> => {
     console.log('Login request with:', credentials.username);
     try {
-      const response = await api.post<AuthResponse>('/auth/login', credentials);
+      const response = await api.post<AuthResponse | TwoFactorChallenge>('/auth/login', credentials);
       console.log('Login response:', response.data);
       return response;
     } catch (error) {
@@ -163,6 +251,22 @@ export const authService = {
       throw error;
     }
   },
+  // Second step of a login that answered with a challenge
+  verifyTwoFactor: (verification: TwoFactorVerification): Promise<AxiosResponse<AuthResponse>> =>
+      api.post<AuthResponse>('/auth/2fa', verification),
+  // Start enrolment for staff whose login requires an authenticator app they haven't set up
+  enrollTwoFactor: (challengeToken: string): Promise<AxiosResponse<TwoFactorEnrollment>> =>
+      api.post<TwoFactorEnrollment>('/auth/2fa/enroll', { challenge_token: challengeToken }),
+  // Revoke the session's tokens on the server. Logging out locally must not depend on
+  // it, so failures are only logged.
+  logout: async (): Promise<void> => {
+    const refreshToken = sessionStorage.getItem('refresh_token');
+    try {
+      await api.post('/auth/logout', refreshToken ? { refresh_token: refreshToken } : {});
+    } catch (error) {
+      console.error('Logout error in service:', error);
+    }
+  },
   // For testing/debugging - simulate a login
   mockLogin: (credentials: LoginCredentials): Promise<{ data: AuthResponse }> => {
     console.log('MOCK LOGIN:', credentials);
@@ -179,10 +283,23 @@ export const authService = {
   },
 };
 
+// Account services for the logged in user
+export const accountService = {
+  startTwoFactorEnrollment: (): Promise<AxiosResponse<TwoFactorEnrollment>> =>
+      api.post<TwoFactorEnrollment>('/me/2fa'),
+
+  confirmTwoFactorEnrollment: (code: string): Promise<AxiosResponse<{ recovery_codes: string[] }>> =>
+      api.post<{ recovery_codes: string[] }>('/me/2fa/confirm', { code }),
+
+  disableTwoFactor: (password: string): Promise<AxiosResponse<{ message: string }>> =>
+      api.delete<{ message: string }>('/me/2fa', { data: { password } }),
+};
+
 // Issue services
 export const issuesService = {
-  getAllIssues: (page = 1, pageSize = 10): Promise<AxiosResponse<Issue[]>> =>
-      api.get<Issue[]>(`/issues?page=${page}&pageSize=${pageSize}`),
+  // locationReview lists only the issues whose reported location staff should check
+  getAllIssues: (page = 1, pageSize = 10, locationReview = false): Promise<AxiosResponse<Issue[]>> =>
+      api.get<Issue[]>(`/issues?page=${page}&pageSize=${pageSize}${locationReview ? '&location_review=true' : ''}`),
 
   getIssueById: (id: number): Promise<AxiosResponse<Issue>> => {
     if (!id) {
@@ -206,6 +323,9 @@ export const issuesService = {
   updateIssue: (id: number, issueData: Partial<IssueData>): Promise<AxiosResponse<{ message: string }>> =>
       api.put<{ message: string }>(`/issues/${id}`, issueData),
 
+  resolveLocationReview: (id: number): Promise<AxiosResponse<{ message: string }>> =>
+      api.delete<{ message: string }>(`/issues/${id}/location-review`),
+
   searchIssues: (type?: string, status?: string): Promise<AxiosResponse<Issue[]>> =>
       api.get<Issue[]>(`/issues/search?type=${type || ''}&status=${status || ''}`),
 
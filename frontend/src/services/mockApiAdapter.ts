@@ -21,7 +21,7 @@ interface MockIssue {
   assigned_to: string | null;
   created_at: string;
   updated_at: string;
-  images: string[];
+  images: { original: string; medium: string; thumb: string }[];
 }
 
 // Create a mock adapter for testing
@@ -25,25 +25,43 @@ export const ISSUE_TYPE_LABELS: Record<IssueType, string> = {
   [ISSUE_TYPES.BLOCKED_DRAIN]: 'Blocked Drain',
 };
 
-// Issue Statuses
+// Issue Statuses, in lifecycle order (see backend/internal/models/workflow.go)
 export const ISSUE_STATUSES = {
   NEW: 'NEW',
+  TRIAGED: 'TRIAGED',
+  ASSIGNED: 'ASSIGNED',
   IN_PROGRESS: 'IN_PROGRESS',
   RESOLVED: 'RESOLVED',
+  CLOSED: 'CLOSED',
+  REJECTED: 'REJECTED',
+  DUPLICATE: 'DUPLICATE',
+  REOPENED: 'REOPENED',
 } as const;
 
 export type IssueStatus = typeof ISSUE_STATUSES[keyof typeof ISSUE_STATUSES];
 
 export const ISSUE_STATUS_LABELS: Record<IssueStatus, string> = {
   [ISSUE_STATUSES.NEW]: 'New',
+  [ISSUE_STATUSES.TRIAGED]: 'Triaged',
+  [ISSUE_STATUSES.ASSIGNED]: 'Assigned',
   [ISSUE_STATUSES.IN_PROGRESS]: 'In Progress',
   [ISSUE_STATUSES.RESOLVED]: 'Resolved',
+  [ISSUE_STATUSES.CLOSED]: 'Closed',
+  [ISSUE_STATUSES.REJECTED]: 'Rejected',
+  [ISSUE_STATUSES.DUPLICATE]: 'Duplicate',
+  [ISSUE_STATUSES.REOPENED]: 'Reopened',
 };
 
 export const ISSUE_STATUS_COLORS: Record<IssueStatus, string> = {
   [ISSUE_STATUSES.NEW]: '#17a2b8', // info
+  [ISSUE_STATUSES.TRIAGED]: '#6f42c1', // purple
+  [ISSUE_STATUSES.ASSIGNED]: '#007bff', // primary
   [ISSUE_STATUSES.IN_PROGRESS]: '#ffc107', // warning
   [ISSUE_STATUSES.RESOLVED]: '#28a745', // success secondary
+  [ISSUE_STATUSES.CLOSED]: '#6c757d', // secondary
+  [ISSUE_STATUSES.REJECTED]: '#dc3545', // danger
+  [ISSUE_STATUSES.DUPLICATE]: '#6c757d', // secondary
+  [ISSUE_STATUSES.REOPENED]: '#fd7e14', // orange
 };
 
 // Map settings
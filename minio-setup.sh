@@ -9,8 +9,8 @@ echo "Setting up Minio..."
 # Create bucket (ignore error if it already exists)
 /usr/bin/mc mb myminio/issues-bucket || true
 
-# Set public access for the bucket
-/usr/bin/mc anonymous set public myminio/issues-bucket
+# Keep the bucket private; the API hands out presigned URLs to read images
+/usr/bin/mc anonymous set none myminio/issues-bucket
 
 echo "Uploading sample images to Minio..."
 cd /sample-images